@@ -0,0 +1,8 @@
+package event
+
+import "github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+
+// Publisher はチャンネルの購読者へイベントを配信する
+type Publisher interface {
+	Publish(e *model.Event)
+}
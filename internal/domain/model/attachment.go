@@ -0,0 +1,39 @@
+package model
+
+import (
+	"io"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// MaxAttachmentSize はアップロードできるファイルの最大サイズ
+	MaxAttachmentSize = 25 << 20
+	// MaxAttachmentsPerMessage は1メッセージに添付できるファイル数の上限。RequestCreateMessage の validate タグと揃える
+	MaxAttachmentsPerMessage = 10
+)
+
+// Attachment はアップロードされたファイルのメタデータ
+type Attachment struct {
+	AttachmentID uuid.UUID `db:"attachment_id" json:"attachment_id"`
+	OwnerID      uuid.UUID `db:"owner_id" json:"owner_id"`
+	FileName     string    `db:"file_name" json:"file_name"`
+	MimeType     string    `db:"mime_type" json:"mime_type"`
+	Size         int64     `db:"size" json:"size"`
+	SHA256       string    `db:"sha256" json:"sha256"`
+	StorageKey   string    `db:"storage_key" json:"-"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+
+	// Deduplicated はアップロード時のみ設定され、同じ内容のファイルが既に保存されていたかを表す
+	Deduplicated *bool `db:"-" json:"deduplicated,omitempty"`
+
+	// MessageID は一覧取得時にどのメッセージの添付かを表す
+	MessageID uuid.UUID `db:"message_id" json:"-"`
+}
+
+type RequestUploadAttachment struct {
+	FileName string
+	MimeType string
+	Body     io.Reader
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Blob は sha256 で重複排除された本文またはファイル本体
+// 参照数が 0 になったものはスイーパーが回収する
+type Blob struct {
+	SHA256 string `db:"sha256"`
+	Size   int64  `db:"size"`
+	// StorageKey は BlobStore に本体がある場合のキー。メッセージ本文のみなら nil
+	StorageKey *string   `db:"storage_key"`
+	RefCount   int       `db:"ref_count"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
@@ -6,23 +6,69 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+const (
+	ChannelVisibilityPublic  = "public"
+	ChannelVisibilityPrivate = "private"
+)
+
 type Channel struct {
 	ChannelID   uuid.UUID `db:"channel_id" json:"channel_id"`
 	ChannelName string    `db:"channel_name" json:"channel_name"`
 	DisplayName string    `db:"display_name" json:"display_name"`
 	Description string    `db:"description" json:"description"`
+	Visibility  string    `db:"visibility" json:"visibility"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	// DeletedAt はゴミ箱に入れられた日時。削除済みのチャンネルを含めて取得した場合のみ設定される
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
+// Request* の validate タグの長さは u_channel の列に合わせる
 type RequestCreateChannel struct {
-	ChannelName string `db:"channel_name" json:"channel_name"`
-	DisplayName string `db:"display_name" json:"display_name"`
-	Description string `db:"description" json:"description"`
+	ChannelName string `db:"channel_name" json:"channel_name" validate:"required,max=32,format=channel_name"`
+	DisplayName string `db:"display_name" json:"display_name" validate:"required,max=32"`
+	Description string `db:"description" json:"description" validate:"max=256"`
+	// Visibility を省略すると公開チャンネルになる
+	Visibility string `db:"visibility" json:"visibility" validate:"oneof=public private"`
+	// OwnerID は作成者。認証済みユーザーから設定され、リクエストボディからは受け取らない
+	OwnerID uuid.UUID `db:"-" json:"-"`
 }
 
 type RequestPatchChannel struct {
-	ChannelName *string `json:"channel_name,omitempty"`
-	DisplayName *string `json:"display_name,omitempty"`
-	Description *string `json:"description,omitempty"`
+	ChannelName *string `json:"channel_name,omitempty" validate:"required,max=32,format=channel_name"`
+	DisplayName *string `json:"display_name,omitempty" validate:"required,max=32"`
+	Description *string `json:"description,omitempty" validate:"max=256"`
+	Visibility  *string `json:"visibility,omitempty" validate:"required,oneof=public private"`
+}
+
+// ChannelRole はチャンネル内での権限
+type ChannelRole string
+
+const (
+	ChannelRoleOwner  ChannelRole = "owner"
+	ChannelRoleAdmin  ChannelRole = "admin"
+	ChannelRoleMember ChannelRole = "member"
+)
+
+// CanManage はメンバーの追加・削除やチャンネル設定の変更ができる権限かどうか
+func (r ChannelRole) CanManage() bool {
+	return r == ChannelRoleOwner || r == ChannelRoleAdmin
+}
+
+type ChannelMember struct {
+	ChannelID uuid.UUID   `db:"channel_id" json:"channel_id"`
+	UserID    uuid.UUID   `db:"user_id" json:"user_id"`
+	Role      ChannelRole `db:"role" json:"role"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time   `db:"updated_at" json:"updated_at"`
+}
+
+type RequestAddChannelMember struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	// Role を省略すると member になる。owner は作成者だけで、後から追加できない
+	Role ChannelRole `json:"role" validate:"oneof=admin member"`
+}
+
+type RequestRemoveChannelMember struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
 }
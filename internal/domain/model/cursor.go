@@ -0,0 +1,69 @@
+package model
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// MessageCursor はキーセットページネーションの位置 (created_at, message_id) を表す
+type MessageCursor struct {
+	CreatedAt time.Time
+	MessageID uuid.UUID
+}
+
+func CursorOf(message *Message) *MessageCursor {
+	return &MessageCursor{CreatedAt: message.CreatedAt, MessageID: message.MessageID}
+}
+
+// Encode はクライアントに渡す不透明な文字列にする
+func (c *MessageCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.MessageID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeMessageCursor(s string) (*MessageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	createdAtStr, messageIDStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	messageID, err := uuid.FromString(messageIDStr)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &MessageCursor{CreatedAt: createdAt, MessageID: messageID}, nil
+}
+
+// MessageQuery はチャンネルのメッセージ一覧の取得条件
+// Before / After のどちらかを指定するとキーセットで、どちらもなければ Offset で位置を決める
+type MessageQuery struct {
+	Limit  int
+	Offset int
+	Before *MessageCursor
+	After  *MessageCursor
+	// ExcludeReplies が true ならスレッドへの返信を除き、タイムラインのメッセージのみ返す
+	ExcludeReplies bool
+	// ContentType を指定するとその種類のメッセージのみ返す
+	ContentType string
+	// IncludeDeleted が true ならゴミ箱に入ったメッセージも返す。チャンネルの管理者のみ指定できる
+	IncludeDeleted bool
+}
+
+// MessagePage はメッセージ一覧の1ページ分。Messages は常に新しい順に並ぶ
+type MessagePage struct {
+	Messages []*Message
+	// NextCursor はより古いメッセージを取得するためのカーソル。続きがなければ nil
+	NextCursor *MessageCursor
+	// PrevCursor はより新しいメッセージを取得するためのカーソル
+	PrevCursor *MessageCursor
+}
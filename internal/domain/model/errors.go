@@ -3,9 +3,14 @@ package model
 import "errors"
 
 var (
-	ErrNilUUID        = errors.New("nil UUID")
-	ErrInvalidUUID    = errors.New("invalid UUID")
-	ErrNothingChanged = errors.New("nothing changed")
+	ErrNilUUID          = errors.New("nil UUID")
+	ErrInvalidUUID      = errors.New("invalid UUID")
+	ErrNothingChanged   = errors.New("nothing changed")
+	ErrNoFieldsToUpdate = errors.New("no fields to update")
+
+	ErrInvalidCredentials = errors.New("invalid User Name or Password")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
 
 	ErrInvalidUserName      = errors.New("invalid User Name")
 	ErrBadFormatUserName    = errors.New("User Name does not match the required format")
@@ -13,6 +18,7 @@ var (
 	ErrInvalidNickname      = errors.New("invalid Nickname")
 	ErrWeakPassword         = errors.New("weak Password")
 	ErrBadFormatEmail       = errors.New("email does not match the required format")
+	ErrAlreadyExistEmail    = errors.New("email already exists")
 	ErrUserNotFound         = errors.New("user not found")
 
 	ErrInvalidChannelName      = errors.New("invalid Channel Name")
@@ -20,11 +26,36 @@ var (
 	ErrAlreadyExistChannelName = errors.New("Channel Name already exists")
 	ErrInvalidDisplayName      = errors.New("invalid Channel Display Name")
 	ErrChannelNotFound         = errors.New("channel not found")
+	ErrInvalidVisibility       = errors.New("invalid Channel Visibility")
+	ErrInvalidChannelRole      = errors.New("invalid Channel Role")
+	ErrAlreadyChannelMember    = errors.New("user is already a channel member")
+	ErrChannelMemberNotFound   = errors.New("channel member not found")
 
 	ErrInvalidMessageContent = errors.New("invalid Message Content")
+	ErrInvalidContentType    = errors.New("invalid content type")
+	ErrInvalidLanguage       = errors.New("invalid language")
+	ErrInvalidExpiry         = errors.New("invalid expiry")
+	ErrInvalidEnvelope       = errors.New("invalid encryption envelope")
 	ErrMessageNotFound       = errors.New("message not found")
 	ErrInvalidRequestLimit   = errors.New("invalid request limit")
 	ErrInvalidTimeRange      = errors.New("invalid time range")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrInvalidSearchQuery    = errors.New("invalid search query")
 	ErrMessageAlreadyPinned  = errors.New("message already pinned")
 	ErrMessageNotPinned      = errors.New("message not pinned")
+	ErrInvalidParentMessage  = errors.New("invalid parent message")
+	ErrParentMessageDeleted  = errors.New("parent message is deleted")
+	ErrInvalidEmoji          = errors.New("invalid Emoji")
+	ErrAlreadyReacted        = errors.New("reaction already exists")
+	ErrReactionNotFound      = errors.New("reaction not found")
+
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	ErrInvalidAttachment  = errors.New("invalid attachment")
+	ErrTooManyAttachments = errors.New("too many attachments")
+	ErrBlobNotFound       = errors.New("blob not found")
+
+	ErrInvalidUserKey       = errors.New("invalid user key")
+	ErrUserKeyNotFound      = errors.New("user key not found")
+	ErrUserKeyAlreadyExists = errors.New("user key already exists")
 )
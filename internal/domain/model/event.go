@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// EventType はチャンネルに配信されるイベントの種類
+type EventType string
+
+const (
+	EventMessageCreated  EventType = "message.created"
+	EventMessageUpdated  EventType = "message.updated"
+	EventMessageDeleted  EventType = "message.deleted"
+	EventMessageRestored EventType = "message.restored"
+	EventMessagePinned   EventType = "message.pinned"
+	EventMessageUnpinned EventType = "message.unpinned"
+)
+
+// Event はチャンネル内で発生したメッセージのライフサイクルイベント
+type Event struct {
+	ID        uint64    `json:"id"`
+	Type      EventType `json:"type"`
+	ChannelID uuid.UUID `json:"channel_id"`
+	MessageID uuid.UUID `json:"message_id"`
+	Message   *Message  `json:"message,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// MentionKindUser は @user_name で直接宛てたメンション
+	MentionKindUser = "user"
+	// MentionKindChannel は @channel でチャンネルのメンバー全員に宛てたメンション
+	MentionKindChannel = "channel"
+)
+
+// MentionTargets はメッセージの本文から読み取ったメンションの宛先
+type MentionTargets struct {
+	UserNames []string
+	Channel   bool
+}
+
+// MessageMention は u_mention の1行。メッセージと宛先のユーザーの組で1つだけ持つ
+type MessageMention struct {
+	MessageID uuid.UUID `db:"message_id"`
+	UserID    uuid.UUID `db:"user_id"`
+	ChannelID uuid.UUID `db:"channel_id"`
+	Kind      string    `db:"kind"`
+	// CreatedAt はメッセージの作成日時。メンション一覧もメッセージと同じ位置で並べる
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Mention はメンション一覧の1件
+type Mention struct {
+	Message *Message `json:"message"`
+	Kind    string   `json:"kind"`
+	// Read はチャンネルの既読の位置までに含まれるか
+	Read bool `json:"read"`
+}
+
+// MentionQuery はメンション一覧の取得条件
+type MentionQuery struct {
+	Limit  int
+	Before *MessageCursor
+	// UnreadOnly が true なら未読のメンションのみ返す
+	UnreadOnly bool
+}
+
+// MentionPage はメンション一覧の1ページ分。新しい順に並ぶ
+type MentionPage struct {
+	Mentions []*Mention
+	// NextCursor はより古いメンションを取得するためのカーソル。続きがなければ nil
+	NextCursor *MessageCursor
+}
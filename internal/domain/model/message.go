@@ -6,21 +6,106 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+// 本文の種類。これ以外の MIME タイプも指定できる
+const (
+	ContentTypeText = "text/plain"
+	ContentTypeJSON = "application/json"
+	ContentTypeURL  = "text/uri-list"
+	// ContentTypeCode はソースコード。言語は Language で表す
+	ContentTypeCode = "text/x-code"
+	// ContentTypeEncrypted は種類が指定されなかった暗号化メッセージ
+	ContentTypeEncrypted = "application/octet-stream"
+)
+
+const (
+	// MaxCiphertextSize は暗号化メッセージの暗号文の最大サイズ
+	MaxCiphertextSize = 4 << 20
+	// MaxNonceSize は暗号化メッセージの nonce の最大サイズ
+	MaxNonceSize = 64
+	// MaxKeyIDLength は暗号化に使ったチャンネル鍵の ID の最大長
+	MaxKeyIDLength = 128
+)
+
 type Message struct {
 	MessageID uuid.UUID `db:"message_id" json:"message_id"`
 	ChannelID uuid.UUID `db:"channel_id" json:"channel_id"`
 	UserID    uuid.UUID `db:"user_id" json:"user_id"`
-	Content   string    `db:"content" json:"content"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	// ParentMessageID はスレッドの親メッセージ。返信でなければ null
+	ParentMessageID uuid.NullUUID `db:"parent_message_id" json:"parent_message_id"`
+	Content         string        `db:"content" json:"content"`
+	// ContentSHA256 は本文を保存している blob のハッシュ
+	ContentSHA256 string `db:"content_sha256" json:"-"`
+	ContentType   string `db:"content_type" json:"content_type"`
+	// Language はコードのシンタックスハイライト用の言語。不明なら null
+	Language *string `db:"language" json:"language"`
+	// ExpiresAt を過ぎたメッセージは読めなくなり、後でまとめて削除される
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at"`
+	// BurnAfterRead なメッセージは投稿者以外が一度開くと削除される。一覧では本文を伏せる
+	BurnAfterRead bool `db:"burn_after_read" json:"burn_after_read"`
+
+	// Encrypted なメッセージは Content が空で、暗号文と nonce、鍵の ID をそのまま保存する
+	// Ciphertext と Nonce は JSON では base64 で表す
+	Encrypted  bool      `db:"encrypted" json:"encrypted"`
+	Ciphertext []byte    `db:"ciphertext" json:"ciphertext,omitempty"`
+	Nonce      []byte    `db:"nonce" json:"nonce,omitempty"`
+	KeyID      *string   `db:"key_id" json:"key_id,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	// DeletedAt はゴミ箱に入れられた日時。削除済みのメッセージを含めて取得した場合のみ設定される
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+
+	// ReplyCount と LastReplyAt はスレッドの親メッセージでのみ意味を持つ
+	ReplyCount  int        `db:"reply_count" json:"reply_count"`
+	LastReplyAt *time.Time `db:"last_reply_at" json:"last_reply_at"`
+
+	// Edited は一度でも編集されたか、RevisionCount は記録された編集前の版の数を表す
+	Edited        bool `db:"edited" json:"edited"`
+	RevisionCount int  `db:"revision_count" json:"revision_count"`
+
+	Reactions   []*ReactionSummary `db:"-" json:"reactions"`
+	Attachments []*Attachment      `db:"-" json:"attachments"`
+
+	// Deduplicated は作成時のみ設定され、同じ本文が既に保存されていたかを表す
+	Deduplicated *bool `db:"-" json:"deduplicated,omitempty"`
 }
 
+// Request* の validate タグの長さは u_message と本文を保存する u_blob.content (MEDIUMTEXT) に合わせる
 type RequestCreateMessage struct {
-	ChannelID uuid.UUID `json:"channel_id"`
+	ChannelID uuid.UUID `json:"channel_id" validate:"required"`
 	UserID    uuid.UUID `json:"user_id"`
-	Content   string    `json:"content"`
+	// ParentMessageID を指定するとスレッドへの返信になる
+	ParentMessageID uuid.NullUUID `json:"parent_message_id"`
+	Content         string        `json:"content" validate:"required_unless=encrypted,maxbytes=16777215"`
+	// ContentType と Language を省略すると本文から推定する
+	ContentType string `json:"content_type" validate:"max=127"`
+	Language    string `json:"language" validate:"max=32"`
+	// ExpiresAt か TTLSeconds のどちらかで有効期限を指定できる
+	ExpiresAt     *time.Time `json:"expires_at"`
+	TTLSeconds    *int64     `json:"ttl_seconds"`
+	BurnAfterRead bool       `json:"burn_after_read"`
+	// Encrypted の場合は Content を空にし、Ciphertext / Nonce / KeyID を指定する
+	Encrypted  bool   `json:"encrypted"`
+	Ciphertext []byte `json:"ciphertext" validate:"max=4194304"`
+	Nonce      []byte `json:"nonce" validate:"max=64"`
+	KeyID      string `json:"key_id" validate:"max=128"`
+	// AttachmentIDs は事前にアップロードした自分の添付ファイル
+	AttachmentIDs []uuid.UUID `json:"attachment_ids" validate:"max=10,unique"`
 }
 
 type RequestPatchMessage struct {
-	Content *string `json:"content,omitempty"`
+	// 平文のメッセージを空の本文にはできない。暗号化メッセージは Content を指定できない
+	Content *string `json:"content,omitempty" validate:"required,maxbytes=16777215"`
+	// 本文だけを変更した場合、ContentType と Language は新しい本文から推定し直す
+	ContentType *string `json:"content_type,omitempty" validate:"max=127"`
+	Language    *string `json:"language,omitempty" validate:"max=32"`
+	// 暗号化メッセージは Ciphertext / Nonce / KeyID をまとめて差し替える
+	Ciphertext []byte  `json:"ciphertext,omitempty" validate:"max=4194304"`
+	Nonce      []byte  `json:"nonce,omitempty" validate:"max=64"`
+	KeyID      *string `json:"key_id,omitempty" validate:"max=128"`
+}
+
+// IsEmpty は変更するフィールドが1つも指定されていないかを返す
+func (r *RequestPatchMessage) IsEmpty() bool {
+	return r.Content == nil && r.ContentType == nil && r.Language == nil &&
+		r.Ciphertext == nil && r.Nonce == nil && r.KeyID == nil
 }
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type Reaction struct {
+	MessageID uuid.UUID `db:"message_id" json:"message_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ReactionSummary はメッセージに付いた絵文字ごとのリアクション数
+type ReactionSummary struct {
+	MessageID uuid.UUID `db:"message_id" json:"-"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+	Count     int       `db:"count" json:"count"`
+	// Reacted はリクエストしたユーザー自身がリアクションしているかどうか
+	Reacted bool `db:"reacted" json:"reacted"`
+}
+
+type RequestAddReaction struct {
+	// u_message_reaction.emoji の長さ
+	Emoji string `json:"emoji" validate:"required,max=64"`
+}
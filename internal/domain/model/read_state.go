@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChannelReadState はユーザーがチャンネルのどこまで読んだか
+// 位置はメッセージ一覧と同じく (created_at, message_id) の順で比べる
+type ChannelReadState struct {
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	ChannelID         uuid.UUID `db:"channel_id" json:"channel_id"`
+	LastReadMessageID uuid.UUID `db:"last_read_message_id" json:"last_read_message_id"`
+	// LastReadAt は最後に読んだメッセージの作成日時
+	LastReadAt time.Time `db:"last_read_at" json:"last_read_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type RequestMarkChannelRead struct {
+	// MessageID はチャンネルで最後に読んだメッセージ
+	MessageID uuid.UUID `json:"message_id" validate:"required"`
+}
+
+// ChannelUnread はチャンネルごとの未読数
+// 自分のメッセージと、ゴミ箱に入ったものや期限切れのものは数えない
+type ChannelUnread struct {
+	ChannelID   uuid.UUID `db:"channel_id" json:"channel_id"`
+	ChannelName string    `db:"channel_name" json:"channel_name"`
+	UnreadCount int       `db:"unread_count" json:"unread_count"`
+	// MentionCount は未読のうち自分へのメンションを含むものの数
+	MentionCount int `db:"mention_count" json:"mention_count"`
+	// LastReadMessageID と LastReadAt は既読の位置。まだ記録していなければ null
+	LastReadMessageID uuid.NullUUID `db:"last_read_message_id" json:"last_read_message_id"`
+	LastReadAt        *time.Time    `db:"last_read_at" json:"last_read_at"`
+}
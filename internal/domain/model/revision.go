@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// MessageRevision はメッセージのある時点の版
+// 編集のたびに編集前の版が記録され、最新の版はメッセージ自身が持つ
+type MessageRevision struct {
+	MessageID uuid.UUID `db:"message_id" json:"message_id"`
+	// Revision は 1 から始まる版の番号
+	Revision      int       `db:"revision" json:"revision"`
+	Content       string    `db:"content" json:"content"`
+	ContentSHA256 string    `db:"content_sha256" json:"-"`
+	ContentType   string    `db:"content_type" json:"content_type"`
+	Language      *string   `db:"language" json:"language"`
+	Encrypted     bool      `db:"encrypted" json:"encrypted"`
+	Ciphertext    []byte    `db:"ciphertext" json:"ciphertext,omitempty"`
+	Nonce         []byte    `db:"nonce" json:"nonce,omitempty"`
+	KeyID         *string   `db:"key_id" json:"key_id,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+
+	// Current は最新の版（メッセージの現在の内容）かを表す
+	Current bool `db:"-" json:"current"`
+	// Diff は1つ前の版からの本文の差分 (unified 形式)。最初の版と暗号化された版では null
+	Diff *string `db:"-" json:"diff"`
+}
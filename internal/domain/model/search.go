@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// SearchQuery はメッセージ検索の条件
+type SearchQuery struct {
+	Query string
+	// Terms は Query を検索語に分割したもの
+	Terms     []string
+	ChannelID uuid.UUID
+	UserID    uuid.UUID
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Before    *MessageCursor
+	// ViewerID は検索したユーザー。非公開チャンネルはメンバーの場合のみ検索対象になる
+	ViewerID uuid.UUID
+}
+
+type SearchResult struct {
+	Message *Message `json:"message"`
+	// Snippet はマッチ箇所を <mark> で囲んだ本文の抜粋（HTML エスケープ済み）
+	Snippet string `json:"snippet"`
+}
+
+// SearchPage は検索結果の1ページ分。新しい順に並ぶ
+type SearchPage struct {
+	Results    []*SearchResult
+	NextCursor *MessageCursor
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Session はログインセッションを表すドメインモデル
+// トークン本体は保存せず、SHA-256 ハッシュのみを保持する
+type Session struct {
+	TokenHash string    `db:"token_hash" json:"-"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type RequestLogin struct {
+	UserName string `json:"user_name" validate:"required,max=32"`
+	Password string `json:"password" validate:"required,maxbytes=72"`
+}
+
+type ResponseLogin struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      *User     `json:"user"`
+}
@@ -16,11 +16,13 @@ type User struct {
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// Request* の validate タグの長さは u_user / u_user_private の列に合わせる
+// パスワードは bcrypt が扱える 72 バイトまで
 type RequestCreateUser struct {
-	UserName string `db:"user_name" json:"user_name"`
-	Password string `db:"password" json:"password"`
-	Nickname string `db:"nickname" json:"nickname"`
-	Status   string `db:"status" json:"status"`
+	UserName string `db:"user_name" json:"user_name" validate:"required,max=32,format=user_name"`
+	Password string `db:"password" json:"password" validate:"required,maxbytes=72,password"`
+	Nickname string `db:"nickname" json:"nickname" validate:"required,max=32"`
+	Status   string `db:"status" json:"status" validate:"max=4096"`
 }
 
 type RequestGetUserBatch struct {
@@ -28,13 +30,13 @@ type RequestGetUserBatch struct {
 }
 
 type RequestPatchUser struct {
-	UserName *string `json:"user_name,omitempty"`
-	Email    *string `json:"email,omitempty"`
-	Nickname *string `json:"nickname,omitempty"`
-	Status   *string `json:"status,omitempty"`
+	UserName *string `json:"user_name,omitempty" validate:"required,max=32,format=user_name"`
+	Email    *string `json:"email,omitempty" validate:"max=255,format=email"`
+	Nickname *string `json:"nickname,omitempty" validate:"required,max=32"`
+	Status   *string `json:"status,omitempty" validate:"max=4096"`
 }
 
 type RequestChangePassword struct {
-	OldPassword string `json:"old_password"`
-	NewPassword string `json:"new_password"`
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,maxbytes=72,password"`
 }
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// MaxPublicKeySize は登録できる公開鍵の最大サイズ
+const MaxPublicKeySize = 1024
+
+// UserKeyAlgorithms は登録できる公開鍵のアルゴリズムと鍵の長さ。0 は長さを問わない
+var UserKeyAlgorithms = map[string]int{
+	"x25519":       32,
+	"p256":         65,
+	"rsa-oaep-256": 0,
+}
+
+// UserKey はクライアントがチャンネル鍵を包むのに使うユーザーの公開鍵
+// 秘密鍵はクライアントだけが持ち、サーバーには送られない
+type UserKey struct {
+	KeyID     uuid.UUID `db:"key_id" json:"key_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Algorithm string    `db:"algorithm" json:"algorithm"`
+	// PublicKey は JSON では base64 で表す
+	PublicKey []byte `db:"public_key" json:"public_key"`
+	// Fingerprint は公開鍵の sha256。利用者同士で鍵を照合するのに使う
+	Fingerprint string    `db:"fingerprint" json:"fingerprint"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+type RequestCreateUserKey struct {
+	UserID    uuid.UUID `json:"-"`
+	Algorithm string    `json:"algorithm" validate:"required,oneof=x25519 p256 rsa-oaep-256"`
+	// u_user_key.public_key の長さ
+	PublicKey []byte `json:"public_key" validate:"required,max=1024"`
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type AttachmentRepository interface {
+	// CreateAttachment は同じ内容の本体が既にあれば StorageKey をそのキーに置き換えて返す
+	CreateAttachment(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error)
+	GetAttachment(ctx context.Context, attachmentID uuid.UUID) (*model.Attachment, error)
+	// GetAttachmentsByMessageIDs は複数メッセージの添付ファイルを1回のクエリでまとめて取得する
+	GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]*model.Attachment, error)
+	// GetAttachmentChannels は添付ファイルを参照しているメッセージのチャンネルを返す
+	GetAttachmentChannels(ctx context.Context, attachmentID uuid.UUID) ([]uuid.UUID, error)
+	DeleteAttachment(ctx context.Context, attachmentID uuid.UUID) error
+}
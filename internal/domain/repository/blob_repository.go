@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// BlobRepository は重複排除された blob の参照数を管理する
+// 参照数の増減は各リポジトリがメッセージや添付ファイルの作成・削除と同じトランザクションで行う
+type BlobRepository interface {
+	// DeleteUnreferencedBlobs は olderThan より前から参照されていない blob を最大 limit 件削除し、削除したものを返す
+	DeleteUnreferencedBlobs(ctx context.Context, olderThan time.Time, limit int) ([]*model.Blob, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore はアップロードされたファイル本体を保存するストレージ
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open は Range リクエストに応えられるよう Seek 可能なリーダーを返す
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
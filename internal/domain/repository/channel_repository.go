@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
 	"github.com/gofrs/uuid"
@@ -9,8 +10,22 @@ import (
 
 type ChannelRepository interface {
 	CreateChannel(ctx context.Context, req *model.RequestCreateChannel) (*model.Channel, error)
+	// GetChannel はゴミ箱にないチャンネルを返す。なければ ErrChannelNotFound を返す
 	GetChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error)
-	GetChannels(ctx context.Context) ([]*model.Channel, error)
+	// GetDeletedChannel はゴミ箱に入ったチャンネルを返す。ゴミ箱になければ ErrChannelNotFound を返す
+	GetDeletedChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error)
+	// GetChannels は公開チャンネルと、viewerID が所属する非公開チャンネルを返す
+	// includeDeleted が true なら、viewerID が管理できるゴミ箱のチャンネルも返す
+	GetChannels(ctx context.Context, viewerID uuid.UUID, includeDeleted bool) ([]*model.Channel, error)
 	PatchChannel(ctx context.Context, channelID uuid.UUID, req *model.RequestPatchChannel) (*model.Channel, error)
+	// DeleteChannel はチャンネルをゴミ箱に入れる。メッセージはチャンネルごと見えなくなる
 	DeleteChannel(ctx context.Context, channelID uuid.UUID) error
+	RestoreChannel(ctx context.Context, channelID uuid.UUID) error
+	// PurgeDeletedChannels は deletedBefore より前にゴミ箱に入ったチャンネルをメッセージごと最大 limit 件完全に削除し、削除した件数を返す
+	PurgeDeletedChannels(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+
+	AddChannelMember(ctx context.Context, member *model.ChannelMember) (*model.ChannelMember, error)
+	GetChannelMember(ctx context.Context, channelID uuid.UUID, userID uuid.UUID) (*model.ChannelMember, error)
+	GetChannelMembers(ctx context.Context, channelID uuid.UUID) ([]*model.ChannelMember, error)
+	RemoveChannelMember(ctx context.Context, channelID uuid.UUID, userID uuid.UUID) error
 }
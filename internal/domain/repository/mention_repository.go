@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type MentionRepository interface {
+	// SaveMentions は messageID のメンションを targets で置き換える
+	// ユーザー名は大文字と小文字を区別せずに照合し、存在しないユーザー、投稿者自身、非公開チャンネルのメンバーでないユーザーは除く
+	// @channel はチャンネルのメンバー全員に宛て、同じユーザーを名前でも宛てていれば MentionKindUser とする
+	SaveMentions(ctx context.Context, messageID uuid.UUID, targets *model.MentionTargets) error
+	// GetMentions は userID へのメンションをメッセージ付きで新しい順に返す
+	// ゴミ箱に入ったものや期限切れのもの、見られなくなった非公開チャンネルのものは除く
+	GetMentions(ctx context.Context, userID uuid.UUID, query *model.MentionQuery) (*model.MentionPage, error)
+}
@@ -10,11 +10,27 @@ import (
 
 type MessageRepository interface {
 	CreateMessage(ctx context.Context, req *model.RequestCreateMessage) (*model.Message, error)
-	GetMessages(ctx context.Context, channelID uuid.UUID, limit int, offset int) ([]*model.Message, error)
+	GetMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error)
+	// GetDeletedMessage はゴミ箱に入ったメッセージを返す。ゴミ箱になければ ErrMessageNotFound を返す
+	GetDeletedMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error)
+	GetMessages(ctx context.Context, channelID uuid.UUID, query *model.MessageQuery) (*model.MessagePage, error)
 	GetMessagesInDuration(ctx context.Context, channelID uuid.UUID, start, end time.Time) ([]*model.Message, error)
 	GetPinnedMessages(ctx context.Context, channelID uuid.UUID) ([]*model.Message, error)
+	GetReplies(ctx context.Context, messageID uuid.UUID) ([]*model.Message, error)
+	// PatchMessage は更新前の版を同じトランザクションで u_message_revision に記録する
 	PatchMessage(ctx context.Context, messageID uuid.UUID, req *model.RequestPatchMessage) (*model.Message, error)
+	// GetRevisions は編集前の版を古い順に返す。最新の版は含まない
+	GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.MessageRevision, error)
 	PinnMessage(ctx context.Context, messageID uuid.UUID) error
 	UnpinnMessage(ctx context.Context, messageID uuid.UUID) error
+	// DeleteMessage はメッセージを返信ごとゴミ箱に入れる。完全に削除するのは PurgeDeletedMessages
 	DeleteMessage(ctx context.Context, messageID uuid.UUID) error
+	// RestoreMessage はゴミ箱に入ったメッセージを、一緒にゴミ箱に入った返信ごと元に戻す
+	RestoreMessage(ctx context.Context, messageID uuid.UUID) error
+	// PurgeDeletedMessages は deletedBefore より前にゴミ箱に入ったメッセージを最大 limit 件完全に削除し、削除した件数を返す
+	PurgeDeletedMessages(ctx context.Context, deletedBefore time.Time, limit int) (int, error)
+	// BurnMessage はメッセージを取得すると同時に削除する。同時に読まれても返すのは1回だけ
+	BurnMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error)
+	// DeleteExpiredMessages は期限切れのメッセージを最大 limit 件削除し、削除したものを返す
+	DeleteExpiredMessages(ctx context.Context, limit int) ([]*model.Message, error)
 }
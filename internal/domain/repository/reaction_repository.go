@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type ReactionRepository interface {
+	AddReaction(ctx context.Context, reaction *model.Reaction) error
+	RemoveReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) error
+	// GetReactionSummaries は複数メッセージのリアクション数を1回のクエリでまとめて取得する
+	GetReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]*model.ReactionSummary, error)
+}
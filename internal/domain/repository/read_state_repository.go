@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type ReadStateRepository interface {
+	// MarkRead は既読の位置を state まで進め、記録した位置を返す
+	// 既に state より先まで読んでいれば戻さずに、その位置を返す
+	MarkRead(ctx context.Context, state *model.ChannelReadState) (*model.ChannelReadState, error)
+	// GetUnreadCounts は userID が所属するチャンネルと、既読の位置を記録した公開チャンネルの未読数をチャンネル名の順に返す
+	// 既読の位置を記録していない所属チャンネルは、参加した後のメッセージを未読として数える
+	GetUnreadCounts(ctx context.Context, userID uuid.UUID) ([]*model.ChannelUnread, error)
+}
@@ -0,0 +1,210 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+// RunChannelRepository は ChannelRepository の振る舞いを確かめる
+func RunChannelRepository(t *testing.T, newRepositories Factory) {
+	ctx := context.Background()
+
+	t.Run("GetChannel returns ErrChannelNotFound for a missing channel", func(t *testing.T) {
+		r := newRepositories(t)
+		_, err := r.Channel.GetChannel(ctx, missingID)
+		wantErr(t, "GetChannel", err, model.ErrChannelNotFound)
+		_, err = r.Channel.GetDeletedChannel(ctx, missingID)
+		wantErr(t, "GetDeletedChannel", err, model.ErrChannelNotFound)
+	})
+
+	t.Run("CreateChannel makes the creator the owner", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+
+		found, err := r.Channel.GetChannel(ctx, channel.ChannelID)
+		if err != nil {
+			t.Fatalf("GetChannel: %v", err)
+		}
+		if found.ChannelName != "general" || found.Visibility != model.ChannelVisibilityPublic || found.DeletedAt != nil {
+			t.Errorf("GetChannel = %+v", found)
+		}
+
+		member, err := r.Channel.GetChannelMember(ctx, channel.ChannelID, alice.UserID)
+		if err != nil {
+			t.Fatalf("GetChannelMember: %v", err)
+		}
+		if member.Role != model.ChannelRoleOwner {
+			t.Errorf("creator role = %q, want %q", member.Role, model.ChannelRoleOwner)
+		}
+	})
+
+	t.Run("CreateChannel rejects a duplicate name regardless of case", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+
+		_, err := r.Channel.CreateChannel(ctx, &model.RequestCreateChannel{
+			ChannelName: "GENERAL", DisplayName: "General", Visibility: model.ChannelVisibilityPublic, OwnerID: alice.UserID,
+		})
+		wantErr(t, "CreateChannel", err, model.ErrAlreadyExistChannelName)
+
+		// ゴミ箱のチャンネルも完全に削除されるまで名前を使い続ける
+		if err := r.Channel.DeleteChannel(ctx, channel.ChannelID); err != nil {
+			t.Fatalf("DeleteChannel: %v", err)
+		}
+		_, err = r.Channel.CreateChannel(ctx, &model.RequestCreateChannel{
+			ChannelName: "general", DisplayName: "General", Visibility: model.ChannelVisibilityPublic, OwnerID: alice.UserID,
+		})
+		wantErr(t, "CreateChannel with the name of a deleted channel", err, model.ErrAlreadyExistChannelName)
+	})
+
+	t.Run("GetChannels hides private and deleted channels", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+		lobby := createChannel(t, r, "lobby", model.ChannelVisibilityPublic, alice)
+		secret := createChannel(t, r, "secret", model.ChannelVisibilityPrivate, alice)
+
+		wantChannels := func(viewer *model.User, includeDeleted bool, want ...*model.Channel) {
+			t.Helper()
+			channels, err := r.Channel.GetChannels(ctx, viewer.UserID, includeDeleted)
+			if err != nil {
+				t.Fatalf("GetChannels: %v", err)
+			}
+			got := map[uuid.UUID]bool{}
+			for _, channel := range channels {
+				got[channel.ChannelID] = true
+			}
+			if len(got) != len(want) {
+				t.Errorf("GetChannels(%s, %v) returned %d channels, want %d", viewer.UserName, includeDeleted, len(got), len(want))
+			}
+			for _, channel := range want {
+				if !got[channel.ChannelID] {
+					t.Errorf("GetChannels(%s, %v) does not include %s", viewer.UserName, includeDeleted, channel.ChannelName)
+				}
+			}
+		}
+
+		wantChannels(alice, false, lobby, secret)
+		wantChannels(bobby, false, lobby)
+
+		if err := r.Channel.DeleteChannel(ctx, lobby.ChannelID); err != nil {
+			t.Fatalf("DeleteChannel: %v", err)
+		}
+		wantChannels(alice, false, secret)
+		wantChannels(alice, true, lobby, secret)
+		wantChannels(bobby, true)
+	})
+
+	t.Run("PatchChannel", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+
+		patched, err := r.Channel.PatchChannel(ctx, channel.ChannelID, &model.RequestPatchChannel{
+			DisplayName: ptr("General"), Description: ptr("everything"),
+		})
+		if err != nil {
+			t.Fatalf("PatchChannel: %v", err)
+		}
+		if patched.DisplayName != "General" || patched.Description != "everything" || patched.ChannelName != "general" {
+			t.Errorf("PatchChannel = %+v", patched)
+		}
+
+		// 値が変わらなくても、チャンネルが存在すれば成功する
+		if _, err := r.Channel.PatchChannel(ctx, channel.ChannelID, &model.RequestPatchChannel{Description: ptr("everything")}); err != nil {
+			t.Errorf("PatchChannel without changes: %v", err)
+		}
+		if _, err := r.Channel.PatchChannel(ctx, channel.ChannelID, &model.RequestPatchChannel{}); err != nil {
+			t.Errorf("PatchChannel without fields: %v", err)
+		}
+
+		_, err = r.Channel.PatchChannel(ctx, missingID, &model.RequestPatchChannel{Description: ptr("nothing")})
+		wantErr(t, "PatchChannel for a missing channel", err, model.ErrChannelNotFound)
+		_, err = r.Channel.PatchChannel(ctx, missingID, &model.RequestPatchChannel{})
+		wantErr(t, "PatchChannel without fields for a missing channel", err, model.ErrChannelNotFound)
+	})
+
+	t.Run("DeleteChannel and RestoreChannel", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+
+		err := r.Channel.RestoreChannel(ctx, channel.ChannelID)
+		wantErr(t, "RestoreChannel for a live channel", err, model.ErrChannelNotFound)
+		_, err = r.Channel.GetDeletedChannel(ctx, channel.ChannelID)
+		wantErr(t, "GetDeletedChannel for a live channel", err, model.ErrChannelNotFound)
+
+		if err := r.Channel.DeleteChannel(ctx, channel.ChannelID); err != nil {
+			t.Fatalf("DeleteChannel: %v", err)
+		}
+		_, err = r.Channel.GetChannel(ctx, channel.ChannelID)
+		wantErr(t, "GetChannel after DeleteChannel", err, model.ErrChannelNotFound)
+		deleted, err := r.Channel.GetDeletedChannel(ctx, channel.ChannelID)
+		if err != nil {
+			t.Fatalf("GetDeletedChannel: %v", err)
+		}
+		if deleted.DeletedAt == nil {
+			t.Errorf("GetDeletedChannel returned a channel without deleted_at")
+		}
+		_, err = r.Channel.PatchChannel(ctx, channel.ChannelID, &model.RequestPatchChannel{Description: ptr("gone")})
+		wantErr(t, "PatchChannel after DeleteChannel", err, model.ErrChannelNotFound)
+		err = r.Channel.DeleteChannel(ctx, channel.ChannelID)
+		wantErr(t, "DeleteChannel twice", err, model.ErrChannelNotFound)
+
+		if err := r.Channel.RestoreChannel(ctx, channel.ChannelID); err != nil {
+			t.Fatalf("RestoreChannel: %v", err)
+		}
+		if _, err := r.Channel.GetChannel(ctx, channel.ChannelID); err != nil {
+			t.Errorf("GetChannel after RestoreChannel: %v", err)
+		}
+		err = r.Channel.DeleteChannel(ctx, missingID)
+		wantErr(t, "DeleteChannel for a missing channel", err, model.ErrChannelNotFound)
+	})
+
+	t.Run("channel members", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+
+		_, err := r.Channel.GetChannelMember(ctx, channel.ChannelID, bobby.UserID)
+		wantErr(t, "GetChannelMember before joining", err, model.ErrChannelMemberNotFound)
+
+		added, err := r.Channel.AddChannelMember(ctx, &model.ChannelMember{ChannelID: channel.ChannelID, UserID: bobby.UserID, Role: model.ChannelRoleMember})
+		if err != nil {
+			t.Fatalf("AddChannelMember: %v", err)
+		}
+		if added.Role != model.ChannelRoleMember || added.UserID != bobby.UserID {
+			t.Errorf("AddChannelMember = %+v", added)
+		}
+		_, err = r.Channel.AddChannelMember(ctx, &model.ChannelMember{ChannelID: channel.ChannelID, UserID: bobby.UserID, Role: model.ChannelRoleAdmin})
+		wantErr(t, "AddChannelMember twice", err, model.ErrAlreadyChannelMember)
+		_, err = r.Channel.AddChannelMember(ctx, &model.ChannelMember{ChannelID: channel.ChannelID, UserID: missingID, Role: model.ChannelRoleMember})
+		wantErr(t, "AddChannelMember for a missing user", err, model.ErrUserNotFound)
+
+		members, err := r.Channel.GetChannelMembers(ctx, channel.ChannelID)
+		if err != nil {
+			t.Fatalf("GetChannelMembers: %v", err)
+		}
+		roles := map[uuid.UUID]model.ChannelRole{}
+		for _, member := range members {
+			roles[member.UserID] = member.Role
+		}
+		if len(members) != 2 || roles[alice.UserID] != model.ChannelRoleOwner || roles[bobby.UserID] != model.ChannelRoleMember {
+			t.Errorf("GetChannelMembers = %v", roles)
+		}
+
+		if err := r.Channel.RemoveChannelMember(ctx, channel.ChannelID, bobby.UserID); err != nil {
+			t.Fatalf("RemoveChannelMember: %v", err)
+		}
+		err = r.Channel.RemoveChannelMember(ctx, channel.ChannelID, bobby.UserID)
+		wantErr(t, "RemoveChannelMember twice", err, model.ErrChannelMemberNotFound)
+		_, err = r.Channel.GetChannelMember(ctx, channel.ChannelID, bobby.UserID)
+		wantErr(t, "GetChannelMember after leaving", err, model.ErrChannelMemberNotFound)
+	})
+}
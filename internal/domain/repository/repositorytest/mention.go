@@ -0,0 +1,143 @@
+package repositorytest
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+func saveMentions(t *testing.T, r *Repositories, message *model.Message, targets *model.MentionTargets) {
+	t.Helper()
+	if err := r.Mention.SaveMentions(context.Background(), message.MessageID, targets); err != nil {
+		t.Fatalf("SaveMentions(%q): %v", message.Content, err)
+	}
+}
+
+// RunMentionRepository は MentionRepository の振る舞いを確かめる
+func RunMentionRepository(t *testing.T, newRepositories Factory) {
+	ctx := context.Background()
+
+	getMentions := func(t *testing.T, r *Repositories, user *model.User, query *model.MentionQuery) *model.MentionPage {
+		t.Helper()
+		if query.Limit == 0 {
+			query.Limit = 10
+		}
+		page, err := r.Mention.GetMentions(ctx, user.UserID, query)
+		if err != nil {
+			t.Fatalf("GetMentions: %v", err)
+		}
+		return page
+	}
+
+	addMember := func(t *testing.T, r *Repositories, channel *model.Channel, user *model.User) {
+		t.Helper()
+		_, err := r.Channel.AddChannelMember(ctx, &model.ChannelMember{ChannelID: channel.ChannelID, UserID: user.UserID, Role: model.ChannelRoleMember})
+		if err != nil {
+			t.Fatalf("AddChannelMember: %v", err)
+		}
+	}
+
+	t.Run("SaveMentions resolves user names and @channel", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+		carol := createUser(t, r, "carol")
+		private := createChannel(t, r, "secret", model.ChannelVisibilityPrivate, alice)
+		public := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+		addMember(t, r, private, bobby)
+
+		// 名前は大文字と小文字を区別せず、投稿者自身と非公開チャンネルのメンバーでないユーザーは除く
+		secret := createMessage(t, r, private, alice, "@Bobby @carol @nobody @alice @channel", nil)
+		saveMentions(t, r, secret, &model.MentionTargets{UserNames: []string{"Bobby", "carol", "nobody", "alice"}, Channel: true})
+		hello := createMessage(t, r, public, alice, "@carol hello", nil)
+		saveMentions(t, r, hello, &model.MentionTargets{UserNames: []string{"carol"}})
+
+		page := getMentions(t, r, bobby, &model.MentionQuery{})
+		if len(page.Mentions) != 1 || page.Mentions[0].Message.MessageID != secret.MessageID || page.Mentions[0].Kind != model.MentionKindUser {
+			t.Errorf("mentions of bobby = %+v, want the secret message by name", page.Mentions)
+		}
+		page = getMentions(t, r, carol, &model.MentionQuery{})
+		if len(page.Mentions) != 1 || page.Mentions[0].Message.MessageID != hello.MessageID {
+			t.Errorf("mentions of carol = %+v, want only the public message", page.Mentions)
+		}
+		if page = getMentions(t, r, alice, &model.MentionQuery{}); len(page.Mentions) != 0 {
+			t.Errorf("mentions of the author = %+v, want none", page.Mentions)
+		}
+	})
+
+	t.Run("SaveMentions replaces the mentions of a message", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+		addMember(t, r, channel, bobby)
+		message := createMessage(t, r, channel, alice, "@bobby", nil)
+
+		saveMentions(t, r, message, &model.MentionTargets{UserNames: []string{"bobby"}})
+		saveMentions(t, r, message, &model.MentionTargets{UserNames: []string{}})
+		if page := getMentions(t, r, bobby, &model.MentionQuery{}); len(page.Mentions) != 0 {
+			t.Errorf("mentions after removing the name = %+v, want none", page.Mentions)
+		}
+		saveMentions(t, r, message, &model.MentionTargets{UserNames: []string{}, Channel: true})
+		if page := getMentions(t, r, bobby, &model.MentionQuery{}); len(page.Mentions) != 1 || page.Mentions[0].Kind != model.MentionKindChannel {
+			t.Errorf("mentions after @channel = %+v, want one channel mention", page.Mentions)
+		}
+	})
+
+	t.Run("GetMentions pages newest first with the read state", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+		addMember(t, r, channel, bobby)
+		messages := []*model.Message{
+			createMessage(t, r, channel, alice, "@bobby first", nil),
+			createMessage(t, r, channel, alice, "@bobby second", nil),
+			createMessage(t, r, channel, alice, "@bobby third", nil),
+		}
+		for _, message := range messages {
+			saveMentions(t, r, message, &model.MentionTargets{UserNames: []string{"bobby"}})
+		}
+		// 作成日時の精度が秒までのバックエンドでは同じ日時になりうるため、一覧と同じ順に並べて比べる
+		sort.Slice(messages, func(i, j int) bool { return newer(messages[i], messages[j]) })
+
+		first := getMentions(t, r, bobby, &model.MentionQuery{Limit: 2})
+		if len(first.Mentions) != 2 || first.NextCursor == nil ||
+			first.Mentions[0].Message.MessageID != messages[0].MessageID || first.Mentions[1].Message.MessageID != messages[1].MessageID {
+			t.Fatalf("first page = %+v, want the two newest mentions and a cursor", first)
+		}
+		second := getMentions(t, r, bobby, &model.MentionQuery{Limit: 2, Before: first.NextCursor})
+		if len(second.Mentions) != 1 || second.NextCursor != nil || second.Mentions[0].Message.MessageID != messages[2].MessageID {
+			t.Errorf("second page = %+v, want the oldest mention and no cursor", second)
+		}
+
+		_, err := r.ReadState.MarkRead(ctx, &model.ChannelReadState{
+			UserID:            bobby.UserID,
+			ChannelID:         channel.ChannelID,
+			LastReadMessageID: messages[1].MessageID,
+			LastReadAt:        messages[1].CreatedAt,
+		})
+		if err != nil {
+			t.Fatalf("MarkRead: %v", err)
+		}
+		page := getMentions(t, r, bobby, &model.MentionQuery{})
+		for i, mention := range page.Mentions {
+			if want := i > 0; mention.Read != want {
+				t.Errorf("mention %d read = %v, want %v", i, mention.Read, want)
+			}
+		}
+		unread := getMentions(t, r, bobby, &model.MentionQuery{UnreadOnly: true})
+		if len(unread.Mentions) != 1 || unread.Mentions[0].Message.MessageID != messages[0].MessageID {
+			t.Errorf("unread mentions = %+v, want only the newest", unread.Mentions)
+		}
+
+		if err := r.Message.DeleteMessage(ctx, messages[0].MessageID); err != nil {
+			t.Fatalf("DeleteMessage: %v", err)
+		}
+		if page = getMentions(t, r, bobby, &model.MentionQuery{}); len(page.Mentions) != 2 {
+			t.Errorf("GetMentions returned %d mentions after deleting one, want 2", len(page.Mentions))
+		}
+	})
+}
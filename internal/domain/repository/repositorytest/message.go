@@ -0,0 +1,308 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+// newer は a が b より新しい位置にあるかを返す。一覧は (created_at, message_id) の降順に並ぶ
+func newer(a, b *model.Message) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.MessageID.String() > b.MessageID.String()
+}
+
+// RunMessageRepository は MessageRepository の振る舞いを確かめる
+func RunMessageRepository(t *testing.T, newRepositories Factory) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*Repositories, *model.User, *model.Channel) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		return r, alice, createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+	}
+
+	t.Run("GetMessage returns ErrMessageNotFound for a missing message", func(t *testing.T) {
+		r := newRepositories(t)
+		_, err := r.Message.GetMessage(ctx, missingID)
+		wantErr(t, "GetMessage", err, model.ErrMessageNotFound)
+		_, err = r.Message.GetDeletedMessage(ctx, missingID)
+		wantErr(t, "GetDeletedMessage", err, model.ErrMessageNotFound)
+		_, err = r.Message.PatchMessage(ctx, missingID, &model.RequestPatchMessage{Content: ptr("nothing")})
+		wantErr(t, "PatchMessage", err, model.ErrMessageNotFound)
+		err = r.Message.DeleteMessage(ctx, missingID)
+		wantErr(t, "DeleteMessage", err, model.ErrMessageNotFound)
+		err = r.Message.RestoreMessage(ctx, missingID)
+		wantErr(t, "RestoreMessage", err, model.ErrMessageNotFound)
+	})
+
+	t.Run("CreateMessage deduplicates identical content", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		first := createMessage(t, r, channel, alice, "hello", nil)
+		second := createMessage(t, r, channel, alice, "hello", nil)
+
+		if first.Deduplicated == nil || *first.Deduplicated {
+			t.Errorf("first message deduplicated = %v, want false", first.Deduplicated)
+		}
+		if second.Deduplicated == nil || !*second.Deduplicated {
+			t.Errorf("second message deduplicated = %v, want true", second.Deduplicated)
+		}
+
+		found, err := r.Message.GetMessage(ctx, second.MessageID)
+		if err != nil {
+			t.Fatalf("GetMessage: %v", err)
+		}
+		if found.Content != "hello" || found.ChannelID != channel.ChannelID || found.UserID != alice.UserID || found.Edited {
+			t.Errorf("GetMessage = %+v", found)
+		}
+	})
+
+	t.Run("GetMessages pages newest first without gaps", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		created := map[uuid.UUID]bool{}
+		for i := 0; i < 5; i++ {
+			created[createMessage(t, r, channel, alice, fmt.Sprintf("message %d", i), nil).MessageID] = true
+		}
+
+		var seen []*model.Message
+		query := &model.MessageQuery{Limit: 2}
+		for page := 0; ; page++ {
+			if page > len(created) {
+				t.Fatalf("GetMessages did not reach the end")
+			}
+			result, err := r.Message.GetMessages(ctx, channel.ChannelID, query)
+			if err != nil {
+				t.Fatalf("GetMessages: %v", err)
+			}
+			if len(result.Messages) > query.Limit {
+				t.Fatalf("GetMessages returned %d messages, limit %d", len(result.Messages), query.Limit)
+			}
+			seen = append(seen, result.Messages...)
+			if result.NextCursor == nil {
+				break
+			}
+			query = &model.MessageQuery{Limit: 2, Before: result.NextCursor}
+		}
+
+		if len(seen) != len(created) {
+			t.Fatalf("GetMessages returned %d messages in total, want %d", len(seen), len(created))
+		}
+		for i, message := range seen {
+			if !created[message.MessageID] {
+				t.Errorf("GetMessages returned unexpected or repeated message %s", message.MessageID)
+			}
+			delete(created, message.MessageID)
+			if i > 0 && !newer(seen[i-1], message) {
+				t.Errorf("GetMessages is not ordered newest first at %d", i)
+			}
+		}
+
+		// After で新しい方へ戻っても同じ順に並ぶ
+		back, err := r.Message.GetMessages(ctx, channel.ChannelID, &model.MessageQuery{Limit: 2, After: model.CursorOf(seen[len(seen)-1])})
+		if err != nil {
+			t.Fatalf("GetMessages after: %v", err)
+		}
+		if len(back.Messages) != 2 || back.Messages[0].MessageID != seen[len(seen)-3].MessageID || back.Messages[1].MessageID != seen[len(seen)-2].MessageID {
+			t.Errorf("GetMessages after the oldest message returned %d messages in the wrong order", len(back.Messages))
+		}
+	})
+
+	t.Run("PinnMessage allows a message to be pinned once", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		message := createMessage(t, r, channel, alice, "pin me", nil)
+		createMessage(t, r, channel, alice, "not pinned", nil)
+
+		if err := r.Message.PinnMessage(ctx, message.MessageID); err != nil {
+			t.Fatalf("PinnMessage: %v", err)
+		}
+		err := r.Message.PinnMessage(ctx, message.MessageID)
+		wantErr(t, "PinnMessage twice", err, model.ErrMessageAlreadyPinned)
+		err = r.Message.PinnMessage(ctx, missingID)
+		wantErr(t, "PinnMessage for a missing message", err, model.ErrMessageNotFound)
+
+		pinned, err := r.Message.GetPinnedMessages(ctx, channel.ChannelID)
+		if err != nil {
+			t.Fatalf("GetPinnedMessages: %v", err)
+		}
+		if len(pinned) != 1 || pinned[0].MessageID != message.MessageID {
+			t.Errorf("GetPinnedMessages returned %d messages, want the pinned one", len(pinned))
+		}
+
+		if err := r.Message.UnpinnMessage(ctx, message.MessageID); err != nil {
+			t.Fatalf("UnpinnMessage: %v", err)
+		}
+		err = r.Message.UnpinnMessage(ctx, message.MessageID)
+		wantErr(t, "UnpinnMessage twice", err, model.ErrMessageNotPinned)
+
+		// 外した後はもう一度ピン留めできる
+		if err := r.Message.PinnMessage(ctx, message.MessageID); err != nil {
+			t.Errorf("PinnMessage after UnpinnMessage: %v", err)
+		}
+	})
+
+	t.Run("replies", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		parent := createMessage(t, r, channel, alice, "question", nil)
+		first := createMessage(t, r, channel, alice, "answer 1", parent)
+		second := createMessage(t, r, channel, alice, "answer 2", parent)
+
+		replies, err := r.Message.GetReplies(ctx, parent.MessageID)
+		if err != nil {
+			t.Fatalf("GetReplies: %v", err)
+		}
+		if len(replies) != 2 {
+			t.Fatalf("GetReplies returned %d replies, want 2", len(replies))
+		}
+		// 返信は古い順に並ぶ
+		oldest, newest := first, second
+		if newer(first, second) {
+			oldest, newest = second, first
+		}
+		if replies[0].MessageID != oldest.MessageID || replies[1].MessageID != newest.MessageID {
+			t.Errorf("GetReplies is not ordered oldest first")
+		}
+
+		found, err := r.Message.GetMessage(ctx, parent.MessageID)
+		if err != nil {
+			t.Fatalf("GetMessage: %v", err)
+		}
+		if found.ReplyCount != 2 || found.LastReplyAt == nil {
+			t.Errorf("parent reply_count = %d, last_reply_at = %v", found.ReplyCount, found.LastReplyAt)
+		}
+
+		timeline, err := r.Message.GetMessages(ctx, channel.ChannelID, &model.MessageQuery{Limit: 10, ExcludeReplies: true})
+		if err != nil {
+			t.Fatalf("GetMessages: %v", err)
+		}
+		if len(timeline.Messages) != 1 || timeline.Messages[0].MessageID != parent.MessageID {
+			t.Errorf("GetMessages excluding replies returned %d messages, want only the parent", len(timeline.Messages))
+		}
+	})
+
+	t.Run("PatchMessage records the previous revision", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		message := createMessage(t, r, channel, alice, "first draft", nil)
+
+		patched, err := r.Message.PatchMessage(ctx, message.MessageID, &model.RequestPatchMessage{Content: ptr("second draft")})
+		if err != nil {
+			t.Fatalf("PatchMessage: %v", err)
+		}
+		if patched.Content != "second draft" || !patched.Edited || patched.RevisionCount != 1 {
+			t.Errorf("PatchMessage = content %q, edited %v, revision_count %d", patched.Content, patched.Edited, patched.RevisionCount)
+		}
+		if _, err := r.Message.PatchMessage(ctx, message.MessageID, &model.RequestPatchMessage{Content: ptr("final")}); err != nil {
+			t.Fatalf("PatchMessage: %v", err)
+		}
+
+		revisions, err := r.Message.GetRevisions(ctx, message.MessageID)
+		if err != nil {
+			t.Fatalf("GetRevisions: %v", err)
+		}
+		if len(revisions) != 2 || revisions[0].Content != "first draft" || revisions[1].Content != "second draft" {
+			t.Errorf("GetRevisions returned %d revisions in the wrong order", len(revisions))
+		}
+	})
+
+	t.Run("DeleteMessage and RestoreMessage include replies", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		parent := createMessage(t, r, channel, alice, "question", nil)
+		reply := createMessage(t, r, channel, alice, "answer", parent)
+
+		err := r.Message.RestoreMessage(ctx, parent.MessageID)
+		wantErr(t, "RestoreMessage for a live message", err, model.ErrMessageNotFound)
+
+		if err := r.Message.DeleteMessage(ctx, parent.MessageID); err != nil {
+			t.Fatalf("DeleteMessage: %v", err)
+		}
+		for _, message := range []*model.Message{parent, reply} {
+			_, err := r.Message.GetMessage(ctx, message.MessageID)
+			wantErr(t, "GetMessage after DeleteMessage", err, model.ErrMessageNotFound)
+		}
+		if _, err := r.Message.GetDeletedMessage(ctx, parent.MessageID); err != nil {
+			t.Errorf("GetDeletedMessage: %v", err)
+		}
+		err = r.Message.DeleteMessage(ctx, parent.MessageID)
+		wantErr(t, "DeleteMessage twice", err, model.ErrMessageNotFound)
+
+		if err := r.Message.RestoreMessage(ctx, parent.MessageID); err != nil {
+			t.Fatalf("RestoreMessage: %v", err)
+		}
+		for _, message := range []*model.Message{parent, reply} {
+			if _, err := r.Message.GetMessage(ctx, message.MessageID); err != nil {
+				t.Errorf("GetMessage after RestoreMessage: %v", err)
+			}
+		}
+	})
+
+	t.Run("hard deletes release attachments that no other message uses", func(t *testing.T) {
+		r, alice, channel := setup(t)
+		report := createAttachment(t, r, alice, "report")
+		shared := createAttachment(t, r, alice, "shared")
+		log := createAttachment(t, r, alice, "log")
+		post := func(content string, parent *model.Message, attachments ...*model.Attachment) *model.Message {
+			t.Helper()
+			req := &model.RequestCreateMessage{
+				ChannelID:   channel.ChannelID,
+				UserID:      alice.UserID,
+				Content:     content,
+				ContentType: model.ContentTypeText,
+			}
+			if parent != nil {
+				req.ParentMessageID = uuid.NullUUID{UUID: parent.MessageID, Valid: true}
+			}
+			for _, attachment := range attachments {
+				req.AttachmentIDs = append(req.AttachmentIDs, attachment.AttachmentID)
+			}
+			message, err := r.Message.CreateMessage(ctx, req)
+			if err != nil {
+				t.Fatalf("CreateMessage(%q): %v", content, err)
+			}
+			return message
+		}
+		burned := post("burned", nil, report, shared)
+		post("reply", burned, log)
+		kept := post("kept", nil, shared)
+
+		// 返信の添付ファイルも消え、他のメッセージにも添付したものは残る
+		if _, err := r.Message.BurnMessage(ctx, burned.MessageID); err != nil {
+			t.Fatalf("BurnMessage: %v", err)
+		}
+		for _, attachment := range []*model.Attachment{report, log} {
+			_, err := r.Attachment.GetAttachment(ctx, attachment.AttachmentID)
+			wantErr(t, "GetAttachment after BurnMessage", err, model.ErrAttachmentNotFound)
+		}
+		if _, err := r.Attachment.GetAttachment(ctx, shared.AttachmentID); err != nil {
+			t.Errorf("GetAttachment for an attachment still in use: %v", err)
+		}
+
+		if err := r.Message.DeleteMessage(ctx, kept.MessageID); err != nil {
+			t.Fatalf("DeleteMessage: %v", err)
+		}
+		if _, err := r.Message.PurgeDeletedMessages(ctx, time.Now().Add(time.Hour), 10); err != nil {
+			t.Fatalf("PurgeDeletedMessages: %v", err)
+		}
+		_, err := r.Attachment.GetAttachment(ctx, shared.AttachmentID)
+		wantErr(t, "GetAttachment after PurgeDeletedMessages", err, model.ErrAttachmentNotFound)
+
+		// 参照が外れていれば、スイーパーが本体を回収できる
+		blobs, err := r.Blob.DeleteUnreferencedBlobs(ctx, time.Now().Add(time.Hour), 100)
+		if err != nil {
+			t.Fatalf("DeleteUnreferencedBlobs: %v", err)
+		}
+		swept := map[string]bool{}
+		for _, blob := range blobs {
+			swept[blob.SHA256] = true
+		}
+		for _, attachment := range []*model.Attachment{report, shared, log} {
+			if !swept[attachment.SHA256] {
+				t.Errorf("blob of %s was not swept", attachment.FileName)
+			}
+		}
+	})
+}
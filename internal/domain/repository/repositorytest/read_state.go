@@ -0,0 +1,125 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// RunReadStateRepository は ReadStateRepository の振る舞いを確かめる
+func RunReadStateRepository(t *testing.T, newRepositories Factory) {
+	ctx := context.Background()
+
+	markRead := func(t *testing.T, r *Repositories, user *model.User, message *model.Message) *model.ChannelReadState {
+		t.Helper()
+		state, err := r.ReadState.MarkRead(ctx, &model.ChannelReadState{
+			UserID:            user.UserID,
+			ChannelID:         message.ChannelID,
+			LastReadMessageID: message.MessageID,
+			LastReadAt:        message.CreatedAt,
+		})
+		if err != nil {
+			t.Fatalf("MarkRead: %v", err)
+		}
+		return state
+	}
+
+	// unreadOf は GetUnreadCounts のうち channel の未読数を返す。一覧になければ nil
+	unreadOf := func(t *testing.T, r *Repositories, user *model.User, channel *model.Channel) *model.ChannelUnread {
+		t.Helper()
+		unread, err := r.ReadState.GetUnreadCounts(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("GetUnreadCounts: %v", err)
+		}
+		for _, counts := range unread {
+			if counts.ChannelID == channel.ChannelID {
+				return counts
+			}
+		}
+		return nil
+	}
+
+	t.Run("MarkRead only moves forward", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+		oldest := createMessage(t, r, channel, alice, "first", nil)
+		newest := createMessage(t, r, channel, alice, "second", nil)
+		if newer(oldest, newest) {
+			oldest, newest = newest, oldest
+		}
+
+		state := markRead(t, r, alice, newest)
+		if state.UserID != alice.UserID || state.ChannelID != channel.ChannelID || state.LastReadMessageID != newest.MessageID {
+			t.Errorf("MarkRead = %+v, want the newest message", state)
+		}
+		if state = markRead(t, r, alice, oldest); state.LastReadMessageID != newest.MessageID {
+			t.Errorf("MarkRead with an older message moved the position back to %s", state.LastReadMessageID)
+		}
+	})
+
+	t.Run("GetUnreadCounts counts messages of others after the read position", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bob_b")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+
+		createMessage(t, r, channel, alice, "my own message", nil)
+		hello := createMessage(t, r, channel, bob, "hello", nil)
+		mention := createMessage(t, r, channel, bob, "@alice look", nil)
+		saveMentions(t, r, mention, &model.MentionTargets{UserNames: []string{"alice"}})
+		// 他のユーザーへのメンションは数えない
+		own := createMessage(t, r, channel, alice, "@bob_b see mine", nil)
+		saveMentions(t, r, own, &model.MentionTargets{UserNames: []string{"bob_b"}})
+
+		counts := unreadOf(t, r, alice, channel)
+		if counts == nil {
+			t.Fatalf("GetUnreadCounts did not include the channel the user owns")
+		}
+		if counts.UnreadCount != 2 || counts.MentionCount != 1 || counts.LastReadMessageID.Valid || counts.LastReadAt != nil {
+			t.Errorf("before reading: %+v, want 2 unread, 1 mention and no read position", counts)
+		}
+
+		// 作成日時の精度が秒までのバックエンドでは同じ日時になりうるため、一覧の順で最新のものまで読む
+		last := mention
+		if newer(hello, mention) {
+			last = hello
+		}
+		markRead(t, r, alice, last)
+		counts = unreadOf(t, r, alice, channel)
+		if counts.UnreadCount != 0 || counts.MentionCount != 0 || counts.LastReadMessageID.UUID != last.MessageID || counts.LastReadAt == nil {
+			t.Errorf("after reading: %+v, want nothing unread at the last message", counts)
+		}
+
+		more := createMessage(t, r, channel, bob, "one more", nil)
+		want := 0
+		if newer(more, last) {
+			want = 1
+		}
+		if counts = unreadOf(t, r, alice, channel); counts.UnreadCount != want {
+			t.Errorf("after a new message: %d unread, want %d", counts.UnreadCount, want)
+		}
+	})
+
+	t.Run("GetUnreadCounts lists public channels only after they are read", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bob := createUser(t, r, "bobby")
+		public := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+		private := createChannel(t, r, "secret", model.ChannelVisibilityPrivate, alice)
+		message := createMessage(t, r, public, alice, "hello", nil)
+		createMessage(t, r, private, alice, "secret", nil)
+
+		if unreadOf(t, r, bob, public) != nil || unreadOf(t, r, bob, private) != nil {
+			t.Errorf("GetUnreadCounts included channels the user has neither joined nor read")
+		}
+		markRead(t, r, bob, message)
+		if unreadOf(t, r, bob, public) == nil {
+			t.Errorf("GetUnreadCounts did not include the public channel after MarkRead")
+		}
+
+		_, err := r.ReadState.GetUnreadCounts(ctx, missingID)
+		wantErr(t, "GetUnreadCounts for a missing user", err, model.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,123 @@
+// Package repositorytest は全てのバックエンドのリポジトリが同じ振る舞いをすることを確かめるテストを提供する
+// 各バックエンドのテストから Run に空のデータベースを使うリポジトリの作り方を渡して呼び出す
+package repositorytest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+// Repositories はテストで使うリポジトリ。全て同じデータベースを使う
+type Repositories struct {
+	User       repository.UserRepository
+	Channel    repository.ChannelRepository
+	Message    repository.MessageRepository
+	ReadState  repository.ReadStateRepository
+	Mention    repository.MentionRepository
+	Attachment repository.AttachmentRepository
+	Blob       repository.BlobRepository
+	Tx         repository.TxManager
+}
+
+// Factory は空のデータベースを用意し、それを使うリポジトリを返す。後片付けは t.Cleanup で行う
+type Factory func(t *testing.T) *Repositories
+
+// Run はユーザー、チャンネル、メッセージ、既読、メンションのリポジトリと TxManager のテストを全て実行する
+func Run(t *testing.T, newRepositories Factory) {
+	t.Run("User", func(t *testing.T) { RunUserRepository(t, newRepositories) })
+	t.Run("Channel", func(t *testing.T) { RunChannelRepository(t, newRepositories) })
+	t.Run("Message", func(t *testing.T) { RunMessageRepository(t, newRepositories) })
+	t.Run("ReadState", func(t *testing.T) { RunReadStateRepository(t, newRepositories) })
+	t.Run("Mention", func(t *testing.T) { RunMentionRepository(t, newRepositories) })
+	t.Run("TxManager", func(t *testing.T) { RunTxManager(t, newRepositories) })
+}
+
+// password は CreateUser で作るユーザーのパスワード
+const password = "Passw0rd"
+
+// missingID はどのテーブルにも存在しない ID
+var missingID = uuid.Must(uuid.FromString("00000000-0000-4000-8000-000000000000"))
+
+func createUser(t *testing.T, r *Repositories, userName string) *model.User {
+	t.Helper()
+	user, err := r.User.CreateUser(context.Background(), &model.RequestCreateUser{
+		UserName: userName,
+		Password: password,
+		Nickname: userName,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser(%q): %v", userName, err)
+	}
+	return user
+}
+
+func createChannel(t *testing.T, r *Repositories, channelName string, visibility string, owner *model.User) *model.Channel {
+	t.Helper()
+	channel, err := r.Channel.CreateChannel(context.Background(), &model.RequestCreateChannel{
+		ChannelName: channelName,
+		DisplayName: channelName,
+		Visibility:  visibility,
+		OwnerID:     owner.UserID,
+	})
+	if err != nil {
+		t.Fatalf("CreateChannel(%q): %v", channelName, err)
+	}
+	return channel
+}
+
+func createMessage(t *testing.T, r *Repositories, channel *model.Channel, user *model.User, content string, parent *model.Message) *model.Message {
+	t.Helper()
+	req := &model.RequestCreateMessage{
+		ChannelID:   channel.ChannelID,
+		UserID:      user.UserID,
+		Content:     content,
+		ContentType: model.ContentTypeText,
+	}
+	if parent != nil {
+		req.ParentMessageID = uuid.NullUUID{UUID: parent.MessageID, Valid: true}
+	}
+	message, err := r.Message.CreateMessage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateMessage(%q): %v", content, err)
+	}
+	return message
+}
+
+// createAttachment は name を内容とする添付ファイルを登録する。本体は保存しない
+func createAttachment(t *testing.T, r *Repositories, owner *model.User, name string) *model.Attachment {
+	t.Helper()
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])
+	attachment, err := r.Attachment.CreateAttachment(context.Background(), &model.Attachment{
+		AttachmentID: uuid.Must(uuid.NewV4()),
+		OwnerID:      owner.UserID,
+		FileName:     name + ".txt",
+		MimeType:     "text/plain",
+		Size:         int64(len(name)),
+		SHA256:       hash,
+		StorageKey:   "attachments/" + hash,
+	})
+	if err != nil {
+		t.Fatalf("CreateAttachment(%q): %v", name, err)
+	}
+	return attachment
+}
+
+// wantErr は err がリポジトリの返すエラーそのものであることを確かめる
+// ユースケースは == で比べるため、ラップされたエラーは認めない
+func wantErr(t *testing.T, op string, err error, want error) {
+	t.Helper()
+	if err != want {
+		t.Errorf("%s: err = %v, want %v", op, err, want)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,105 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// RunTxManager は TxManager がリポジトリの操作をまとめてコミット・ロールバックすることを確かめる
+func RunTxManager(t *testing.T, newRepositories Factory) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	// createUserAndChannel は ctx のトランザクションでユーザーとそのチャンネルを作る
+	createUserAndChannel := func(ctx context.Context, r *Repositories) error {
+		user, err := r.User.CreateUser(ctx, &model.RequestCreateUser{UserName: "alice", Password: password, Nickname: "alice"})
+		if err != nil {
+			return err
+		}
+		_, err = r.Channel.CreateChannel(ctx, &model.RequestCreateChannel{
+			ChannelName: "general",
+			DisplayName: "general",
+			Visibility:  model.ChannelVisibilityPublic,
+			OwnerID:     user.UserID,
+		})
+		return err
+	}
+
+	// countRows はトランザクションの外から見えるユーザーと公開チャンネルの数を返す
+	countRows := func(t *testing.T, r *Repositories) (users, channels int) {
+		t.Helper()
+		u, err := r.User.GetUsers(ctx)
+		if err != nil {
+			t.Fatalf("GetUsers: %v", err)
+		}
+		for _, user := range u {
+			c, err := r.Channel.GetChannels(ctx, user.UserID, false)
+			if err != nil {
+				t.Fatalf("GetChannels: %v", err)
+			}
+			channels += len(c)
+		}
+		return len(u), channels
+	}
+
+	t.Run("Do commits when fn succeeds", func(t *testing.T) {
+		r := newRepositories(t)
+		if err := r.Tx.Do(ctx, func(ctx context.Context) error { return createUserAndChannel(ctx, r) }); err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if users, channels := countRows(t, r); users != 1 || channels != 1 {
+			t.Errorf("after commit: %d users, %d channels, want 1 and 1", users, channels)
+		}
+	})
+
+	t.Run("Do rolls back when fn returns an error", func(t *testing.T) {
+		r := newRepositories(t)
+		err := r.Tx.Do(ctx, func(ctx context.Context) error {
+			if err := createUserAndChannel(ctx, r); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		wantErr(t, "Do", err, errAbort)
+		if users, channels := countRows(t, r); users != 0 || channels != 0 {
+			t.Errorf("after rollback: %d users, %d channels, want none", users, channels)
+		}
+	})
+
+	t.Run("Do rolls back and re-panics when fn panics", func(t *testing.T) {
+		r := newRepositories(t)
+		func() {
+			defer func() {
+				if p := recover(); p != "boom" {
+					t.Errorf("recovered %v, want the original panic", p)
+				}
+			}()
+			r.Tx.Do(ctx, func(ctx context.Context) error {
+				if err := createUserAndChannel(ctx, r); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		}()
+		if users, channels := countRows(t, r); users != 0 || channels != 0 {
+			t.Errorf("after panic: %d users, %d channels, want none", users, channels)
+		}
+	})
+
+	t.Run("nested Do joins the outer transaction", func(t *testing.T) {
+		r := newRepositories(t)
+		err := r.Tx.Do(ctx, func(ctx context.Context) error {
+			if err := r.Tx.Do(ctx, func(ctx context.Context) error { return createUserAndChannel(ctx, r) }); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		wantErr(t, "Do", err, errAbort)
+		if users, channels := countRows(t, r); users != 0 || channels != 0 {
+			t.Errorf("after the outer rollback: %d users, %d channels, want none", users, channels)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// RunUserRepository は UserRepository の振る舞いを確かめる
+func RunUserRepository(t *testing.T, newRepositories Factory) {
+	ctx := context.Background()
+
+	t.Run("GetUserByID returns ErrUserNotFound for a missing user", func(t *testing.T) {
+		r := newRepositories(t)
+		_, err := r.User.GetUserByID(ctx, missingID)
+		wantErr(t, "GetUserByID", err, model.ErrUserNotFound)
+	})
+
+	t.Run("CreateUser rejects a duplicate name regardless of case", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		createUser(t, r, "bobby")
+
+		_, err := r.User.CreateUser(ctx, &model.RequestCreateUser{UserName: "ALICE", Password: password, Nickname: "Alice"})
+		wantErr(t, "CreateUser", err, model.ErrAlreadyExistUserName)
+
+		found, err := r.User.GetUserByID(ctx, alice.UserID)
+		if err != nil {
+			t.Fatalf("GetUserByID: %v", err)
+		}
+		if found.UserName != "alice" || found.Nickname != "alice" {
+			t.Errorf("GetUserByID = %+v, want alice", found)
+		}
+	})
+
+	t.Run("GetUsers returns every user", func(t *testing.T) {
+		r := newRepositories(t)
+		want := map[string]bool{}
+		for _, name := range []string{"alice", "bobby", "carol"} {
+			want[createUser(t, r, name).UserID.String()] = true
+		}
+
+		users, err := r.User.GetUsers(ctx)
+		if err != nil {
+			t.Fatalf("GetUsers: %v", err)
+		}
+		if len(users) != len(want) {
+			t.Fatalf("GetUsers returned %d users, want %d", len(users), len(want))
+		}
+		for _, user := range users {
+			if !want[user.UserID.String()] {
+				t.Errorf("GetUsers returned unexpected user %s", user.UserName)
+			}
+		}
+	})
+
+	t.Run("VerifyPassword", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+
+		user, err := r.User.VerifyPassword(ctx, "Alice", password)
+		if err != nil {
+			t.Fatalf("VerifyPassword: %v", err)
+		}
+		if user.UserID != alice.UserID {
+			t.Errorf("VerifyPassword returned %s, want %s", user.UserID, alice.UserID)
+		}
+
+		_, err = r.User.VerifyPassword(ctx, "alice", "wrong password")
+		wantErr(t, "VerifyPassword with a wrong password", err, model.ErrInvalidCredentials)
+		_, err = r.User.VerifyPassword(ctx, "nobody", password)
+		wantErr(t, "VerifyPassword for a missing user", err, model.ErrInvalidCredentials)
+	})
+
+	t.Run("PatchUser", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+
+		patched, err := r.User.PatchUser(ctx, alice.UserID, &model.RequestPatchUser{Nickname: ptr("Alice"), Status: ptr("busy")})
+		if err != nil {
+			t.Fatalf("PatchUser: %v", err)
+		}
+		if patched.Nickname != "Alice" || patched.Status != "busy" || patched.UserName != "alice" {
+			t.Errorf("PatchUser = %+v", patched)
+		}
+
+		// 値が変わらなくても、ユーザーが存在すれば成功する
+		unchanged, err := r.User.PatchUser(ctx, alice.UserID, &model.RequestPatchUser{Nickname: ptr("Alice")})
+		if err != nil {
+			t.Fatalf("PatchUser without changes: %v", err)
+		}
+		if unchanged.Nickname != "Alice" {
+			t.Errorf("PatchUser without changes = %+v", unchanged)
+		}
+
+		empty, err := r.User.PatchUser(ctx, alice.UserID, &model.RequestPatchUser{})
+		if err != nil {
+			t.Fatalf("PatchUser without fields: %v", err)
+		}
+		if empty.UserID != alice.UserID {
+			t.Errorf("PatchUser without fields returned %s", empty.UserID)
+		}
+
+		_, err = r.User.PatchUser(ctx, bobby.UserID, &model.RequestPatchUser{UserName: ptr("ALICE")})
+		wantErr(t, "PatchUser to a taken name", err, model.ErrAlreadyExistUserName)
+		_, err = r.User.PatchUser(ctx, missingID, &model.RequestPatchUser{Nickname: ptr("nobody")})
+		wantErr(t, "PatchUser for a missing user", err, model.ErrUserNotFound)
+	})
+
+	t.Run("PatchUser keeps email addresses unique", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		bobby := createUser(t, r, "bobby")
+
+		if _, err := r.User.PatchUser(ctx, alice.UserID, &model.RequestPatchUser{Email: ptr("alice@example.com")}); err != nil {
+			t.Fatalf("PatchUser email: %v", err)
+		}
+		_, err := r.User.PatchUser(ctx, bobby.UserID, &model.RequestPatchUser{Email: ptr("ALICE@example.com")})
+		wantErr(t, "PatchUser to a taken email", err, model.ErrAlreadyExistEmail)
+
+		// 空にすると未設定に戻り、他のユーザーが使えるようになる
+		if _, err := r.User.PatchUser(ctx, alice.UserID, &model.RequestPatchUser{Email: ptr("")}); err != nil {
+			t.Fatalf("PatchUser clearing email: %v", err)
+		}
+		if _, err := r.User.PatchUser(ctx, bobby.UserID, &model.RequestPatchUser{Email: ptr("alice@example.com")}); err != nil {
+			t.Fatalf("PatchUser to a released email: %v", err)
+		}
+		// 未設定のユーザーは何人いてもよい
+		createUser(t, r, "carol")
+	})
+
+	t.Run("ChangePassword", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+
+		err := r.User.ChangePassword(ctx, alice.UserID, &model.RequestChangePassword{OldPassword: "wrong", NewPassword: "N3wPassword"})
+		if !errors.Is(err, model.ErrInvalidCredentials) {
+			t.Errorf("ChangePassword with a wrong password: err = %v, want %v", err, model.ErrInvalidCredentials)
+		}
+		err = r.User.ChangePassword(ctx, missingID, &model.RequestChangePassword{OldPassword: password, NewPassword: "N3wPassword"})
+		wantErr(t, "ChangePassword for a missing user", err, model.ErrUserNotFound)
+
+		if err := r.User.ChangePassword(ctx, alice.UserID, &model.RequestChangePassword{OldPassword: password, NewPassword: "N3wPassword"}); err != nil {
+			t.Fatalf("ChangePassword: %v", err)
+		}
+		if _, err := r.User.VerifyPassword(ctx, "alice", "N3wPassword"); err != nil {
+			t.Errorf("VerifyPassword with the new password: %v", err)
+		}
+		_, err = r.User.VerifyPassword(ctx, "alice", password)
+		wantErr(t, "VerifyPassword with the old password", err, model.ErrInvalidCredentials)
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		r := newRepositories(t)
+		alice := createUser(t, r, "alice")
+		channel := createChannel(t, r, "general", model.ChannelVisibilityPublic, alice)
+		createMessage(t, r, channel, alice, "hello", nil)
+
+		if err := r.User.DeleteUser(ctx, alice.UserID); err != nil {
+			t.Fatalf("DeleteUser: %v", err)
+		}
+		_, err := r.User.GetUserByID(ctx, alice.UserID)
+		wantErr(t, "GetUserByID after DeleteUser", err, model.ErrUserNotFound)
+		err = r.User.DeleteUser(ctx, alice.UserID)
+		wantErr(t, "DeleteUser twice", err, model.ErrUserNotFound)
+
+		// ユーザー名は再び使える
+		createUser(t, r, "alice")
+	})
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// SearchRepository はメッセージの全文検索を提供する
+// 外部の検索エンジンに差し替えられるよう、メッセージの保存とは分けている
+type SearchRepository interface {
+	SearchMessages(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *model.Session) error
+	GetSession(ctx context.Context, tokenHash string) (*model.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// TxManager は複数のリポジトリの操作を1つのトランザクションで実行する
+type TxManager interface {
+	// Do は fn を1つのトランザクションで実行し、fn が nil を返せばコミットする
+	// fn がエラーを返すか panic した場合はロールバックする。panic はロールバックした後にそのまま伝える
+	// リポジトリに fn が受け取った ctx を渡すと、その操作は同じトランザクションで実行される
+	// 既にトランザクションの中なら、新しく始めずにそのトランザクションに参加する
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
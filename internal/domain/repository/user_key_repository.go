@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type UserKeyRepository interface {
+	CreateUserKey(ctx context.Context, key *model.UserKey) (*model.UserKey, error)
+	GetUserKeys(ctx context.Context, userID uuid.UUID) ([]*model.UserKey, error)
+	DeleteUserKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error
+}
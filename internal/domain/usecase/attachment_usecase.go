@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type AttachmentUsecase interface {
+	UploadAttachment(ctx context.Context, req *model.RequestUploadAttachment) (*model.Attachment, error)
+	OpenAttachment(ctx context.Context, attachmentID uuid.UUID) (*model.Attachment, io.ReadSeekCloser, error)
+}
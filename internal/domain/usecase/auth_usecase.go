@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+type AuthUsecase interface {
+	Login(ctx context.Context, req *model.RequestLogin) (*model.ResponseLogin, error)
+	Logout(ctx context.Context, token string) error
+	Authenticate(ctx context.Context, token string) (*model.User, error)
+}
@@ -0,0 +1,8 @@
+package usecase
+
+import "context"
+
+type BlobUsecase interface {
+	// SweepBlobs は参照されなくなった blob を回収し、回収した件数を返す
+	SweepBlobs(ctx context.Context) (int, error)
+}
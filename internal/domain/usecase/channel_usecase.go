@@ -10,7 +10,14 @@ import (
 type ChannelUsecase interface {
 	CreateChannel(ctx context.Context, req *model.RequestCreateChannel) (*model.Channel, error)
 	GetChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error)
-	GetChannels(ctx context.Context) ([]*model.Channel, error)
+	// GetChannels は見えるチャンネルを返す。includeDeleted が true なら、管理しているゴミ箱のチャンネルも返す
+	GetChannels(ctx context.Context, includeDeleted bool) ([]*model.Channel, error)
+	// PatchChannel と DeleteChannel はチャンネルのオーナーか管理者のみ行える
 	PatchChannel(ctx context.Context, channelID uuid.UUID, req *model.RequestPatchChannel) (*model.Channel, error)
 	DeleteChannel(ctx context.Context, channelID uuid.UUID) error
+	RestoreChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error)
+
+	AddChannelMember(ctx context.Context, channelID uuid.UUID, req *model.RequestAddChannelMember) (*model.ChannelMember, error)
+	GetChannelMembers(ctx context.Context, channelID uuid.UUID) ([]*model.ChannelMember, error)
+	RemoveChannelMember(ctx context.Context, channelID uuid.UUID, req *model.RequestRemoveChannelMember) error
 }
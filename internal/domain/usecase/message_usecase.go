@@ -10,11 +10,25 @@ import (
 
 type MessageUsecase interface {
 	CreateMessage(ctx context.Context, req *model.RequestCreateMessage) (*model.Message, error)
-	GetMessages(ctx context.Context, channelID uuid.UUID, limit int, offset int) ([]*model.Message, error)
+	GetMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error)
+	GetMessages(ctx context.Context, channelID uuid.UUID, query *model.MessageQuery) (*model.MessagePage, error)
 	GetMessagesInDuration(ctx context.Context, channelID uuid.UUID, start, end time.Time) ([]*model.Message, error)
 	GetPinnedMessages(ctx context.Context, channelID uuid.UUID) ([]*model.Message, error)
+	GetReplies(ctx context.Context, messageID uuid.UUID) ([]*model.Message, error)
 	PatchMessage(ctx context.Context, messageID uuid.UUID, req *model.RequestPatchMessage) (*model.Message, error)
+	// GetRevisions は最新の版を含む全ての版を古い順に、1つ前の版との差分付きで返す
+	GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.MessageRevision, error)
 	PinnMessage(ctx context.Context, messageID uuid.UUID) error
 	UnpinnMessage(ctx context.Context, messageID uuid.UUID) error
+	// DeleteMessage はメッセージをゴミ箱に入れる
 	DeleteMessage(ctx context.Context, messageID uuid.UUID) error
+	// RestoreMessage はゴミ箱のメッセージを元に戻す。投稿者かチャンネルの管理者のみ戻せる
+	RestoreMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error)
+	// ReapExpiredMessages は期限切れのメッセージを削除し、削除した件数を返す
+	ReapExpiredMessages(ctx context.Context) (int, error)
+	// GetMentions は userID へのメンションをメッセージ付きで新しい順に返す。自分のメンションのみ取得できる
+	GetMentions(ctx context.Context, userID uuid.UUID, query *model.MentionQuery) (*model.MentionPage, error)
+
+	AddReaction(ctx context.Context, messageID uuid.UUID, req *model.RequestAddReaction) error
+	RemoveReaction(ctx context.Context, messageID uuid.UUID, emoji string) error
 }
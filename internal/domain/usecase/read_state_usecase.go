@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type ReadStateUsecase interface {
+	MarkChannelRead(ctx context.Context, channelID uuid.UUID, req *model.RequestMarkChannelRead) (*model.ChannelReadState, error)
+	GetUnreadCounts(ctx context.Context, userID uuid.UUID) ([]*model.ChannelUnread, error)
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+type SearchUsecase interface {
+	SearchMessages(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error)
+}
@@ -0,0 +1,8 @@
+package usecase
+
+import "context"
+
+type TrashUsecase interface {
+	// PurgeTrash は保持期間を過ぎたゴミ箱のチャンネルとメッセージを完全に削除し、削除した件数を返す
+	PurgeTrash(ctx context.Context) (int, error)
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+type UserKeyUsecase interface {
+	CreateUserKey(ctx context.Context, req *model.RequestCreateUserKey) (*model.UserKey, error)
+	GetUserKeys(ctx context.Context, userID uuid.UUID) ([]*model.UserKey, error)
+	DeleteUserKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error
+}
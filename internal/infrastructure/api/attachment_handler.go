@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+)
+
+// multipartOverhead はファイル以外のパートやヘッダに許す余裕
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentUsecase usecase.AttachmentUsecase
+}
+
+func NewAttachmentHandler(attachmentUsecase usecase.AttachmentUsecase) *AttachmentHandler {
+	return &AttachmentHandler{attachmentUsecase: attachmentUsecase}
+}
+
+// UploadAttachment : POST /v1/attachments (multipart/form-data の file フィールド)
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, model.MaxAttachmentSize+multipartOverhead)
+
+	// ファイル全体をメモリに載せないよう、パートを順に読む
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, r, errInvalidMultipart)
+		return
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			writeError(w, r, fmt.Errorf("%w: file field is required", errInvalidMultipart))
+			return
+		}
+		if err != nil {
+			writeError(w, r, errInvalidMultipart)
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.attachmentUsecase.UploadAttachment(r.Context(), &model.RequestUploadAttachment{
+			FileName: part.FileName(),
+			MimeType: part.Header.Get("Content-Type"),
+			Body:     part,
+		})
+		part.Close()
+		if err != nil {
+			// 上限を超えたことは読み込み中のエラーとして届く
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				err = model.ErrAttachmentTooLarge
+			}
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(attachment)
+		return
+	}
+}
+
+// DownloadAttachment : GET /v1/attachments/{attachmentID}
+// Range リクエストに対応する
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	attachmentID, err := getID(r, "attachmentID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	attachment, body, err := h.attachmentUsecase.OpenAttachment(r.Context(), attachmentID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	defer body.Close()
+
+	// 画像以外はブラウザ内で描画させない（アップロードされた HTML 等による XSS 対策）
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.MimeType, "image/") && attachment.MimeType != "image/svg+xml" {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+attachment.SHA256+`"`)
+
+	http.ServeContent(w, r, attachment.FileName, attachment.CreatedAt, body)
+}
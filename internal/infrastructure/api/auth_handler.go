@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+)
+
+type AuthHandler struct {
+	authUsecase usecase.AuthUsecase
+}
+
+func NewAuthHandler(authUsecase usecase.AuthUsecase) *AuthHandler {
+	return &AuthHandler{authUsecase: authUsecase}
+}
+
+// Login : POST /v1/auth/login
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req model.RequestLogin
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidRequestBody)
+		return
+	}
+
+	res, err := h.authUsecase.Login(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// ブラウザ向けに Cookie でも返す。API クライアントは Authorization ヘッダを使う
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    res.Token,
+		Path:     "/",
+		Expires:  res.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// Logout : POST /v1/auth/logout
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	err := h.authUsecase.Logout(r.Context(), sessionToken(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
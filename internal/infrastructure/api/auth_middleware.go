@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+)
+
+// sessionCookieName はセッショントークンを保持する Cookie 名
+const sessionCookieName = "session_token"
+
+// sessionToken は Authorization: Bearer ヘッダ、なければ Cookie からトークンを取り出す
+func sessionToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		if token, ok := strings.CutPrefix(h, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if cookie, err := r.Cookie(sessionCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+type AuthMiddleware struct {
+	authUsecase usecase.AuthUsecase
+}
+
+func NewAuthMiddleware(authUsecase usecase.AuthUsecase) *AuthMiddleware {
+	return &AuthMiddleware{authUsecase: authUsecase}
+}
+
+// Authenticate はセッショントークンを解決し、ユーザーをリクエストコンテキストに載せる
+// トークンがない・無効なリクエストは未認証のまま通し、拒否は RequireAuth に任せる
+// （期限切れの Cookie が残っていてもログインし直せるようにするため）
+func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := sessionToken(r)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := m.authUsecase.Authenticate(r.Context(), token)
+		if err != nil {
+			if err == model.ErrUnauthorized {
+				next.ServeHTTP(w, r)
+				return
+			}
+			writeError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
+
+// RequireAuth は認証済みでないリクエストを 401 で拒否する
+func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.UserFromContext(r.Context()); !ok {
+			writeError(w, r, model.ErrUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
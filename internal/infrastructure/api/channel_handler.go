@@ -20,17 +20,13 @@ func NewChannelHandler(channelUsecase usecase.ChannelUsecase) *ChannelHandler {
 func (h *ChannelHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
 	var req model.RequestCreateChannel
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
 	channel, err := h.channelUsecase.CreateChannel(r.Context(), &req)
 	if err != nil {
-		if err == model.ErrAlreadyExistChannelName {
-			http.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -41,18 +37,18 @@ func (h *ChannelHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
 func (h *ChannelHandler) GetChannelByName(w http.ResponseWriter, r *http.Request) {
 	channelID, err := getID(r, "channelID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	channel, err := h.channelUsecase.GetChannel(r.Context(), channelID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	if channel == nil {
-		http.Error(w, "Channel not found", http.StatusNotFound)
+		writeError(w, r, model.ErrChannelNotFound)
 		return
 	}
 
@@ -61,10 +57,12 @@ func (h *ChannelHandler) GetChannelByName(w http.ResponseWriter, r *http.Request
 }
 
 // GetChannels : POST /v1/channels
+// include_deleted=true を指定すると、管理しているゴミ箱のチャンネルも返す
 func (h *ChannelHandler) GetChannels(w http.ResponseWriter, r *http.Request) {
-	channels, err := h.channelUsecase.GetChannels(r.Context())
+	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
+	channels, err := h.channelUsecase.GetChannels(r.Context(), includeDeleted)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -76,24 +74,24 @@ func (h *ChannelHandler) GetChannels(w http.ResponseWriter, r *http.Request) {
 func (h *ChannelHandler) PatchChannel(w http.ResponseWriter, r *http.Request) {
 	channelID, err := getID(r, "channelID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	var req model.RequestPatchChannel
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
 	channel, err := h.channelUsecase.PatchChannel(r.Context(), channelID, &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	if channel == nil {
-		http.Error(w, "Channel not found", http.StatusNotFound)
+		writeError(w, r, model.ErrChannelNotFound)
 		return
 	}
 
@@ -101,16 +99,100 @@ func (h *ChannelHandler) PatchChannel(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(channel)
 }
 
+// DeleteChannel : DELETE /v1/channels/{channelID}
 func (h *ChannelHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
 	channelID, err := getID(r, "channelID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	err = h.channelUsecase.DeleteChannel(r.Context(), channelID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreChannel : POST /v1/channels/{channelID}/restore
+func (h *ChannelHandler) RestoreChannel(w http.ResponseWriter, r *http.Request) {
+	channelID, err := getID(r, "channelID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	channel, err := h.channelUsecase.RestoreChannel(r.Context(), channelID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(channel)
+}
+
+// AddChannelMember : POST /v1/channels/{channelID}/members
+func (h *ChannelHandler) AddChannelMember(w http.ResponseWriter, r *http.Request) {
+	channelID, err := getID(r, "channelID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req model.RequestAddChannelMember
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidRequestBody)
+		return
+	}
+
+	member, err := h.channelUsecase.AddChannelMember(r.Context(), channelID, &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(member)
+}
+
+// GetChannelMembers : GET /v1/channels/{channelID}/members
+func (h *ChannelHandler) GetChannelMembers(w http.ResponseWriter, r *http.Request) {
+	channelID, err := getID(r, "channelID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	members, err := h.channelUsecase.GetChannelMembers(r.Context(), channelID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// RemoveChannelMember : DELETE /v1/channels/{channelID}/members
+func (h *ChannelHandler) RemoveChannelMember(w http.ResponseWriter, r *http.Request) {
+	channelID, err := getID(r, "channelID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req model.RequestRemoveChannelMember
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidRequestBody)
+		return
+	}
+
+	if err := h.channelUsecase.RemoveChannelMember(r.Context(), channelID, &req); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// openAPISpec は /api/v1 以下の全ルートを記述した OpenAPI 3.1 のドキュメント
+// ルートを追加したらここにも追記する。漏れは openapi_test.go で検出する
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUI は docs ページが読み込む Swagger UI の配布元
+// @5 のような範囲指定では配信される内容が知らないうちに変わるため、バージョンを固定する
+const swaggerUI = "https://unpkg.com/swagger-ui-dist@5.17.14"
+
+// docsScript は docs ページで Swagger UI を起動するスクリプト
+// 検証バッジは外部のサービスに仕様を送るため表示しない
+const docsScript = `
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui", validatorUrl: null });
+    };
+  `
+
+// docsPage は openAPISpec を表示する Swagger UI のページ
+const docsPage = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+  <meta charset="utf-8">
+  <title>clipboard-server API</title>
+  <link rel="stylesheet" href="` + swaggerUI + `/swagger-ui.css" crossorigin>
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="` + swaggerUI + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>` + docsScript + `</script>
+</body>
+</html>
+`
+
+// docsPolicy は docs ページの Content-Security-Policy
+// スクリプトは固定したバージョンの Swagger UI と docsScript だけを実行できる
+var docsPolicy = func() string {
+	sum := sha256.Sum256([]byte(docsScript))
+	return strings.Join([]string{
+		"default-src 'none'",
+		"script-src " + swaggerUI + "/swagger-ui-bundle.js 'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'",
+		// Swagger UI は要素の style 属性で表示を切り替える
+		"style-src " + swaggerUI + "/swagger-ui.css 'unsafe-inline'",
+		"img-src 'self' data:",
+		"connect-src 'self'",
+		"base-uri 'none'",
+		"form-action 'none'",
+	}, "; ")
+}()
+
+type DocsHandler struct{}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// GetOpenAPISpec : GET /v1/openapi.json
+func (h *DocsHandler) GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// GetDocs : GET /docs
+func (h *DocsHandler) GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", docsPolicy)
+	w.Write([]byte(docsPage))
+}
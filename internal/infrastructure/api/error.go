@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/validate"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ハンドラーでリクエストを読むときに発生するエラー
+var (
+	errInvalidRequestBody   = errors.New("invalid request body")
+	errInvalidTimeFormat    = errors.New("invalid time format")
+	errInvalidMultipart     = errors.New("invalid multipart request")
+	errInvalidLastEventID   = errors.New("invalid Last-Event-ID")
+	errStreamingUnsupported = errors.New("streaming unsupported")
+)
+
+// errorResponse はエラー時のレスポンスボディ
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	// Code はクライアントが分岐に使う機械向けのコード。メッセージと違って変更しない
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	// Fields は validation_failed のときに全てのフィールドエラーを返す
+	Fields []validate.FieldError `json:"fields,omitempty"`
+}
+
+// errorMapping はエラーと HTTP ステータス、コードの対応
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorTable は writeError が上から順に errors.Is で照合する対応表
+var errorTable = []errorMapping{
+	{model.ErrNilUUID, http.StatusBadRequest, "nil_uuid"},
+	{model.ErrInvalidUUID, http.StatusBadRequest, "invalid_uuid"},
+	{errInvalidRequestBody, http.StatusBadRequest, "invalid_request_body"},
+	{errInvalidTimeFormat, http.StatusBadRequest, "invalid_time_format"},
+	{errInvalidMultipart, http.StatusBadRequest, "invalid_multipart_request"},
+	{errInvalidLastEventID, http.StatusBadRequest, "invalid_last_event_id"},
+	{validate.ErrInvalid, http.StatusUnprocessableEntity, "validation_failed"},
+
+	{model.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{model.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{model.ErrForbidden, http.StatusForbidden, "forbidden"},
+
+	{model.ErrInvalidUserName, http.StatusBadRequest, "invalid_user_name"},
+	{model.ErrBadFormatUserName, http.StatusBadRequest, "bad_format_user_name"},
+	{model.ErrAlreadyExistUserName, http.StatusConflict, "user_name_already_exists"},
+	{model.ErrInvalidNickname, http.StatusBadRequest, "invalid_nickname"},
+	{model.ErrWeakPassword, http.StatusBadRequest, "weak_password"},
+	{model.ErrBadFormatEmail, http.StatusBadRequest, "bad_format_email"},
+	{model.ErrAlreadyExistEmail, http.StatusConflict, "email_already_exists"},
+	{model.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+
+	{model.ErrInvalidChannelName, http.StatusBadRequest, "invalid_channel_name"},
+	{model.ErrBadFormatChannelName, http.StatusBadRequest, "bad_format_channel_name"},
+	{model.ErrAlreadyExistChannelName, http.StatusConflict, "channel_name_already_exists"},
+	{model.ErrInvalidDisplayName, http.StatusBadRequest, "invalid_display_name"},
+	{model.ErrChannelNotFound, http.StatusNotFound, "channel_not_found"},
+	{model.ErrInvalidVisibility, http.StatusBadRequest, "invalid_visibility"},
+	{model.ErrInvalidChannelRole, http.StatusBadRequest, "invalid_channel_role"},
+	{model.ErrAlreadyChannelMember, http.StatusConflict, "already_channel_member"},
+	{model.ErrChannelMemberNotFound, http.StatusNotFound, "channel_member_not_found"},
+
+	{model.ErrInvalidMessageContent, http.StatusBadRequest, "invalid_message_content"},
+	{model.ErrNoFieldsToUpdate, http.StatusBadRequest, "no_fields_to_update"},
+	{model.ErrNothingChanged, http.StatusBadRequest, "nothing_changed"},
+	{model.ErrInvalidContentType, http.StatusBadRequest, "invalid_content_type"},
+	{model.ErrInvalidLanguage, http.StatusBadRequest, "invalid_language"},
+	{model.ErrInvalidExpiry, http.StatusBadRequest, "invalid_expiry"},
+	{model.ErrInvalidEnvelope, http.StatusBadRequest, "invalid_envelope"},
+	{model.ErrMessageNotFound, http.StatusNotFound, "message_not_found"},
+	{model.ErrInvalidRequestLimit, http.StatusBadRequest, "invalid_request_limit"},
+	{model.ErrInvalidTimeRange, http.StatusBadRequest, "invalid_time_range"},
+	{model.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{model.ErrInvalidSearchQuery, http.StatusBadRequest, "invalid_search_query"},
+	{model.ErrMessageAlreadyPinned, http.StatusConflict, "message_already_pinned"},
+	{model.ErrMessageNotPinned, http.StatusConflict, "message_not_pinned"},
+	{model.ErrInvalidParentMessage, http.StatusBadRequest, "invalid_parent_message"},
+	{model.ErrParentMessageDeleted, http.StatusConflict, "parent_message_deleted"},
+	{model.ErrInvalidEmoji, http.StatusBadRequest, "invalid_emoji"},
+	{model.ErrAlreadyReacted, http.StatusConflict, "already_reacted"},
+	{model.ErrReactionNotFound, http.StatusNotFound, "reaction_not_found"},
+
+	{model.ErrAttachmentNotFound, http.StatusNotFound, "attachment_not_found"},
+	{model.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "attachment_too_large"},
+	{model.ErrInvalidAttachment, http.StatusBadRequest, "invalid_attachment"},
+	{model.ErrTooManyAttachments, http.StatusBadRequest, "too_many_attachments"},
+	{model.ErrBlobNotFound, http.StatusNotFound, "blob_not_found"},
+
+	{model.ErrInvalidUserKey, http.StatusBadRequest, "invalid_user_key"},
+	{model.ErrUserKeyNotFound, http.StatusNotFound, "user_key_not_found"},
+	{model.ErrUserKeyAlreadyExists, http.StatusConflict, "user_key_already_exists"},
+}
+
+// writeError はエラーを対応表に従って JSON のエラーレスポンスにする
+// 対応表にないエラーは内部エラーとして記録し、詳細はクライアントに返さない
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	requestID := middleware.GetReqID(r.Context())
+	body := errorBody{
+		Code:      "internal_error",
+		Message:   "internal server error",
+		RequestID: requestID,
+	}
+	status := http.StatusInternalServerError
+	matched := false
+	for _, m := range errorTable {
+		if errors.Is(err, m.err) {
+			status, body.Code, body.Message = m.status, m.code, err.Error()
+			matched = true
+			break
+		}
+	}
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		body.Message = validate.ErrInvalid.Error()
+		body.Fields = fieldErrs
+	}
+	if !matched {
+		log.Printf("[%s] %s %s: %v", requestID, r.Method, r.URL.Path, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: body})
+}
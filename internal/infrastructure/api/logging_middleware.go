@@ -1,12 +1,18 @@
 package api
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 func init() {
@@ -26,10 +32,37 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 }
 
 func (lrw *loggingResponseWriter) Write(b []byte) (int, error) {
-	lrw.body.Write(b)
+	// イベントストリームやファイルのダウンロードはログ用に溜め込まない
+	if isLoggableContentType(lrw.Header().Get("Content-Type")) {
+		lrw.body.Write(b)
+	}
 	return lrw.ResponseWriter.Write(b)
 }
 
+// ストリーミングレスポンス（SSE）のために Flush を委譲する
+func (lrw *loggingResponseWriter) Flush() {
+	if f, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// WebSocket のアップグレードのために Hijack を委譲する
+func (lrw *loggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := lrw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijack not supported")
+	}
+	lrw.statusCode = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// isLoggableContentType はボディをログに残す対象か判定する
+func isLoggableContentType(contentType string) bool {
+	return contentType == "" ||
+		strings.HasPrefix(contentType, "application/json") ||
+		strings.HasPrefix(contentType, "text/plain")
+}
+
 // ミドルウェア本体
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -42,7 +75,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 
 		// リクエストボディを読み直せるように
 		var reqBody []byte
-		if r.Body != nil {
+		if r.Body != nil && isLoggableContentType(r.Header.Get("Content-Type")) {
 			reqBody, _ = io.ReadAll(r.Body)
 			r.Body = io.NopCloser(bytes.NewBuffer(reqBody))
 		}
@@ -50,7 +83,8 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(lrw, r)
 
 		log.Printf(
-			"[%s] %s %s %d %s\nRequest: %s\nResponse: %s\n",
+			"[%s] [%s] %s %s %d %s\nRequest: %s\nResponse: %s\n",
+			middleware.GetReqID(r.Context()),
 			r.RemoteAddr,
 			r.Method,
 			r.URL.Path,
@@ -2,12 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/go-chi/chi/v5"
 )
 
 type MessageHandler struct {
@@ -22,20 +26,13 @@ func NewMessageHandler(messageUsecase usecase.MessageUsecase) *MessageHandler {
 func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 	var req model.RequestCreateMessage
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
 	message, err := h.messageUsecase.CreateMessage(r.Context(), &req)
 	if err != nil {
-		if err == model.ErrChannelNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		} else if err == model.ErrInvalidMessageContent {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -43,71 +40,163 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetMessages : GET /v1/channels/{channelID}/messages
+// content_type を指定するとその種類のメッセージのみ返す
+// include_deleted=true を指定するとゴミ箱のメッセージも返す（チャンネルの管理者のみ）
+// before / after にカーソルを渡すとその位置から取得する
+// 前後のページのURLは Link ヘッダ (rel="next" / rel="prev") で返す
 func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 	channelID, err := getID(r, "channelID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+
+	query, err := parseMessageQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	page, err := h.messageUsecase.GetMessages(r.Context(), channelID, query)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	limitStr := r.URL.Query().Get("limit")
+	if link := pageLinks(r, query.Limit, page.NextCursor, page.PrevCursor); link != "" {
+		w.Header().Set("Link", link)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page.Messages)
+}
+
+func parseMessageQuery(r *http.Request) (*model.MessageQuery, error) {
+	q := r.URL.Query()
+	query := &model.MessageQuery{}
+
+	limitStr := q.Get("limit")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limitStr == "" {
 		limit = 100 // Default limit
 	}
+	query.Limit = limit
 
-	offsetStr := r.URL.Query().Get("offset")
+	offsetStr := q.Get("offset")
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offsetStr == "" {
 		offset = 0 // Default offset
 	}
+	query.Offset = offset
 
-	messages, err := h.messageUsecase.GetMessages(r.Context(), channelID, limit, offset)
+	query.ExcludeReplies = q.Get("exclude_replies") == "true"
+	query.ContentType = q.Get("content_type")
+	query.IncludeDeleted = q.Get("include_deleted") == "true"
+
+	if before := q.Get("before"); before != "" {
+		cursor, err := model.DecodeMessageCursor(before)
+		if err != nil {
+			return nil, err
+		}
+		query.Before = cursor
+	}
+	if after := q.Get("after"); after != "" {
+		cursor, err := model.DecodeMessageCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		query.After = cursor
+	}
+
+	return query, nil
+}
+
+// pageLinks は RFC 8288 形式の Link ヘッダ値を組み立てる
+func pageLinks(r *http.Request, limit int, next, prev *model.MessageCursor) string {
+	link := func(key string, cursor *model.MessageCursor, rel string) string {
+		u := *r.URL
+		q := u.Query()
+		q.Del("before")
+		q.Del("after")
+		q.Del("offset")
+		q.Set(key, cursor.Encode())
+		q.Set("limit", strconv.Itoa(limit))
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+	}
+
+	var links []string
+	if next != nil {
+		links = append(links, link("before", next, "next"))
+	}
+	if prev != nil {
+		links = append(links, link("after", prev, "prev"))
+	}
+	return strings.Join(links, ", ")
+}
+
+// GetMentions : GET /v1/users/{userID}/mentions
+// 自分へのメンションのみ取得できる。unread=true を指定すると未読のメンションのみ返す
+// 続きのページのURLは Link ヘッダ (rel="next") で返す
+func (h *MessageHandler) GetMentions(w http.ResponseWriter, r *http.Request) {
+	userID, err := getID(r, "userID")
 	if err != nil {
-		if err == model.ErrChannelNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	query := &model.MentionQuery{UnreadOnly: q.Get("unread") == "true"}
+	limitStr := q.Get("limit")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limitStr == "" {
+		limit = 20 // Default limit
+	}
+	query.Limit = limit
+	if s := q.Get("before"); s != "" {
+		if query.Before, err = model.DecodeMessageCursor(s); err != nil {
+			writeError(w, r, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	page, err := h.messageUsecase.GetMentions(r.Context(), userID, query)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	if link := pageLinks(r, query.Limit, page.NextCursor, nil); link != "" {
+		w.Header().Set("Link", link)
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(messages)
+	json.NewEncoder(w).Encode(page.Mentions)
 }
 
 // GetMessagesInDuration : GET /v1/channels/{channelID}/messages/span
 func (h *MessageHandler) GetMessagesInDuration(w http.ResponseWriter, r *http.Request) {
 	channelID, err := getID(r, "channelID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	startStr := r.URL.Query().Get("start")
 	startTime, err := time.Parse(time.RFC3339, startStr)
 	if err != nil {
-		http.Error(w, "Invalid start time format", http.StatusBadRequest)
+		writeError(w, r, fmt.Errorf("%w: start", errInvalidTimeFormat))
 		return
 	}
 
 	endStr := r.URL.Query().Get("end")
 	endTime, err := time.Parse(time.RFC3339, endStr)
 	if err != nil {
-		http.Error(w, "Invalid end time format", http.StatusBadRequest)
+		writeError(w, r, fmt.Errorf("%w: end", errInvalidTimeFormat))
 		return
 	}
 
 	messages, err := h.messageUsecase.GetMessagesInDuration(r.Context(), channelID, startTime, endTime)
 	if err != nil {
-		if err == model.ErrChannelNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		} else if err == model.ErrInvalidTimeRange {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -119,17 +208,13 @@ func (h *MessageHandler) GetMessagesInDuration(w http.ResponseWriter, r *http.Re
 func (h *MessageHandler) GetPinnedMessages(w http.ResponseWriter, r *http.Request) {
 	channelID, err := getID(r, "channelID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	messages, err := h.messageUsecase.GetPinnedMessages(r.Context(), channelID)
 	if err != nil {
-		if err == model.ErrChannelNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -137,30 +222,83 @@ func (h *MessageHandler) GetPinnedMessages(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(messages)
 }
 
+// GetMessage : GET /v1/messages/{messageID}
+// burn_after_read のメッセージは投稿者以外のログインユーザーが取得すると削除される
+func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, err := getID(r, "messageID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	message, err := h.messageUsecase.GetMessage(r.Context(), messageID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// 一度きりのメッセージがキャッシュに残らないようにする
+	if message.BurnAfterRead {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
+
+// GetReplies : GET /v1/messages/{messageID}/replies
+func (h *MessageHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	messageID, err := getID(r, "messageID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	messages, err := h.messageUsecase.GetReplies(r.Context(), messageID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// GetRevisions : GET /v1/messages/{messageID}/revisions
+// 最新の版を含む全ての版を古い順に、1つ前の版との差分付きで返す
+func (h *MessageHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	messageID, err := getID(r, "messageID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	revisions, err := h.messageUsecase.GetRevisions(r.Context(), messageID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
 // PatchMessage : PATCH /v1/messages/{messageID}
 func (h *MessageHandler) PatchMessage(w http.ResponseWriter, r *http.Request) {
 	messageID, err := getID(r, "messageID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	var req model.RequestPatchMessage
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
 	message, err := h.messageUsecase.PatchMessage(r.Context(), messageID, &req)
 	if err != nil {
-		if err == model.ErrMessageNotFound || err == model.ErrChannelNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		} else if err == model.ErrInvalidMessageContent {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -171,17 +309,13 @@ func (h *MessageHandler) PatchMessage(w http.ResponseWriter, r *http.Request) {
 func (h *MessageHandler) PinnMessage(w http.ResponseWriter, r *http.Request) {
 	messageID, err := getID(r, "messageID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	err = h.messageUsecase.PinnMessage(r.Context(), messageID)
 	if err != nil {
-		if err == model.ErrMessageNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -192,17 +326,13 @@ func (h *MessageHandler) PinnMessage(w http.ResponseWriter, r *http.Request) {
 func (h *MessageHandler) UnpinnMessage(w http.ResponseWriter, r *http.Request) {
 	messageID, err := getID(r, "messageID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	err = h.messageUsecase.UnpinnMessage(r.Context(), messageID)
 	if err != nil {
-		if err == model.ErrMessageNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -213,17 +343,76 @@ func (h *MessageHandler) UnpinnMessage(w http.ResponseWriter, r *http.Request) {
 func (h *MessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
 	messageID, err := getID(r, "messageID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	err = h.messageUsecase.DeleteMessage(r.Context(), messageID)
 	if err != nil {
-		if err == model.ErrMessageNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreMessage : POST /v1/messages/{messageID}/restore
+func (h *MessageHandler) RestoreMessage(w http.ResponseWriter, r *http.Request) {
+	messageID, err := getID(r, "messageID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	message, err := h.messageUsecase.RestoreMessage(r.Context(), messageID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
+
+// AddReaction : POST /v1/messages/{messageID}/reactions
+func (h *MessageHandler) AddReaction(w http.ResponseWriter, r *http.Request) {
+	messageID, err := getID(r, "messageID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req model.RequestAddReaction
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidRequestBody)
+		return
+	}
+
+	if err := h.messageUsecase.AddReaction(r.Context(), messageID, &req); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveReaction : DELETE /v1/messages/{messageID}/reactions/{emoji}
+func (h *MessageHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	messageID, err := getID(r, "messageID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// 絵文字はパーセントエンコードされて届く
+	emoji, err := url.PathUnescape(chi.URLParam(r, "emoji"))
+	if err != nil {
+		writeError(w, r, model.ErrInvalidEmoji)
+		return
+	}
+
+	if err := h.messageUsecase.RemoveReaction(r.Context(), messageID, emoji); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const apiPrefix = "/api/v1"
+
+// registeredRoutes は Router.Setup に登録された /api/v1 以下のルートを "METHOD /path" の形で返す
+func registeredRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+
+	handler := NewRouter(nil, nil, nil, nil, nil, nil, nil, nil, nil).Setup()
+	routes, ok := handler.(chi.Routes)
+	if !ok {
+		t.Fatalf("Setup() returned %T, want chi.Routes", handler)
+	}
+
+	result := map[string]bool{}
+	walk := func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, apiPrefix+"/") {
+			return nil
+		}
+		path := strings.TrimSuffix(strings.TrimPrefix(route, apiPrefix), "/")
+		result[method+" "+path] = true
+		return nil
+	}
+	if err := chi.Walk(routes, walk); err != nil {
+		t.Fatalf("chi.Walk: %v", err)
+	}
+	return result
+}
+
+// specRoutes は openapi.json に記述されたルートを "METHOD /path" の形で返す
+func specRoutes(t *testing.T) map[string]bool {
+	t.Helper()
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("failed to parse openapi.json: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.1") {
+		t.Errorf("openapi = %q, want 3.1.x", spec.OpenAPI)
+	}
+
+	result := map[string]bool{}
+	for path, item := range spec.Paths {
+		for method := range item {
+			switch method {
+			case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+				result[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+	return result
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	registered := registeredRoutes(t)
+	documented := specRoutes(t)
+
+	var missing, stale []string
+	for route := range registered {
+		if !documented[route] {
+			missing = append(missing, route)
+		}
+	}
+	for route := range documented {
+		if !registered[route] {
+			stale = append(stale, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+
+	for _, route := range missing {
+		t.Errorf("route %s is registered but missing from openapi.json", route)
+	}
+	for _, route := range stale {
+		t.Errorf("route %s is documented in openapi.json but not registered", route)
+	}
+}
+
+func TestOpenAPISpecReferences(t *testing.T) {
+	var spec map[string]any
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("failed to parse openapi.json: %v", err)
+	}
+
+	// $ref が全て定義済みのコンポーネントを指しているか確かめる
+	var check func(v any)
+	check = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for key, child := range v {
+				if ref, ok := child.(string); ok && key == "$ref" {
+					if !resolves(spec, ref) {
+						t.Errorf("unresolved $ref %q", ref)
+					}
+					continue
+				}
+				check(child)
+			}
+		case []any:
+			for _, child := range v {
+				check(child)
+			}
+		}
+	}
+	check(spec)
+}
+
+func resolves(spec map[string]any, ref string) bool {
+	if !strings.HasPrefix(ref, "#/") {
+		return false
+	}
+	var node any = spec
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return false
+		}
+		if node, ok = m[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+)
+
+type ReadStateHandler struct {
+	readStateUsecase usecase.ReadStateUsecase
+}
+
+func NewReadStateHandler(readStateUsecase usecase.ReadStateUsecase) *ReadStateHandler {
+	return &ReadStateHandler{readStateUsecase: readStateUsecase}
+}
+
+// MarkChannelRead : POST /v1/channels/{channelID}/read
+// message_id まで読んだことを記録する。既に先まで読んでいれば位置は戻さない
+func (h *ReadStateHandler) MarkChannelRead(w http.ResponseWriter, r *http.Request) {
+	channelID, err := getID(r, "channelID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req model.RequestMarkChannelRead
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidRequestBody)
+		return
+	}
+
+	state, err := h.readStateUsecase.MarkChannelRead(r.Context(), channelID, &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// GetUnreadCounts : GET /v1/users/{userID}/unread
+// 自分の未読数のみ取得できる
+func (h *ReadStateHandler) GetUnreadCounts(w http.ResponseWriter, r *http.Request) {
+	userID, err := getID(r, "userID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	unread, err := h.readStateUsecase.GetUnreadCounts(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unread)
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestIDHeader はリクエスト ID を返すレスポンスヘッダ
+const requestIDHeader = "X-Request-Id"
+
+// RequestIDMiddleware は middleware.RequestID が割り当てた ID をレスポンスヘッダで返す
+// エラーレスポンスの request_id と同じ値になり、ログとの突き合わせに使える
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestIDHeader, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	}))
+}
@@ -4,22 +4,45 @@ import (
 	"net/http"
 
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/stream"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 )
 
 type Router struct {
-	channelUsecase usecase.ChannelUsecase
-	messageUsecase usecase.MessageUsecase
-	userUsecase    usecase.UserUsecase
+	channelUsecase    usecase.ChannelUsecase
+	messageUsecase    usecase.MessageUsecase
+	userUsecase       usecase.UserUsecase
+	authUsecase       usecase.AuthUsecase
+	searchUsecase     usecase.SearchUsecase
+	attachmentUsecase usecase.AttachmentUsecase
+	userKeyUsecase    usecase.UserKeyUsecase
+	readStateUsecase  usecase.ReadStateUsecase
+	hub               *stream.Hub
 }
 
-func NewRouter(channelUsecase usecase.ChannelUsecase, messageUsecase usecase.MessageUsecase, userUsecase usecase.UserUsecase) *Router {
+func NewRouter(
+	channelUsecase usecase.ChannelUsecase,
+	messageUsecase usecase.MessageUsecase,
+	userUsecase usecase.UserUsecase,
+	authUsecase usecase.AuthUsecase,
+	searchUsecase usecase.SearchUsecase,
+	attachmentUsecase usecase.AttachmentUsecase,
+	userKeyUsecase usecase.UserKeyUsecase,
+	readStateUsecase usecase.ReadStateUsecase,
+	hub *stream.Hub,
+) *Router {
 	return &Router{
-		channelUsecase: channelUsecase,
-		messageUsecase: messageUsecase,
-		userUsecase:    userUsecase,
+		channelUsecase:    channelUsecase,
+		messageUsecase:    messageUsecase,
+		userUsecase:       userUsecase,
+		authUsecase:       authUsecase,
+		searchUsecase:     searchUsecase,
+		attachmentUsecase: attachmentUsecase,
+		userKeyUsecase:    userKeyUsecase,
+		readStateUsecase:  readStateUsecase,
+		hub:               hub,
 	}
 }
 
@@ -27,6 +50,7 @@ func (r *Router) Setup() http.Handler {
 	router := chi.NewRouter()
 
 	// ミドルウェアの設定
+	router.Use(RequestIDMiddleware)
 	router.Use(LoggingMiddleware)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
@@ -34,52 +58,122 @@ func (r *Router) Setup() http.Handler {
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", requestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
 	router.Route("/api/v1", func(v1 chi.Router) {
+		// セッショントークンからユーザーを解決する
+		authMiddleware := NewAuthMiddleware(r.authUsecase)
+		v1.Use(authMiddleware.Authenticate)
+
+		// API ドキュメント
+		docsHandler := NewDocsHandler()
+		v1.Get("/openapi.json", docsHandler.GetOpenAPISpec)
+
+		// 認証API
+		authHandler := NewAuthHandler(r.authUsecase)
+		v1.Route("/auth", func(a chi.Router) {
+			a.Post("/login", authHandler.Login)
+			a.With(authMiddleware.RequireAuth).Post("/logout", authHandler.Logout)
+		})
+
 		// ユーザーAPI
 		userHandler := NewUserHandler(r.userUsecase)
+		userKeyHandler := NewUserKeyHandler(r.userKeyUsecase)
+		readStateHandler := NewReadStateHandler(r.readStateUsecase)
+		messageHandler := NewMessageHandler(r.messageUsecase)
 		v1.Route("/users", func(user chi.Router) {
 			user.Post("/", userHandler.CreateUser)
 			user.Get("/", userHandler.GetUsers)
 			user.Get("/{userID}", userHandler.GetUserByID)
-			user.Patch("/{userID}", userHandler.PatchUser)
-			user.Post("/{userID}/change-password", userHandler.ChangePassword)
-			user.Delete("/{userID}", userHandler.DeleteUser)
+			user.With(authMiddleware.RequireAuth).Patch("/{userID}", userHandler.PatchUser)
+			user.With(authMiddleware.RequireAuth).Post("/{userID}/change-password", userHandler.ChangePassword)
+			user.With(authMiddleware.RequireAuth).Delete("/{userID}", userHandler.DeleteUser)
+
+			// エンドツーエンド暗号化用の公開鍵
+			user.Get("/{userID}/keys", userKeyHandler.GetUserKeys)
+			user.With(authMiddleware.RequireAuth).Post("/{userID}/keys", userKeyHandler.CreateUserKey)
+			user.With(authMiddleware.RequireAuth).Delete("/{userID}/keys/{keyID}", userKeyHandler.DeleteUserKey)
+
+			// チャンネルごとの未読数
+			user.With(authMiddleware.RequireAuth).Get("/{userID}/unread", readStateHandler.GetUnreadCounts)
+			// 自分へのメンション
+			user.With(authMiddleware.RequireAuth).Get("/{userID}/mentions", messageHandler.GetMentions)
 		})
 
 		// チャンネルAPI
 		channelHandler := NewChannelHandler(r.channelUsecase)
-		messageHandler := NewMessageHandler(r.messageUsecase)
+		streamHandler := NewStreamHandler(r.channelUsecase, r.hub)
 		v1.Route("/channels", func(channel chi.Router) {
 			channel.Post("/", channelHandler.CreateChannel)
 			channel.Get("/", channelHandler.GetChannels)
 
 			channel.Route("/{channelID}", func(ch chi.Router) {
 				ch.Get("/", channelHandler.GetChannelByName)
-				ch.Patch("/", channelHandler.PatchChannel)
-				ch.Delete("/", channelHandler.DeleteChannel)
+				ch.Group(func(authed chi.Router) {
+					authed.Use(authMiddleware.RequireAuth)
+					authed.Patch("/", channelHandler.PatchChannel)
+					authed.Delete("/", channelHandler.DeleteChannel)
+					authed.Post("/restore", channelHandler.RestoreChannel)
+				})
+
+				// チャンネルのメンバー
+				ch.Get("/members", channelHandler.GetChannelMembers)
+				ch.With(authMiddleware.RequireAuth).Post("/members", channelHandler.AddChannelMember)
+				ch.With(authMiddleware.RequireAuth).Delete("/members", channelHandler.RemoveChannelMember)
 
 				// チャンネルごとのメッセージ
 				ch.Get("/messages", messageHandler.GetMessages)
 				ch.Get("/messages/span", messageHandler.GetMessagesInDuration)
 				ch.Get("/messages/pinned", messageHandler.GetPinnedMessages)
+
+				// 既読の位置
+				ch.With(authMiddleware.RequireAuth).Post("/read", readStateHandler.MarkChannelRead)
+
+				// チャンネルのイベントストリーム
+				ch.Get("/stream", streamHandler.Stream)
+				ch.Get("/events", streamHandler.Events)
 			})
 		})
 
 		// メッセージAPI
 		v1.Route("/messages", func(message chi.Router) {
-			message.Post("/", messageHandler.CreateMessage)
-			message.Patch("/{messageID}", messageHandler.PatchMessage)
-			message.Delete("/{messageID}", messageHandler.DeleteMessage)
-			message.Post("/{messageID}/pin", messageHandler.PinnMessage)
-			message.Post("/{messageID}/unpin", messageHandler.UnpinnMessage)
+			message.Get("/{messageID}", messageHandler.GetMessage)
+			message.Get("/{messageID}/replies", messageHandler.GetReplies)
+			message.Get("/{messageID}/revisions", messageHandler.GetRevisions)
+
+			message.Group(func(authed chi.Router) {
+				authed.Use(authMiddleware.RequireAuth)
+				authed.Post("/", messageHandler.CreateMessage)
+				authed.Patch("/{messageID}", messageHandler.PatchMessage)
+				authed.Delete("/{messageID}", messageHandler.DeleteMessage)
+				authed.Post("/{messageID}/restore", messageHandler.RestoreMessage)
+				authed.Post("/{messageID}/pin", messageHandler.PinnMessage)
+				authed.Post("/{messageID}/unpin", messageHandler.UnpinnMessage)
+				authed.Post("/{messageID}/reactions", messageHandler.AddReaction)
+				authed.Delete("/{messageID}/reactions/{emoji}", messageHandler.RemoveReaction)
+			})
+		})
+
+		// 添付ファイルAPI
+		attachmentHandler := NewAttachmentHandler(r.attachmentUsecase)
+		v1.Route("/attachments", func(attachment chi.Router) {
+			attachment.With(authMiddleware.RequireAuth).Post("/", attachmentHandler.UploadAttachment)
+			attachment.Get("/{attachmentID}", attachmentHandler.DownloadAttachment)
+		})
+
+		// 検索API
+		searchHandler := NewSearchHandler(r.searchUsecase)
+		v1.Route("/search", func(search chi.Router) {
+			search.Get("/messages", searchHandler.SearchMessages)
 		})
 	})
 
+	// API ドキュメントのビューア
+	router.Get("/docs", NewDocsHandler().GetDocs)
+
 	// 静的ファイルの配信（CSS、JS、画像など）
 	router.Get("/styles.css", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "./view/styles.css")
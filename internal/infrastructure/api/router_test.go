@@ -0,0 +1,756 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/blobstore"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/memory"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/stream"
+	"github.com/base-intern-august-b/clipboard-server/internal/usecase"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// newTestClient はメモリ上のリポジトリとローカルの BlobStore で Router.Setup() を動かす
+func newTestClient(t *testing.T) *apiClient {
+	t.Helper()
+
+	db := memory.NewDB()
+	userRepo := memory.NewUserRepository(db)
+	channelRepo := memory.NewChannelRepository(db)
+	messageRepo := memory.NewMessageRepository(db)
+	attachmentRepo := memory.NewAttachmentRepository(db)
+	txManager := memory.NewTxManager(db)
+
+	blobStore, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	hub := stream.NewHub(stream.DefaultBufferSize, stream.DefaultHistorySize)
+
+	router := NewRouter(
+		usecase.NewChannelUsecase(channelRepo, txManager),
+		usecase.NewMessageUsecase(messageRepo, channelRepo, memory.NewReactionRepository(db), attachmentRepo, memory.NewMentionRepository(db), txManager, hub),
+		usecase.NewUserUsecase(userRepo),
+		usecase.NewAuthUsecase(userRepo, memory.NewSessionRepository(db), usecase.DefaultSessionTTL),
+		usecase.NewSearchUsecase(memory.NewSearchRepository(db)),
+		usecase.NewAttachmentUsecase(attachmentRepo, channelRepo, blobStore),
+		usecase.NewUserKeyUsecase(memory.NewUserKeyRepository(db), userRepo),
+		usecase.NewReadStateUsecase(memory.NewReadStateRepository(db), channelRepo, messageRepo),
+		hub,
+	)
+	mux, ok := router.Setup().(*chi.Mux)
+	if !ok {
+		t.Fatalf("Setup() did not return *chi.Mux")
+	}
+	server := httptest.NewServer(mux)
+	// main と同じく、停止時にハブを閉じて配信中のストリームを終わらせる
+	server.Config.RegisterOnShutdown(hub.Close)
+	t.Cleanup(server.Close)
+	return &apiClient{server: server, mux: mux, vars: map[string]string{}}
+}
+
+// upload は multipart/form-data の file フィールドとして送るファイル
+type upload struct {
+	fileName    string
+	contentType string
+	data        string
+}
+
+// apiTest はルートへの1回のリクエストと期待する結果
+type apiTest struct {
+	name string
+	// as は Authorization ヘッダに使うトークンの変数名。空なら未認証
+	as     string
+	method string
+	// path の {name} は vars の値で置き換える
+	path string
+	// query の {name} も vars の値で置き換える
+	query string
+	// body は JSON にして送る。upload なら multipart で送る
+	body any
+	// websocket なら WebSocket として接続する
+	websocket bool
+	want      int
+	// save はレスポンスの JSON オブジェクトのフィールドを変数に保存する（変数名 → フィールド名）
+	save  map[string]string
+	check func(t *testing.T, res *apiResponse)
+}
+
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (r *apiResponse) object(t *testing.T) map[string]any {
+	t.Helper()
+	var v map[string]any
+	if err := json.Unmarshal(r.body, &v); err != nil {
+		t.Fatalf("response is not a JSON object: %v: %s", err, r.body)
+	}
+	return v
+}
+
+func (r *apiResponse) array(t *testing.T) []any {
+	t.Helper()
+	var v []any
+	if err := json.Unmarshal(r.body, &v); err != nil {
+		t.Fatalf("response is not a JSON array: %v: %s", err, r.body)
+	}
+	return v
+}
+
+// wantLen はレスポンスが n 件の JSON 配列であることを確かめる
+func wantLen(n int) func(t *testing.T, res *apiResponse) {
+	return func(t *testing.T, res *apiResponse) {
+		if got := len(res.array(t)); got != n {
+			t.Errorf("got %d items, want %d: %s", got, n, res.body)
+		}
+	}
+}
+
+// wantField はレスポンスの JSON オブジェクトのフィールドの値を確かめる
+func wantField(field string, want any) func(t *testing.T, res *apiResponse) {
+	return func(t *testing.T, res *apiResponse) {
+		if got := res.object(t)[field]; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s = %v, want %v", field, got, want)
+		}
+	}
+}
+
+// wantErrorCode はエラーレスポンスのコードを確かめる
+func wantErrorCode(code string) func(t *testing.T, res *apiResponse) {
+	return func(t *testing.T, res *apiResponse) {
+		var body errorResponse
+		if err := json.Unmarshal(res.body, &body); err != nil {
+			t.Fatalf("response is not an error envelope: %v: %s", err, res.body)
+		}
+		if body.Error.Code != code {
+			t.Errorf("error code = %q, want %q", body.Error.Code, code)
+		}
+	}
+}
+
+// wantUnread は未読数の一覧のうち channelName のチャンネルの未読数とメンション数を確かめる
+func wantUnread(channelName string, unread, mentions int) func(t *testing.T, res *apiResponse) {
+	return func(t *testing.T, res *apiResponse) {
+		for _, item := range res.array(t) {
+			counts := item.(map[string]any)
+			if counts["channel_name"] != channelName {
+				continue
+			}
+			if fmt.Sprint(counts["unread_count"]) != fmt.Sprint(unread) || fmt.Sprint(counts["mention_count"]) != fmt.Sprint(mentions) {
+				t.Errorf("%s: unread_count = %v, mention_count = %v, want %d and %d",
+					channelName, counts["unread_count"], counts["mention_count"], unread, mentions)
+			}
+			return
+		}
+		t.Errorf("no unread counts for %s: %s", channelName, res.body)
+	}
+}
+
+// wantMention はメンション一覧が1件だけで、その種類と既読かどうかが一致することを確かめる
+func wantMention(kind string, read bool) func(t *testing.T, res *apiResponse) {
+	return func(t *testing.T, res *apiResponse) {
+		items := res.array(t)
+		if len(items) != 1 {
+			t.Fatalf("got %d mentions, want 1: %s", len(items), res.body)
+		}
+		mention := items[0].(map[string]any)
+		if mention["kind"] != kind || mention["read"] != read {
+			t.Errorf("kind = %v, read = %v, want %s and %v", mention["kind"], mention["read"], kind, read)
+		}
+	}
+}
+
+type apiClient struct {
+	server *httptest.Server
+	mux    *chi.Mux
+	// vars は前のレスポンスから保存した ID やトークン
+	vars map[string]string
+}
+
+func (c *apiClient) expand(s string) string {
+	for name, value := range c.vars {
+		s = strings.ReplaceAll(s, "{"+name+"}", value)
+	}
+	return s
+}
+
+func (c *apiClient) url(tt apiTest) string {
+	u := c.server.URL + apiPrefix + c.expand(tt.path)
+	if tt.query != "" {
+		u += "?" + c.expand(tt.query)
+	}
+	return u
+}
+
+func (c *apiClient) header(tt apiTest) http.Header {
+	header := http.Header{}
+	if tt.as != "" {
+		header.Set("Authorization", "Bearer "+c.vars[tt.as])
+	}
+	return header
+}
+
+func (c *apiClient) do(t *testing.T, tt apiTest) *apiResponse {
+	t.Helper()
+	if tt.websocket {
+		return c.dial(t, tt)
+	}
+
+	var body io.Reader
+	header := c.header(tt)
+	switch b := tt.body.(type) {
+	case nil:
+	case upload:
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		partHeader := textproto.MIMEHeader{}
+		partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, b.fileName))
+		partHeader.Set("Content-Type", b.contentType)
+		part, err := w.CreatePart(partHeader)
+		if err != nil {
+			t.Fatalf("CreatePart: %v", err)
+		}
+		part.Write([]byte(b.data))
+		w.Close()
+		body = &buf
+		header.Set("Content-Type", w.FormDataContentType())
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		body = strings.NewReader(c.expand(string(data)))
+		header.Set("Content-Type", "application/json")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, tt.method, c.url(tt), body)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header = header
+
+	res, err := c.server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", tt.method, req.URL, err)
+	}
+	defer res.Body.Close()
+
+	var data []byte
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		// ストリームは終わらないため、再送されたイベントを読み終えたら切断する
+		data = readReplay(t, res.Body)
+	} else if data, err = io.ReadAll(res.Body); err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return &apiResponse{status: res.StatusCode, header: res.Header, body: data}
+}
+
+// readReplay は接続直後に送られたイベントを、しばらく何も届かなくなるまで読んで返す
+// retry の指定は読み飛ばす
+func readReplay(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+	lines := make(chan string)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var events bytes.Buffer
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return events.Bytes()
+			}
+			if !strings.HasPrefix(line, "retry:") {
+				events.WriteString(line + "\n")
+			}
+		case <-time.After(200 * time.Millisecond):
+			return events.Bytes()
+		}
+	}
+}
+
+// eventIDs は SSE のレスポンスに含まれるイベントIDを順に返す
+func eventIDs(t *testing.T, body []byte) []uint64 {
+	t.Helper()
+	var ids []uint64
+	for _, line := range strings.Split(string(body), "\n") {
+		if v, ok := strings.CutPrefix(line, "id: "); ok {
+			id, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				t.Fatalf("invalid event id %q", v)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// dial は WebSocket で接続し、ハンドシェイクのレスポンスを返す
+func (c *apiClient) dial(t *testing.T, tt apiTest) *apiResponse {
+	t.Helper()
+	wsURL := "ws" + strings.TrimPrefix(c.url(tt), "http")
+	conn, res, err := websocket.DefaultDialer.Dial(wsURL, c.header(tt))
+	if err != nil {
+		if res == nil {
+			t.Fatalf("dial %s: %v", wsURL, err)
+		}
+		data, _ := io.ReadAll(res.Body)
+		return &apiResponse{status: res.StatusCode, header: res.Header, body: data}
+	}
+	conn.Close()
+	return &apiResponse{status: res.StatusCode, header: res.Header}
+}
+
+// route はリクエストが一致したルートを registeredRoutes と同じ "METHOD /path" の形で返す
+func (c *apiClient) route(tt apiTest) string {
+	pattern := c.mux.Find(chi.NewRouteContext(), tt.method, apiPrefix+c.expand(tt.path))
+	return tt.method + " " + strings.TrimSuffix(strings.TrimPrefix(pattern, apiPrefix), "/")
+}
+
+func (c *apiClient) run(t *testing.T, tests []apiTest) map[string]bool {
+	t.Helper()
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		covered[c.route(tt)] = true
+		ok := t.Run(tt.name, func(t *testing.T) {
+			res := c.do(t, tt)
+			if res.status != tt.want {
+				t.Fatalf("%s %s: status = %d, want %d: %s", tt.method, tt.path, res.status, tt.want, res.body)
+			}
+			for name, field := range tt.save {
+				value, ok := res.object(t)[field].(string)
+				if !ok {
+					t.Fatalf("response has no string field %q: %s", field, res.body)
+				}
+				c.vars[name] = value
+			}
+			if tt.check != nil {
+				tt.check(t, res)
+			}
+		})
+		if !ok {
+			// 後のリクエストは前の結果に依存するため、失敗したらそこで止める
+			t.FailNow()
+		}
+	}
+	return covered
+}
+
+func TestRoutes(t *testing.T) {
+	c := newTestClient(t)
+	c.vars["missingID"] = uuid.Must(uuid.NewV4()).String()
+	c.vars["emoji"] = url.PathEscape("👍")
+	c.vars["start"] = url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	c.vars["end"] = url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	publicKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	// replayed は最初から再送したイベントのID。途中からの再送と比べる
+	var replayed []uint64
+
+	tests := []apiTest{
+		// ドキュメント
+		{name: "openapi", method: "GET", path: "/openapi.json", want: http.StatusOK,
+			check: func(t *testing.T, res *apiResponse) {
+				if res.object(t)["openapi"] == nil {
+					t.Errorf("spec has no openapi field")
+				}
+			}},
+
+		// ユーザー
+		{name: "create alice", method: "POST", path: "/users",
+			body: map[string]any{"user_name": "alice", "password": "Passw0rdA", "nickname": "Alice"},
+			want: http.StatusCreated, save: map[string]string{"aliceID": "user_id"}},
+		{name: "create bob", method: "POST", path: "/users",
+			body: map[string]any{"user_name": "bobby", "password": "Passw0rdB", "nickname": "Bob"},
+			want: http.StatusCreated, save: map[string]string{"bobID": "user_id"}},
+		{name: "create duplicate user", method: "POST", path: "/users",
+			body: map[string]any{"user_name": "ALICE", "password": "Passw0rdA", "nickname": "Alice"},
+			want: http.StatusConflict, check: wantErrorCode("user_name_already_exists")},
+		{name: "create invalid user", method: "POST", path: "/users",
+			body: map[string]any{"user_name": "carol"},
+			want: http.StatusUnprocessableEntity, check: wantErrorCode("validation_failed")},
+		{name: "list users", method: "GET", path: "/users", want: http.StatusOK, check: wantLen(2)},
+		{name: "get user with invalid ID", method: "GET", path: "/users/alice",
+			want: http.StatusBadRequest, check: wantErrorCode("invalid_uuid")},
+		{name: "get alice", method: "GET", path: "/users/{aliceID}", want: http.StatusOK,
+			check: wantField("user_name", "alice")},
+		{name: "get missing user", method: "GET", path: "/users/{missingID}", want: http.StatusNotFound,
+			check: wantErrorCode("user_not_found")},
+
+		// 認証
+		{name: "login alice", method: "POST", path: "/auth/login",
+			body: map[string]any{"user_name": "Alice", "password": "Passw0rdA"},
+			want: http.StatusOK, save: map[string]string{"alice": "token"}},
+		{name: "login bob", method: "POST", path: "/auth/login",
+			body: map[string]any{"user_name": "bobby", "password": "Passw0rdB"},
+			want: http.StatusOK, save: map[string]string{"bob": "token"}},
+
+		// ユーザーの変更は本人のみ
+		{name: "patch user without session", method: "PATCH", path: "/users/{aliceID}",
+			body: map[string]any{"nickname": "Mallory"},
+			want: http.StatusUnauthorized, check: wantErrorCode("unauthorized")},
+		{name: "patch other user", as: "bob", method: "PATCH", path: "/users/{aliceID}",
+			body: map[string]any{"nickname": "Mallory"},
+			want: http.StatusForbidden, check: wantErrorCode("forbidden")},
+		{name: "patch alice", as: "alice", method: "PATCH", path: "/users/{aliceID}",
+			body: map[string]any{"nickname": "Alice A.", "email": "alice@example.com"},
+			want: http.StatusOK, check: wantField("nickname", "Alice A.")},
+		{name: "patch bob to taken name", as: "bob", method: "PATCH", path: "/users/{bobID}",
+			body: map[string]any{"user_name": "alice"},
+			want: http.StatusConflict, check: wantErrorCode("user_name_already_exists")},
+		{name: "patch bob to taken email", as: "bob", method: "PATCH", path: "/users/{bobID}",
+			body: map[string]any{"email": "ALICE@example.com"},
+			want: http.StatusConflict, check: wantErrorCode("email_already_exists")},
+		{name: "change password without session", method: "POST", path: "/users/{aliceID}/change-password",
+			body: map[string]any{"old_password": "Passw0rdA", "new_password": "Passw0rdA2"},
+			want: http.StatusUnauthorized, check: wantErrorCode("unauthorized")},
+		{name: "change password of other user", as: "bob", method: "POST", path: "/users/{aliceID}/change-password",
+			body: map[string]any{"old_password": "Passw0rdA", "new_password": "Passw0rdA2"},
+			want: http.StatusForbidden, check: wantErrorCode("forbidden")},
+		{name: "change password to the same password", as: "alice", method: "POST", path: "/users/{aliceID}/change-password",
+			body: map[string]any{"old_password": "Passw0rdA", "new_password": "Passw0rdA"},
+			want: http.StatusNoContent},
+		{name: "change password", as: "alice", method: "POST", path: "/users/{aliceID}/change-password",
+			body: map[string]any{"old_password": "Passw0rdA", "new_password": "Passw0rdA2"},
+			want: http.StatusNoContent},
+		{name: "change password with wrong old password", as: "alice", method: "POST", path: "/users/{aliceID}/change-password",
+			body: map[string]any{"old_password": "Passw0rdA", "new_password": "Passw0rdA3"},
+			want: http.StatusUnauthorized, check: wantErrorCode("invalid_credentials")},
+		{name: "login with old password", method: "POST", path: "/auth/login",
+			body: map[string]any{"user_name": "alice", "password": "Passw0rdA"},
+			want: http.StatusUnauthorized, check: wantErrorCode("invalid_credentials")},
+		{name: "login with new password", method: "POST", path: "/auth/login",
+			body: map[string]any{"user_name": "alice", "password": "Passw0rdA2"},
+			want: http.StatusOK},
+		{name: "logout without session", method: "POST", path: "/auth/logout",
+			want: http.StatusUnauthorized, check: wantErrorCode("unauthorized")},
+
+		// 公開鍵
+		{name: "create key without session", method: "POST", path: "/users/{aliceID}/keys",
+			body: map[string]any{"algorithm": "x25519", "public_key": publicKey}, want: http.StatusUnauthorized},
+		{name: "create key", as: "alice", method: "POST", path: "/users/{aliceID}/keys",
+			body: map[string]any{"algorithm": "x25519", "public_key": publicKey},
+			want: http.StatusCreated, save: map[string]string{"keyID": "key_id"}},
+		{name: "create duplicate key", as: "alice", method: "POST", path: "/users/{aliceID}/keys",
+			body: map[string]any{"algorithm": "x25519", "public_key": publicKey},
+			want: http.StatusConflict, check: wantErrorCode("user_key_already_exists")},
+		{name: "list keys", method: "GET", path: "/users/{aliceID}/keys", want: http.StatusOK, check: wantLen(1)},
+		{name: "delete key of other user", as: "bob", method: "DELETE", path: "/users/{aliceID}/keys/{keyID}",
+			want: http.StatusForbidden},
+		{name: "delete key", as: "alice", method: "DELETE", path: "/users/{aliceID}/keys/{keyID}",
+			want: http.StatusNoContent},
+		{name: "delete missing key", as: "alice", method: "DELETE", path: "/users/{aliceID}/keys/{keyID}",
+			want: http.StatusNotFound, check: wantErrorCode("user_key_not_found")},
+
+		// チャンネル
+		{name: "create channel", as: "alice", method: "POST", path: "/channels",
+			body: map[string]any{"channel_name": "general", "display_name": "General"},
+			want: http.StatusCreated, save: map[string]string{"channelID": "channel_id"}},
+		{name: "create duplicate channel", as: "bob", method: "POST", path: "/channels",
+			body: map[string]any{"channel_name": "General", "display_name": "General"},
+			want: http.StatusConflict, check: wantErrorCode("channel_name_already_exists")},
+		{name: "create private channel", as: "alice", method: "POST", path: "/channels",
+			body: map[string]any{"channel_name": "secret", "display_name": "Secret", "visibility": "private"},
+			want: http.StatusCreated, save: map[string]string{"privateID": "channel_id"}},
+		{name: "list channels as alice", as: "alice", method: "GET", path: "/channels", want: http.StatusOK, check: wantLen(2)},
+		{name: "list channels as bob", as: "bob", method: "GET", path: "/channels", want: http.StatusOK, check: wantLen(1)},
+		{name: "get channel", method: "GET", path: "/channels/{channelID}", want: http.StatusOK,
+			check: wantField("channel_name", "general")},
+		{name: "get private channel as non-member", as: "bob", method: "GET", path: "/channels/{privateID}",
+			want: http.StatusNotFound},
+		{name: "patch channel without session", method: "PATCH", path: "/channels/{channelID}",
+			body: map[string]any{"display_name": "hijacked"}, want: http.StatusUnauthorized},
+		{name: "patch channel as non-member", as: "bob", method: "PATCH", path: "/channels/{channelID}",
+			body: map[string]any{"display_name": "hijacked"}, want: http.StatusForbidden},
+		{name: "patch channel", as: "alice", method: "PATCH", path: "/channels/{channelID}",
+			body: map[string]any{"description": "everything"}, want: http.StatusOK,
+			check: wantField("description", "everything")},
+		{name: "join channel", as: "bob", method: "POST", path: "/channels/{channelID}/members",
+			body: map[string]any{"user_id": "{bobID}", "role": "member"}, want: http.StatusCreated, check: wantField("role", "member")},
+		{name: "join channel twice", as: "bob", method: "POST", path: "/channels/{channelID}/members",
+			body: map[string]any{"user_id": "{bobID}", "role": "member"}, want: http.StatusConflict,
+			check: wantErrorCode("already_channel_member")},
+		{name: "list members", method: "GET", path: "/channels/{channelID}/members", want: http.StatusOK, check: wantLen(2)},
+
+		// 添付ファイルとメッセージ
+		{name: "upload attachment", as: "alice", method: "POST", path: "/attachments",
+			body: upload{fileName: "notes.txt", contentType: "text/plain", data: "attached notes"},
+			want: http.StatusCreated, save: map[string]string{"attachmentID": "attachment_id"}},
+		{name: "stream channel", method: "GET", path: "/channels/{channelID}/stream", websocket: true,
+			want: http.StatusSwitchingProtocols},
+		{name: "create message without session", method: "POST", path: "/messages",
+			body: map[string]any{"channel_id": "{channelID}", "content": "hello"}, want: http.StatusUnauthorized},
+		{name: "create message", as: "alice", method: "POST", path: "/messages",
+			body: map[string]any{"channel_id": "{channelID}", "content": "hello world", "attachment_ids": []string{"{attachmentID}"}},
+			want: http.StatusCreated, save: map[string]string{"messageID": "message_id"},
+			check: func(t *testing.T, res *apiResponse) {
+				if got := res.object(t)["attachments"].([]any); len(got) != 1 {
+					t.Errorf("got %d attachments, want 1", len(got))
+				}
+			}},
+		{name: "create duplicate content", as: "bob", method: "POST", path: "/messages",
+			body: map[string]any{"channel_id": "{channelID}", "content": "hello world"},
+			want: http.StatusCreated, check: wantField("deduplicated", true)},
+		{name: "reply", as: "bob", method: "POST", path: "/messages",
+			body: map[string]any{"channel_id": "{channelID}", "parent_message_id": "{messageID}", "content": "a reply"},
+			want: http.StatusCreated, save: map[string]string{"replyID": "message_id"}},
+		{name: "events without last event id", method: "GET", path: "/channels/{channelID}/events",
+			want: http.StatusOK, check: func(t *testing.T, res *apiResponse) {
+				if ids := eventIDs(t, res.body); len(ids) != 0 {
+					t.Errorf("replayed %v to a fresh connection", ids)
+				}
+			}},
+		{name: "events since the beginning", method: "GET", path: "/channels/{channelID}/events", query: "last_event_id=0",
+			want: http.StatusOK, check: func(t *testing.T, res *apiResponse) {
+				if !strings.Contains(string(res.body), "message.created") {
+					t.Errorf("replay has no message.created: %s", res.body)
+				}
+				replayed = eventIDs(t, res.body)
+				if len(replayed) < 2 {
+					t.Fatalf("replayed %v, want at least 2 events", replayed)
+				}
+				c.vars["firstEventID"] = strconv.FormatUint(replayed[0], 10)
+			}},
+		{name: "events after last event id", method: "GET", path: "/channels/{channelID}/events", query: "last_event_id={firstEventID}",
+			want: http.StatusOK, check: func(t *testing.T, res *apiResponse) {
+				if got, want := fmt.Sprint(eventIDs(t, res.body)), fmt.Sprint(replayed[1:]); got != want {
+					t.Errorf("replayed %s, want %s", got, want)
+				}
+			}},
+		{name: "get message", method: "GET", path: "/messages/{messageID}", want: http.StatusOK,
+			check: wantField("reply_count", 1)},
+		{name: "get missing message", method: "GET", path: "/messages/{missingID}", want: http.StatusNotFound,
+			check: wantErrorCode("message_not_found")},
+		{name: "get replies", method: "GET", path: "/messages/{messageID}/replies", want: http.StatusOK, check: wantLen(1)},
+		{name: "download attachment", method: "GET", path: "/attachments/{attachmentID}", want: http.StatusOK,
+			check: func(t *testing.T, res *apiResponse) {
+				if string(res.body) != "attached notes" {
+					t.Errorf("body = %q, want %q", res.body, "attached notes")
+				}
+			}},
+		{name: "download missing attachment", method: "GET", path: "/attachments/{missingID}", want: http.StatusNotFound},
+		{name: "patch message of other user", as: "bob", method: "PATCH", path: "/messages/{messageID}",
+			body: map[string]any{"content": "hijacked"}, want: http.StatusForbidden},
+		{name: "patch message without fields", as: "alice", method: "PATCH", path: "/messages/{messageID}",
+			body: map[string]any{}, want: http.StatusBadRequest, check: wantErrorCode("no_fields_to_update")},
+		{name: "patch message with empty content", as: "alice", method: "PATCH", path: "/messages/{messageID}",
+			body: map[string]any{"content": ""}, want: http.StatusUnprocessableEntity,
+			check: func(t *testing.T, res *apiResponse) {
+				wantErrorCode("validation_failed")(t, res)
+				if !strings.Contains(string(res.body), `"field":"content","code":"required"`) {
+					t.Errorf("content is not reported as required: %s", res.body)
+				}
+			}},
+		{name: "patch message", as: "alice", method: "PATCH", path: "/messages/{messageID}",
+			body: map[string]any{"content": "hello there"}, want: http.StatusOK,
+			check: func(t *testing.T, res *apiResponse) {
+				wantField("content", "hello there")(t, res)
+				wantField("edited", true)(t, res)
+			}},
+		{name: "get revisions", method: "GET", path: "/messages/{messageID}/revisions", want: http.StatusOK, check: wantLen(2)},
+		{name: "list messages", method: "GET", path: "/channels/{channelID}/messages", query: "limit=2",
+			want: http.StatusOK, check: func(t *testing.T, res *apiResponse) {
+				wantLen(2)(t, res)
+				if link := res.header.Get("Link"); !strings.Contains(link, `rel="next"`) {
+					t.Errorf("Link = %q, want a next link", link)
+				}
+			}},
+		{name: "list messages with bad cursor", method: "GET", path: "/channels/{channelID}/messages", query: "before=%21",
+			want: http.StatusBadRequest, check: wantErrorCode("invalid_cursor")},
+		{name: "list messages in span", method: "GET", path: "/channels/{channelID}/messages/span",
+			query: "start={start}&end={end}", want: http.StatusOK, check: wantLen(3)},
+		{name: "search", method: "GET", path: "/search/messages", query: "q=THERE", want: http.StatusOK, check: wantLen(1)},
+		{name: "search without terms", method: "GET", path: "/search/messages", want: http.StatusBadRequest},
+
+		// ピン留めとリアクション
+		{name: "pin", as: "alice", method: "POST", path: "/messages/{messageID}/pin", want: http.StatusNoContent},
+		{name: "pin twice", as: "alice", method: "POST", path: "/messages/{messageID}/pin", want: http.StatusConflict,
+			check: wantErrorCode("message_already_pinned")},
+		{name: "list pinned", method: "GET", path: "/channels/{channelID}/messages/pinned", want: http.StatusOK, check: wantLen(1)},
+		{name: "unpin", as: "alice", method: "POST", path: "/messages/{messageID}/unpin", want: http.StatusNoContent},
+		{name: "unpin twice", as: "alice", method: "POST", path: "/messages/{messageID}/unpin", want: http.StatusConflict,
+			check: wantErrorCode("message_not_pinned")},
+		{name: "react", as: "bob", method: "POST", path: "/messages/{messageID}/reactions",
+			body: map[string]any{"emoji": "👍"}, want: http.StatusNoContent},
+		{name: "react twice", as: "bob", method: "POST", path: "/messages/{messageID}/reactions",
+			body: map[string]any{"emoji": "👍"}, want: http.StatusConflict, check: wantErrorCode("already_reacted")},
+		{name: "reactions are summarized", as: "bob", method: "GET", path: "/messages/{messageID}", want: http.StatusOK,
+			check: func(t *testing.T, res *apiResponse) {
+				reactions, _ := res.object(t)["reactions"].([]any)
+				if len(reactions) != 1 || fmt.Sprint(reactions[0].(map[string]any)["reacted"]) != "true" {
+					t.Errorf("reactions = %v, want one reaction by the viewer", reactions)
+				}
+			}},
+		{name: "unreact", as: "bob", method: "DELETE", path: "/messages/{messageID}/reactions/{emoji}", want: http.StatusNoContent},
+		{name: "unreact twice", as: "bob", method: "DELETE", path: "/messages/{messageID}/reactions/{emoji}",
+			want: http.StatusNotFound, check: wantErrorCode("reaction_not_found")},
+
+		// 既読と未読数
+		{name: "mention alice", as: "bob", method: "POST", path: "/messages",
+			body: map[string]any{"channel_id": "{channelID}", "content": "@alice see above"},
+			want: http.StatusCreated, save: map[string]string{"mentionID": "message_id"}},
+		{name: "unread without session", method: "GET", path: "/users/{aliceID}/unread", want: http.StatusUnauthorized},
+		{name: "unread of other user", as: "bob", method: "GET", path: "/users/{aliceID}/unread", want: http.StatusForbidden},
+		{name: "unread before reading", as: "alice", method: "GET", path: "/users/{aliceID}/unread", want: http.StatusOK,
+			check: func(t *testing.T, res *apiResponse) {
+				wantLen(2)(t, res)
+				wantUnread("general", 3, 1)(t, res)
+				wantUnread("secret", 0, 0)(t, res)
+			}},
+		{name: "mentions without session", method: "GET", path: "/users/{aliceID}/mentions", want: http.StatusUnauthorized},
+		{name: "mentions of other user", as: "bob", method: "GET", path: "/users/{aliceID}/mentions", want: http.StatusForbidden},
+		{name: "mentions with invalid limit", as: "alice", method: "GET", path: "/users/{aliceID}/mentions", query: "limit=0",
+			want: http.StatusBadRequest},
+		{name: "mentions before reading", as: "alice", method: "GET", path: "/users/{aliceID}/mentions", want: http.StatusOK,
+			check: wantMention("user", false)},
+		{name: "mark read without session", method: "POST", path: "/channels/{channelID}/read",
+			body: map[string]any{"message_id": "{mentionID}"}, want: http.StatusUnauthorized},
+		{name: "mark read with missing message", as: "alice", method: "POST", path: "/channels/{channelID}/read",
+			body: map[string]any{"message_id": "{missingID}"}, want: http.StatusNotFound, check: wantErrorCode("message_not_found")},
+		{name: "mark read with message of other channel", as: "alice", method: "POST", path: "/channels/{privateID}/read",
+			body: map[string]any{"message_id": "{mentionID}"}, want: http.StatusNotFound, check: wantErrorCode("message_not_found")},
+		{name: "mark read", as: "alice", method: "POST", path: "/channels/{channelID}/read",
+			body: map[string]any{"message_id": "{mentionID}"}, want: http.StatusOK},
+		// 古いメッセージを読んでも既読の位置は戻らない
+		{name: "mark older message read", as: "alice", method: "POST", path: "/channels/{channelID}/read",
+			body: map[string]any{"message_id": "{messageID}"}, want: http.StatusOK},
+		{name: "unread after reading", as: "alice", method: "GET", path: "/users/{aliceID}/unread", want: http.StatusOK,
+			check: wantUnread("general", 0, 0)},
+		{name: "mentions after reading", as: "alice", method: "GET", path: "/users/{aliceID}/mentions", want: http.StatusOK,
+			check: wantMention("user", true)},
+		{name: "unread mentions after reading", as: "alice", method: "GET", path: "/users/{aliceID}/mentions", query: "unread=true",
+			want: http.StatusOK, check: wantLen(0)},
+
+		// ゴミ箱
+		{name: "delete message of other user", as: "bob", method: "DELETE", path: "/messages/{messageID}", want: http.StatusForbidden},
+		{name: "delete message", as: "alice", method: "DELETE", path: "/messages/{messageID}", want: http.StatusNoContent},
+		{name: "deleted message is gone", method: "GET", path: "/messages/{messageID}", want: http.StatusNotFound},
+		{name: "deleted reply is gone", method: "GET", path: "/messages/{replyID}", want: http.StatusNotFound},
+		{name: "restore message", as: "alice", method: "POST", path: "/messages/{messageID}/restore", want: http.StatusOK,
+			check: wantField("reply_count", 1)},
+		{name: "restore live message", as: "alice", method: "POST", path: "/messages/{messageID}/restore", want: http.StatusNotFound},
+		{name: "leave channel", as: "bob", method: "DELETE", path: "/channels/{channelID}/members",
+			body: map[string]any{"user_id": "{bobID}"}, want: http.StatusNoContent},
+		{name: "leave channel twice", as: "bob", method: "DELETE", path: "/channels/{channelID}/members",
+			body: map[string]any{"user_id": "{bobID}"}, want: http.StatusNotFound},
+		{name: "delete private channel as non-member", as: "bob", method: "DELETE", path: "/channels/{privateID}",
+			want: http.StatusNotFound},
+		{name: "delete channel without session", method: "DELETE", path: "/channels/{channelID}", want: http.StatusUnauthorized},
+		{name: "delete channel as non-member", as: "bob", method: "DELETE", path: "/channels/{channelID}", want: http.StatusForbidden},
+		{name: "delete channel", as: "alice", method: "DELETE", path: "/channels/{channelID}", want: http.StatusNoContent},
+		{name: "deleted channel is gone", method: "GET", path: "/channels/{channelID}", want: http.StatusNotFound},
+		{name: "list deleted channels", as: "alice", method: "GET", path: "/channels", query: "include_deleted=true",
+			want: http.StatusOK, check: wantLen(2)},
+		{name: "restore channel without session", method: "POST", path: "/channels/{channelID}/restore", want: http.StatusUnauthorized},
+		{name: "restore channel as non-member", as: "bob", method: "POST", path: "/channels/{channelID}/restore", want: http.StatusForbidden},
+		{name: "restore channel", as: "alice", method: "POST", path: "/channels/{channelID}/restore", want: http.StatusOK,
+			check: wantField("channel_name", "general")},
+		{name: "restore live channel", as: "alice", method: "POST", path: "/channels/{channelID}/restore", want: http.StatusNotFound},
+
+		// 後片付け
+		{name: "logout", as: "bob", method: "POST", path: "/auth/logout", want: http.StatusNoContent},
+		{name: "logged out session is rejected", as: "bob", method: "POST", path: "/auth/logout", want: http.StatusUnauthorized},
+		{name: "login bob again", method: "POST", path: "/auth/login",
+			body: map[string]any{"user_name": "bobby", "password": "Passw0rdB"},
+			want: http.StatusOK, save: map[string]string{"bob": "token"}},
+		{name: "delete user without session", method: "DELETE", path: "/users/{bobID}",
+			want: http.StatusUnauthorized, check: wantErrorCode("unauthorized")},
+		{name: "delete other user", as: "alice", method: "DELETE", path: "/users/{bobID}",
+			want: http.StatusForbidden, check: wantErrorCode("forbidden")},
+		{name: "delete user", as: "bob", method: "DELETE", path: "/users/{bobID}", want: http.StatusNoContent},
+		{name: "deleted user is gone", method: "GET", path: "/users/{bobID}", want: http.StatusNotFound},
+		{name: "session of deleted user is gone", as: "bob", method: "DELETE", path: "/users/{bobID}",
+			want: http.StatusUnauthorized, check: wantErrorCode("unauthorized")},
+	}
+
+	covered := c.run(t, tests)
+
+	// Router.Setup に登録した全てのルートを少なくとも1回は呼び出す
+	var uncovered []string
+	for route := range registeredRoutes(t) {
+		if !covered[route] {
+			uncovered = append(uncovered, route)
+		}
+	}
+	sort.Strings(uncovered)
+	for _, route := range uncovered {
+		t.Errorf("route %s is not exercised by TestRoutes", route)
+	}
+}
+
+// 開いたままの SSE と WebSocket があっても Shutdown がタイムアウトせずに終わる
+func TestShutdownEndsStreams(t *testing.T) {
+	c := newTestClient(t)
+	c.run(t, []apiTest{
+		{name: "create alice", method: "POST", path: "/users",
+			body: map[string]any{"user_name": "alice", "password": "Passw0rdA", "nickname": "Alice"},
+			want: http.StatusCreated},
+		{name: "login alice", method: "POST", path: "/auth/login",
+			body: map[string]any{"user_name": "alice", "password": "Passw0rdA"},
+			want: http.StatusOK, save: map[string]string{"alice": "token"}},
+		{name: "create channel", as: "alice", method: "POST", path: "/channels",
+			body: map[string]any{"channel_name": "general", "display_name": "General"},
+			want: http.StatusCreated, save: map[string]string{"channelID": "channel_id"}},
+	})
+
+	sse, err := c.server.Client().Get(c.url(apiTest{path: "/channels/{channelID}/events"}))
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer sse.Body.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(c.url(apiTest{path: "/channels/{channelID}/stream"}), "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial %s: %v", wsURL, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.server.Config.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if _, err := io.ReadAll(sse.Body); err != nil {
+		t.Errorf("SSE stream did not end cleanly: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("WebSocket read: err = %v, want close %d", err, websocket.CloseGoingAway)
+	}
+}
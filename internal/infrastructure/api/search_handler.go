@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/gofrs/uuid"
+)
+
+type SearchHandler struct {
+	searchUsecase usecase.SearchUsecase
+}
+
+func NewSearchHandler(searchUsecase usecase.SearchUsecase) *SearchHandler {
+	return &SearchHandler{searchUsecase: searchUsecase}
+}
+
+// SearchMessages : GET /v1/search/messages?q=...
+// channel_id / user_id / from / to で絞り込み、before のカーソルで続きを取得する
+func (h *SearchHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := &model.SearchQuery{Query: q.Get("q")}
+
+	limitStr := q.Get("limit")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limitStr == "" {
+		limit = 20 // Default limit
+	}
+	query.Limit = limit
+
+	if s := q.Get("channel_id"); s != "" {
+		if query.ChannelID, err = uuid.FromString(s); err != nil {
+			writeError(w, r, fmt.Errorf("%w: channel_id", model.ErrInvalidUUID))
+			return
+		}
+	}
+	if s := q.Get("user_id"); s != "" {
+		if query.UserID, err = uuid.FromString(s); err != nil {
+			writeError(w, r, fmt.Errorf("%w: user_id", model.ErrInvalidUUID))
+			return
+		}
+	}
+	if s := q.Get("from"); s != "" {
+		from, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: from", errInvalidTimeFormat))
+			return
+		}
+		query.From = &from
+	}
+	if s := q.Get("to"); s != "" {
+		to, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: to", errInvalidTimeFormat))
+			return
+		}
+		query.To = &to
+	}
+	if s := q.Get("before"); s != "" {
+		if query.Before, err = model.DecodeMessageCursor(s); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	page, err := h.searchUsecase.SearchMessages(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if link := pageLinks(r, query.Limit, page.NextCursor, nil); link != "" {
+		w.Header().Set("Link", link)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page.Results)
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/stream"
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// クライアントへの書き込みに許す時間
+	wsWriteWait = 10 * time.Second
+	// クライアントからの pong を待つ時間
+	wsPongWait = 60 * time.Second
+	// ping の送信間隔。wsPongWait より短くする
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// アイドル状態の SSE 接続をプロキシに切られないためのハートビート間隔
+	sseHeartbeatPeriod = 15 * time.Second
+	// 切断時にクライアントが再接続するまでの待ち時間（ミリ秒）
+	sseRetryMillis = 3000
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// CORS と同様に全オリジンを許可する
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type StreamHandler struct {
+	channelUsecase usecase.ChannelUsecase
+	hub            *stream.Hub
+}
+
+func NewStreamHandler(channelUsecase usecase.ChannelUsecase, hub *stream.Hub) *StreamHandler {
+	return &StreamHandler{channelUsecase: channelUsecase, hub: hub}
+}
+
+// findChannel はパスのチャンネルが存在することを確認する
+// 存在しない場合はエラーレスポンスを書き込み、false を返す
+func (h *StreamHandler) findChannel(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	channelID, err := getID(r, "channelID")
+	if err != nil {
+		writeError(w, r, err)
+		return uuid.Nil, false
+	}
+
+	channel, err := h.channelUsecase.GetChannel(r.Context(), channelID)
+	if err != nil {
+		writeError(w, r, err)
+		return uuid.Nil, false
+	}
+	if channel == nil {
+		writeError(w, r, model.ErrChannelNotFound)
+		return uuid.Nil, false
+	}
+
+	return channelID, true
+}
+
+// Stream : GET /v1/channels/{channelID}/stream
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	channelID, ok := h.findChannel(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade がエラーレスポンスを書き込み済み
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub := h.hub.Subscribe(channelID)
+	defer sub.Close()
+
+	// 読み込み側: pong の受信と切断の検知のみ行う
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(512)
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				if sub.Dropped() {
+					// 送信バッファが溢れたため切り離された
+					conn.WriteMessage(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer"))
+				} else {
+					// サーバーの停止でハブが閉じられた
+					conn.WriteMessage(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+				}
+				return
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
+
+// Events : GET /v1/channels/{channelID}/events
+// WebSocket が使えないクライアント向けに同じイベントを Server-Sent Events で配信する
+func (h *StreamHandler) Events(w http.ResponseWriter, r *http.Request) {
+	channelID, ok := h.findChannel(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, errStreamingUnsupported)
+		return
+	}
+
+	// EventSource は再接続時に Last-Event-ID ヘッダを付与する
+	// ヘッダを付けられないクライアントのためにクエリパラメータも受け付ける
+	lastEventIDStr := r.Header.Get("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = r.URL.Query().Get("last_event_id")
+	}
+	// ID を送らない新しい接続には再送せず、購読開始後のイベントのみ配信する
+	var (
+		sub    *stream.Subscription
+		replay []*model.Event
+	)
+	if lastEventIDStr != "" {
+		lastEventID, err := strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			writeError(w, r, errInvalidLastEventID)
+			return
+		}
+		sub, replay = h.hub.SubscribeSince(channelID, lastEventID)
+	} else {
+		sub = h.hub.Subscribe(channelID)
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// nginx のレスポンスバッファリングを無効にする
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+	for _, e := range replay {
+		if err := writeSSEEvent(w, e); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(sseHeartbeatPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				// 送信バッファが溢れたか、サーバーの停止でハブが閉じられた
+				// クライアントは Last-Event-ID で再接続できる
+				return
+			}
+			if err := writeSSEEvent(w, e); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, e *model.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
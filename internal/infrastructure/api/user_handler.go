@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
@@ -20,17 +21,13 @@ func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req model.RequestCreateUser
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
 	user, err := h.userUsecase.CreateUser(r.Context(), &req)
 	if err != nil {
-		if err == model.ErrAlreadyExistUserName {
-			http.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -41,7 +38,7 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 	users, err := h.userUsecase.GetUsers(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to get users", http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
@@ -53,18 +50,18 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 	userID, err := getID(r, "userID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	user, err := h.userUsecase.GetUserByID(r.Context(), userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	if user == nil {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeError(w, r, model.ErrUserNotFound)
 		return
 	}
 
@@ -76,28 +73,24 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
 	userID, err := getID(r, "userID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	var req model.RequestPatchUser
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
 	user, err := h.userUsecase.PatchUser(r.Context(), userID, &req)
 	if err != nil {
-		if err == model.ErrAlreadyExistUserName {
-			http.Error(w, err.Error(), http.StatusConflict)
-			return
-		}
-		http.Error(w, "failed to patch user", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	if user == nil {
-		http.Error(w, "User not found", http.StatusNotFound)
+		writeError(w, r, model.ErrUserNotFound)
 		return
 	}
 
@@ -109,23 +102,20 @@ func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	userID, err := getID(r, "userID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	var req model.RequestChangePassword
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		writeError(w, r, errInvalidRequestBody)
 		return
 	}
 
+	// 同じパスワードへの変更は何もせずに成功として扱う
 	err = h.userUsecase.ChangePassword(r.Context(), userID, &req)
-	if err != nil {
-		if err == model.ErrNothingChanged {
-			http.Error(w, err.Error(), http.StatusNoContent)
-			return
-		}
-		http.Error(w, "failed to change password", http.StatusBadRequest)
+	if err != nil && !errors.Is(err, model.ErrNothingChanged) {
+		writeError(w, r, err)
 		return
 	}
 
@@ -136,13 +126,13 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID, err := getID(r, "userID")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	err = h.userUsecase.DeleteUser(r.Context(), userID)
 	if err != nil {
-		http.Error(w, "failed to delete user", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
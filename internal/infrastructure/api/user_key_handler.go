@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+)
+
+type UserKeyHandler struct {
+	userKeyUsecase usecase.UserKeyUsecase
+}
+
+func NewUserKeyHandler(userKeyUsecase usecase.UserKeyUsecase) *UserKeyHandler {
+	return &UserKeyHandler{userKeyUsecase: userKeyUsecase}
+}
+
+// CreateUserKey : POST /v1/users/{userID}/keys
+// public_key は base64 で渡す
+func (h *UserKeyHandler) CreateUserKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := getID(r, "userID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var req model.RequestCreateUserKey
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidRequestBody)
+		return
+	}
+	req.UserID = userID
+
+	key, err := h.userKeyUsecase.CreateUserKey(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+// GetUserKeys : GET /v1/users/{userID}/keys
+func (h *UserKeyHandler) GetUserKeys(w http.ResponseWriter, r *http.Request) {
+	userID, err := getID(r, "userID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	keys, err := h.userKeyUsecase.GetUserKeys(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// DeleteUserKey : DELETE /v1/users/{userID}/keys/{keyID}
+func (h *UserKeyHandler) DeleteUserKey(w http.ResponseWriter, r *http.Request) {
+	userID, err := getID(r, "userID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	keyID, err := getID(r, "keyID")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.userKeyUsecase.DeleteUserKey(r.Context(), userID, keyID); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,32 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/util"
+)
+
+// New は BLOB_STORE 環境変数 (local / s3) に応じた BlobStore を作成する
+func New(ctx context.Context) (repository.BlobStore, error) {
+	switch driver := util.GetEnvOrDefault("BLOB_STORE", "local"); driver {
+	case "local":
+		return NewLocalStore(util.GetEnvOrDefault("BLOB_DIR", "./data/blobs"))
+	case "s3":
+		return NewS3Store(ctx, S3())
+	default:
+		return nil, fmt.Errorf("unknown BLOB_STORE: %s", driver)
+	}
+}
+
+func S3() S3Config {
+	return S3Config{
+		Endpoint:  util.GetEnvOrDefault("S3_ENDPOINT", "localhost:9000"),
+		AccessKey: util.GetEnvOrDefault("S3_ACCESS_KEY", "minioadmin"),
+		SecretKey: util.GetEnvOrDefault("S3_SECRET_KEY", "minioadmin"),
+		Bucket:    util.GetEnvOrDefault("S3_BUCKET", "clipboard"),
+		Region:    util.GetEnvOrDefault("S3_REGION", "us-east-1"),
+		UseSSL:    util.GetEnvOrDefault("S3_USE_SSL", "false") == "true",
+	}
+}
@@ -0,0 +1,135 @@
+package blobstore_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/blobstore"
+)
+
+func newLocalStore(t *testing.T) (repository.BlobStore, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "blobs")
+	store, err := blobstore.NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	return store, root
+}
+
+func put(t *testing.T, store repository.BlobStore, key, content string) {
+	t.Helper()
+	if err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put(%q): %v", key, err)
+	}
+}
+
+func read(t *testing.T, store repository.BlobStore, key string) string {
+	t.Helper()
+	f, err := store.Open(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", key, err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll(%q): %v", key, err)
+	}
+	return string(b)
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Put, Open and Delete round trip", func(t *testing.T) {
+		store, root := newLocalStore(t)
+		put(t, store, "attachments/abc", "hello")
+
+		if got := read(t, store, "attachments/abc"); got != "hello" {
+			t.Errorf("Open = %q, want %q", got, "hello")
+		}
+
+		// 書き込みに使った一時ファイルは残さない
+		entries, err := os.ReadDir(filepath.Join(root, "attachments"))
+		if err != nil {
+			t.Fatalf("ReadDir: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name() != "abc" {
+			t.Errorf("files after Put = %v, want only abc", entries)
+		}
+
+		if err := store.Delete(ctx, "attachments/abc"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		_, err = store.Open(ctx, "attachments/abc")
+		if err != model.ErrBlobNotFound {
+			t.Errorf("Open after Delete: err = %v, want %v", err, model.ErrBlobNotFound)
+		}
+	})
+
+	t.Run("Open seeks within the blob", func(t *testing.T) {
+		store, _ := newLocalStore(t)
+		put(t, store, "seek", "0123456789")
+
+		f, err := store.Open(ctx, "seek")
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer f.Close()
+		if _, err := f.Seek(5, io.SeekStart); err != nil {
+			t.Fatalf("Seek: %v", err)
+		}
+		b, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if string(b) != "56789" {
+			t.Errorf("read after Seek = %q, want %q", b, "56789")
+		}
+	})
+
+	t.Run("Put replaces an existing blob", func(t *testing.T) {
+		store, _ := newLocalStore(t)
+		put(t, store, "key", "old")
+		put(t, store, "key", "new")
+		if got := read(t, store, "key"); got != "new" {
+			t.Errorf("Open = %q, want %q", got, "new")
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		store, _ := newLocalStore(t)
+		_, err := store.Open(ctx, "missing")
+		if err != model.ErrBlobNotFound {
+			t.Errorf("Open: err = %v, want %v", err, model.ErrBlobNotFound)
+		}
+		if err := store.Delete(ctx, "missing"); err != model.ErrBlobNotFound {
+			t.Errorf("Delete: err = %v, want %v", err, model.ErrBlobNotFound)
+		}
+	})
+
+	t.Run("rejects keys outside the root", func(t *testing.T) {
+		store, root := newLocalStore(t)
+		for _, key := range []string{"", "../escape", "/absolute", "a//b", "a/", "a/../b", "a.b"} {
+			err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain")
+			if err == nil {
+				t.Errorf("Put(%q) succeeded, want an error", key)
+			}
+			if _, err := store.Open(ctx, key); err == nil || err == model.ErrBlobNotFound {
+				t.Errorf("Open(%q): err = %v, want an invalid key error", key, err)
+			}
+			if err := store.Delete(ctx, key); err == nil || err == model.ErrBlobNotFound {
+				t.Errorf("Delete(%q): err = %v, want an invalid key error", key, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape")); !os.IsNotExist(err) {
+			t.Errorf("a blob was written outside the root: %v", err)
+		}
+	})
+}
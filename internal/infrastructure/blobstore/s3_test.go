@@ -0,0 +1,118 @@
+package blobstore_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/blobstore"
+	"github.com/gofrs/uuid"
+)
+
+// newS3Store は TEST_S3_ENDPOINT (host:port) の S3 互換ストレージに接続する
+// 認証情報やリージョンは S3_ACCESS_KEY などアプリと同じ環境変数から読む
+// 他のテストとキーが衝突しないよう、テストごとのプレフィックスを返す
+func newS3Store(t *testing.T) (repository.BlobStore, string) {
+	t.Helper()
+	endpoint := os.Getenv("TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("TEST_S3_ENDPOINT is not set; the S3 store is not verified")
+	}
+
+	cfg := blobstore.S3()
+	cfg.Endpoint = endpoint
+	cfg.Bucket = "clipboard-test"
+	store, err := blobstore.NewS3Store(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	return store, "test/" + uuid.Must(uuid.NewV4()).String()
+}
+
+// TestS3Store は TestLocalStore と同じ振る舞いを S3 互換ストレージで確認する
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Put, Open and Delete round trip", func(t *testing.T) {
+		store, prefix := newS3Store(t)
+		key := prefix + "/abc"
+		put(t, store, key, "hello")
+
+		if got := read(t, store, key); got != "hello" {
+			t.Errorf("Open = %q, want %q", got, "hello")
+		}
+
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		_, err := store.Open(ctx, key)
+		if err != model.ErrBlobNotFound {
+			t.Errorf("Open after Delete: err = %v, want %v", err, model.ErrBlobNotFound)
+		}
+	})
+
+	t.Run("Open seeks within the blob", func(t *testing.T) {
+		store, prefix := newS3Store(t)
+		key := prefix + "/seek"
+		put(t, store, key, "0123456789")
+		t.Cleanup(func() { store.Delete(ctx, key) })
+
+		f, err := store.Open(ctx, key)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer f.Close()
+		if _, err := f.Seek(5, io.SeekStart); err != nil {
+			t.Fatalf("Seek: %v", err)
+		}
+		b, err := io.ReadAll(f)
+		if err != nil {
+			t.Fatalf("ReadAll: %v", err)
+		}
+		if string(b) != "56789" {
+			t.Errorf("read after Seek = %q, want %q", b, "56789")
+		}
+	})
+
+	t.Run("Put replaces an existing blob", func(t *testing.T) {
+		store, prefix := newS3Store(t)
+		key := prefix + "/key"
+		put(t, store, key, "old")
+		put(t, store, key, "new")
+		t.Cleanup(func() { store.Delete(ctx, key) })
+		if got := read(t, store, key); got != "new" {
+			t.Errorf("Open = %q, want %q", got, "new")
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		store, prefix := newS3Store(t)
+		_, err := store.Open(ctx, prefix+"/missing")
+		if err != model.ErrBlobNotFound {
+			t.Errorf("Open: err = %v, want %v", err, model.ErrBlobNotFound)
+		}
+		if err := store.Delete(ctx, prefix+"/missing"); err != model.ErrBlobNotFound {
+			t.Errorf("Delete: err = %v, want %v", err, model.ErrBlobNotFound)
+		}
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		store, _ := newS3Store(t)
+		for _, key := range []string{"", "../escape", "/absolute", "a//b", "a/", "a/../b", "a.b"} {
+			err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain")
+			if err == nil {
+				t.Errorf("Put(%q) succeeded, want an error", key)
+			}
+			if _, err := store.Open(ctx, key); err == nil || err == model.ErrBlobNotFound {
+				t.Errorf("Open(%q): err = %v, want an invalid key error", key, err)
+			}
+			if err := store.Delete(ctx, key); err == nil || err == model.ErrBlobNotFound {
+				t.Errorf("Delete(%q): err = %v, want an invalid key error", key, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,142 @@
+// Package dbtx は TxManager が始めたトランザクションをコンテキストで受け渡し、リポジトリの操作をその中で実行する
+package dbtx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+// executor は *sqlx.DB と *sqlx.Tx に共通する操作
+type executor interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// DB はリポジトリが *sqlx.DB の代わりに持つ。コンテキストにトランザクションがあれば、その中で実行する
+type DB struct {
+	db *sqlx.DB
+}
+
+func New(db *sqlx.DB) *DB {
+	return &DB{db: db}
+}
+
+func (d *DB) executor(ctx context.Context) executor {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return d.db
+}
+
+func (d *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return d.executor(ctx).GetContext(ctx, dest, query, args...)
+}
+
+func (d *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return d.executor(ctx).SelectContext(ctx, dest, query, args...)
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return d.executor(ctx).ExecContext(ctx, query, args...)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return d.executor(ctx).QueryContext(ctx, query, args...)
+}
+
+func (d *DB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return d.executor(ctx).QueryxContext(ctx, query, args...)
+}
+
+func (d *DB) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return d.executor(ctx).QueryRowxContext(ctx, query, args...)
+}
+
+func (d *DB) DriverName() string {
+	return d.db.DriverName()
+}
+
+func (d *DB) Rebind(query string) string {
+	return d.db.Rebind(query)
+}
+
+func (d *DB) BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	return d.db.BindNamed(query, arg)
+}
+
+// BeginTxx はトランザクションを始める。コンテキストに TxManager のトランザクションがあれば、それに参加する
+func (d *DB) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return &Tx{Tx: tx, joined: true}, nil
+	}
+	tx, err := d.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+// Tx はリポジトリの中で始めたトランザクション
+// TxManager のトランザクションに参加した場合、コミットとロールバックは TxManager に任せる
+type Tx struct {
+	*sqlx.Tx
+	joined bool
+}
+
+func (tx *Tx) Commit() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Commit()
+}
+
+func (tx *Tx) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Rollback()
+}
+
+type txManager struct {
+	db *sqlx.DB
+}
+
+func NewTxManager(db *sqlx.DB) repository.TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	// begin transaction
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type attachmentRepository struct {
+	db *DB
+}
+
+func NewAttachmentRepository(db *DB) repository.AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// loadAttachment は本体のストレージキーを含めた添付ファイルのコピーを返す
+func (db *DB) loadAttachment(row *model.Attachment) *model.Attachment {
+	attachment := *row
+	if blob, ok := db.blobs[row.SHA256]; ok && blob.StorageKey != nil {
+		attachment.StorageKey = *blob.StorageKey
+	}
+	return &attachment
+}
+
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.users[attachment.OwnerID]; !ok {
+		return nil, model.ErrUserNotFound
+	}
+
+	_, deduplicated := r.db.acquireStoredBlob(attachment.SHA256, attachment.Size, attachment.StorageKey)
+
+	row := &model.Attachment{
+		AttachmentID: attachment.AttachmentID,
+		OwnerID:      attachment.OwnerID,
+		FileName:     attachment.FileName,
+		MimeType:     attachment.MimeType,
+		Size:         attachment.Size,
+		SHA256:       attachment.SHA256,
+		CreatedAt:    now(),
+	}
+	r.db.attachments[attachment.AttachmentID] = row
+
+	created := r.db.loadAttachment(row)
+	created.Deduplicated = &deduplicated
+	return created, nil
+}
+
+func (r *attachmentRepository) GetAttachment(ctx context.Context, attachmentID uuid.UUID) (*model.Attachment, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	row, ok := r.db.attachments[attachmentID]
+	if !ok {
+		return nil, model.ErrAttachmentNotFound
+	}
+	return r.db.loadAttachment(row), nil
+}
+
+func (r *attachmentRepository) GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]*model.Attachment, error) {
+	attachments := make(map[uuid.UUID][]*model.Attachment, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	// messageAttachments は position の順に並んでいる
+	for _, messageID := range messageIDs {
+		for _, attachmentID := range r.db.messageAttachments[messageID] {
+			row, ok := r.db.attachments[attachmentID]
+			if !ok {
+				continue
+			}
+			attachment := r.db.loadAttachment(row)
+			attachment.MessageID = messageID
+			attachments[messageID] = append(attachments[messageID], attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) GetAttachmentChannels(ctx context.Context, attachmentID uuid.UUID) ([]uuid.UUID, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	seen := map[uuid.UUID]bool{}
+	var channelIDs []uuid.UUID
+	for messageID, ids := range r.db.messageAttachments {
+		message, ok := r.db.messages[messageID]
+		if !ok || message.DeletedAt != nil || seen[message.ChannelID] {
+			continue
+		}
+		for _, id := range ids {
+			if id == attachmentID {
+				seen[message.ChannelID] = true
+				channelIDs = append(channelIDs, message.ChannelID)
+				break
+			}
+		}
+	}
+	sort.Slice(channelIDs, func(i, j int) bool {
+		return channelIDs[i].String() < channelIDs[j].String()
+	})
+	return channelIDs, nil
+}
+
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, attachmentID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.attachments[attachmentID]; !ok {
+		return model.ErrAttachmentNotFound
+	}
+	r.db.deleteAttachments([]uuid.UUID{attachmentID})
+	return nil
+}
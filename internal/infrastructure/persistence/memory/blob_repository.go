@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+)
+
+type blobRepository struct {
+	db *DB
+}
+
+func NewBlobRepository(db *DB) repository.BlobRepository {
+	return &blobRepository{db: db}
+}
+
+func (r *blobRepository) DeleteUnreferencedBlobs(ctx context.Context, olderThan time.Time, limit int) ([]*model.Blob, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var blobs []*blobRow
+	for _, blob := range r.db.blobs {
+		if blob.RefCount <= 0 && blob.UpdatedAt.Before(olderThan) {
+			blobs = append(blobs, blob)
+		}
+	}
+	sort.Slice(blobs, func(i, j int) bool {
+		return blobs[i].UpdatedAt.Before(blobs[j].UpdatedAt)
+	})
+	if len(blobs) > limit {
+		blobs = blobs[:limit]
+	}
+
+	deleted := make([]*model.Blob, 0, len(blobs))
+	for _, blob := range blobs {
+		// 参照数がずれていてまだ参照が残っていれば、数え直して残す
+		if r.db.blobReferences(blob.SHA256) > 0 {
+			log.Printf("blob %s is still referenced; recounting references", blob.SHA256)
+			blob.RefCount = r.db.blobReferences(blob.SHA256)
+			blob.UpdatedAt = now()
+			continue
+		}
+		delete(r.db.blobs, blob.SHA256)
+		found := blob.Blob
+		deleted = append(deleted, &found)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,299 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type channelRepository struct {
+	db *DB
+}
+
+func NewChannelRepository(db *DB) repository.ChannelRepository {
+	return &channelRepository{db: db}
+}
+
+// channelMember はチャンネルのメンバーを返す。所属していなければ nil
+func (db *DB) channelMember(channelID uuid.UUID, userID uuid.UUID) *model.ChannelMember {
+	for _, member := range db.members {
+		if member.ChannelID == channelID && member.UserID == userID {
+			return member
+		}
+	}
+	return nil
+}
+
+func (r *channelRepository) CreateChannel(ctx context.Context, req *model.RequestCreateChannel) (*model.Channel, error) {
+	channelID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	// 他の実装の一意性に合わせ、ゴミ箱のチャンネルも含めて大文字小文字を区別せずに比べる
+	for _, channel := range r.db.channels {
+		if strings.EqualFold(channel.ChannelName, req.ChannelName) {
+			return nil, model.ErrAlreadyExistChannelName
+		}
+	}
+	if !req.OwnerID.IsNil() {
+		if _, ok := r.db.users[req.OwnerID]; !ok {
+			return nil, model.ErrUserNotFound
+		}
+	}
+
+	t := now()
+	channel := &model.Channel{
+		ChannelID:   channelID,
+		ChannelName: req.ChannelName,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		CreatedAt:   t,
+		UpdatedAt:   t,
+	}
+	r.db.channels[channelID] = channel
+
+	// 作成者をオーナーとして登録する
+	if !req.OwnerID.IsNil() {
+		r.db.members = append(r.db.members, &model.ChannelMember{
+			ChannelID: channelID,
+			UserID:    req.OwnerID,
+			Role:      model.ChannelRoleOwner,
+			CreatedAt: t,
+			UpdatedAt: t,
+		})
+	}
+
+	created := *channel
+	return &created, nil
+}
+
+func (r *channelRepository) GetChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	channel, ok := r.db.channels[channelID]
+	if !ok || channel.DeletedAt != nil {
+		return nil, model.ErrChannelNotFound
+	}
+	found := *channel
+	return &found, nil
+}
+
+func (r *channelRepository) GetDeletedChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	channel, ok := r.db.channels[channelID]
+	if !ok || channel.DeletedAt == nil {
+		return nil, model.ErrChannelNotFound
+	}
+	found := *channel
+	return &found, nil
+}
+
+func (r *channelRepository) GetChannels(ctx context.Context, viewerID uuid.UUID, includeDeleted bool) ([]*model.Channel, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var channels []*model.Channel
+	for _, channel := range r.db.channels {
+		member := r.db.channelMember(channel.ChannelID, viewerID)
+		visible := channel.DeletedAt == nil && (channel.Visibility == model.ChannelVisibilityPublic || member != nil)
+		if includeDeleted && channel.DeletedAt != nil && member != nil && member.Role.CanManage() {
+			visible = true
+		}
+		if visible {
+			found := *channel
+			channels = append(channels, &found)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if !channels[i].CreatedAt.Equal(channels[j].CreatedAt) {
+			return channels[i].CreatedAt.Before(channels[j].CreatedAt)
+		}
+		return channels[i].ChannelID.String() < channels[j].ChannelID.String()
+	})
+	return channels, nil
+}
+
+func (r *channelRepository) PatchChannel(ctx context.Context, channelID uuid.UUID, req *model.RequestPatchChannel) (*model.Channel, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	channel, ok := r.db.channels[channelID]
+	if !ok || channel.DeletedAt != nil {
+		return nil, model.ErrChannelNotFound
+	}
+
+	if req.DisplayName == nil && req.Description == nil && req.Visibility == nil {
+		found := *channel
+		return &found, nil
+	}
+
+	// チャンネル名は他の実装と同じく変更しない
+	if req.DisplayName != nil {
+		channel.DisplayName = *req.DisplayName
+	}
+	if req.Description != nil {
+		channel.Description = *req.Description
+	}
+	if req.Visibility != nil {
+		channel.Visibility = *req.Visibility
+	}
+	channel.UpdatedAt = now()
+
+	patched := *channel
+	return &patched, nil
+}
+
+func (r *channelRepository) DeleteChannel(ctx context.Context, channelID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	channel, ok := r.db.channels[channelID]
+	if !ok || channel.DeletedAt != nil {
+		return model.ErrChannelNotFound
+	}
+	// updated_at は設定の変更日時として使うため、削除では変えない
+	deletedAt := now()
+	channel.DeletedAt = &deletedAt
+	return nil
+}
+
+func (r *channelRepository) RestoreChannel(ctx context.Context, channelID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	channel, ok := r.db.channels[channelID]
+	if !ok || channel.DeletedAt == nil {
+		return model.ErrChannelNotFound
+	}
+	channel.DeletedAt = nil
+	return nil
+}
+
+func (r *channelRepository) PurgeDeletedChannels(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var channels []*model.Channel
+	for _, channel := range r.db.channels {
+		if channel.DeletedAt != nil && channel.DeletedAt.Before(deletedBefore) {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].DeletedAt.Before(*channels[j].DeletedAt)
+	})
+	if len(channels) > limit {
+		channels = channels[:limit]
+	}
+
+	for _, channel := range channels {
+		// メッセージはチャンネルと一緒に削除する
+		var messageIDs []uuid.UUID
+		for id, message := range r.db.messages {
+			if message.ChannelID == channel.ChannelID {
+				messageIDs = append(messageIDs, id)
+			}
+		}
+		r.db.deleteMessages(messageIDs)
+
+		members := r.db.members[:0]
+		for _, member := range r.db.members {
+			if member.ChannelID != channel.ChannelID {
+				members = append(members, member)
+			}
+		}
+		r.db.members = members
+		readStates := r.db.readStates[:0]
+		for _, state := range r.db.readStates {
+			if state.ChannelID != channel.ChannelID {
+				readStates = append(readStates, state)
+			}
+		}
+		r.db.readStates = readStates
+		delete(r.db.channels, channel.ChannelID)
+	}
+	return len(channels), nil
+}
+
+func (r *channelRepository) AddChannelMember(ctx context.Context, member *model.ChannelMember) (*model.ChannelMember, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if r.db.channelMember(member.ChannelID, member.UserID) != nil {
+		return nil, model.ErrAlreadyChannelMember
+	}
+	// 他の実装の外部キー制約違反と同じく、チャンネルかユーザーが存在しなければ ErrUserNotFound を返す
+	if _, ok := r.db.channels[member.ChannelID]; !ok {
+		return nil, model.ErrUserNotFound
+	}
+	if _, ok := r.db.users[member.UserID]; !ok {
+		return nil, model.ErrUserNotFound
+	}
+
+	t := now()
+	added := &model.ChannelMember{
+		ChannelID: member.ChannelID,
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+	r.db.members = append(r.db.members, added)
+
+	found := *added
+	return &found, nil
+}
+
+func (r *channelRepository) GetChannelMember(ctx context.Context, channelID uuid.UUID, userID uuid.UUID) (*model.ChannelMember, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	member := r.db.channelMember(channelID, userID)
+	if member == nil {
+		return nil, model.ErrChannelMemberNotFound
+	}
+	found := *member
+	return &found, nil
+}
+
+func (r *channelRepository) GetChannelMembers(ctx context.Context, channelID uuid.UUID) ([]*model.ChannelMember, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	// members は追加した順に並んでいる
+	var members []*model.ChannelMember
+	for _, member := range r.db.members {
+		if member.ChannelID == channelID {
+			found := *member
+			members = append(members, &found)
+		}
+	}
+	return members, nil
+}
+
+func (r *channelRepository) RemoveChannelMember(ctx context.Context, channelID uuid.UUID, userID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	for i, member := range r.db.members {
+		if member.ChannelID == channelID && member.UserID == userID {
+			r.db.members = append(r.db.members[:i], r.db.members[i+1:]...)
+			return nil
+		}
+	}
+	return model.ErrChannelMemberNotFound
+}
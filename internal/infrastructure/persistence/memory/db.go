@@ -0,0 +1,291 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+// DB はメモリ上に全てのテーブルを持つ。データベースなしでテストするためのもの
+// リポジトリは同じ DB を共有し、1つの操作の間は mu で排他する
+type DB struct {
+	mu sync.Mutex
+	tables
+}
+
+// tables は全てのテーブル。TxManager はトランザクションを始める前に複製し、ロールバックするときに書き戻す
+type tables struct {
+	users       map[uuid.UUID]*userRow
+	sessions    map[string]*model.Session
+	channels    map[uuid.UUID]*model.Channel
+	members     []*model.ChannelMember
+	blobs       map[string]*blobRow
+	messages    map[uuid.UUID]*model.Message
+	revisions   map[uuid.UUID][]*model.MessageRevision
+	pins        map[uuid.UUID]time.Time
+	reactions   []*model.Reaction
+	attachments map[uuid.UUID]*model.Attachment
+	// messageAttachments はメッセージごとの添付ファイルを position の順に持つ
+	messageAttachments map[uuid.UUID][]uuid.UUID
+	userKeys           map[uuid.UUID]*model.UserKey
+	readStates         []*model.ChannelReadState
+	mentions           []*model.MessageMention
+}
+
+func NewDB() *DB {
+	return &DB{
+		tables: tables{
+			users:              map[uuid.UUID]*userRow{},
+			sessions:           map[string]*model.Session{},
+			channels:           map[uuid.UUID]*model.Channel{},
+			blobs:              map[string]*blobRow{},
+			messages:           map[uuid.UUID]*model.Message{},
+			revisions:          map[uuid.UUID][]*model.MessageRevision{},
+			pins:               map[uuid.UUID]time.Time{},
+			attachments:        map[uuid.UUID]*model.Attachment{},
+			messageAttachments: map[uuid.UUID][]uuid.UUID{},
+			userKeys:           map[uuid.UUID]*model.UserKey{},
+		},
+	}
+}
+
+// lock は操作の間 DB を排他する。TxManager のトランザクションの中では Do が排他しているため何もしない
+func (db *DB) lock(ctx context.Context) {
+	if !db.inTx(ctx) {
+		db.mu.Lock()
+	}
+}
+
+func (db *DB) unlock(ctx context.Context) {
+	if !db.inTx(ctx) {
+		db.mu.Unlock()
+	}
+}
+
+// cloneRows はマップの各行を複製する。リポジトリは行を書き換えるため、ポインタだけの複製では戻せない
+func cloneRows[K comparable, V any](rows map[K]*V) map[K]*V {
+	cloned := make(map[K]*V, len(rows))
+	for k, row := range rows {
+		copied := *row
+		cloned[k] = &copied
+	}
+	return cloned
+}
+
+func cloneSlice[V any](rows []*V) []*V {
+	cloned := make([]*V, len(rows))
+	for i, row := range rows {
+		copied := *row
+		cloned[i] = &copied
+	}
+	return cloned
+}
+
+func (t *tables) clone() tables {
+	revisions := make(map[uuid.UUID][]*model.MessageRevision, len(t.revisions))
+	for id, rows := range t.revisions {
+		revisions[id] = cloneSlice(rows)
+	}
+	pins := make(map[uuid.UUID]time.Time, len(t.pins))
+	for id, pinnedAt := range t.pins {
+		pins[id] = pinnedAt
+	}
+	messageAttachments := make(map[uuid.UUID][]uuid.UUID, len(t.messageAttachments))
+	for id, ids := range t.messageAttachments {
+		messageAttachments[id] = append([]uuid.UUID(nil), ids...)
+	}
+
+	return tables{
+		users:              cloneRows(t.users),
+		sessions:           cloneRows(t.sessions),
+		channels:           cloneRows(t.channels),
+		members:            cloneSlice(t.members),
+		blobs:              cloneRows(t.blobs),
+		messages:           cloneRows(t.messages),
+		revisions:          revisions,
+		pins:               pins,
+		reactions:          cloneSlice(t.reactions),
+		attachments:        cloneRows(t.attachments),
+		messageAttachments: messageAttachments,
+		userKeys:           cloneRows(t.userKeys),
+		readStates:         cloneSlice(t.readStates),
+		mentions:           cloneSlice(t.mentions),
+	}
+}
+
+// userRow は u_user と u_user_private を合わせた行
+type userRow struct {
+	model.User
+	Email        *string
+	PasswordHash string
+}
+
+// blobRow は u_blob の行。Content はメッセージ本文の場合のみ持つ
+type blobRow struct {
+	model.Blob
+	Content *string
+}
+
+// now はデータベースの現在時刻の代わり。JSON にしたときに他の実装と揃うよう UTC にする
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// acquireContentBlob はメッセージ本文を blobs に登録して参照数を1増やす
+// 同じ本文が既にあれば新たに保存せず、deduplicated として true を返す
+func (db *DB) acquireContentBlob(content string) (string, bool) {
+	hash := contentHash(content)
+	if blob, ok := db.blobs[hash]; ok {
+		blob.RefCount++
+		if blob.Content == nil {
+			blob.Content = &content
+		}
+		blob.UpdatedAt = now()
+		return hash, true
+	}
+
+	t := now()
+	db.blobs[hash] = &blobRow{
+		Blob:    model.Blob{SHA256: hash, Size: int64(len(content)), RefCount: 1, CreatedAt: t, UpdatedAt: t},
+		Content: &content,
+	}
+	return hash, false
+}
+
+// acquireStoredBlob はファイル本体を blobs に登録して参照数を1増やし、実際に使うストレージキーを返す
+// 既に同じハッシュの本体があればそのキーを返し、deduplicated として true を返す
+func (db *DB) acquireStoredBlob(hash string, size int64, storageKey string) (string, bool) {
+	if blob, ok := db.blobs[hash]; ok {
+		blob.RefCount++
+		if blob.StorageKey == nil {
+			blob.StorageKey = &storageKey
+		}
+		blob.UpdatedAt = now()
+		return *blob.StorageKey, *blob.StorageKey != storageKey
+	}
+
+	t := now()
+	db.blobs[hash] = &blobRow{
+		Blob: model.Blob{SHA256: hash, Size: size, StorageKey: &storageKey, RefCount: 1, CreatedAt: t, UpdatedAt: t},
+	}
+	return storageKey, false
+}
+
+// releaseBlob は blob の参照数を1減らす。参照数が 0 になっても、削除はスイーパーに任せる
+func (db *DB) releaseBlob(hash string) {
+	if blob, ok := db.blobs[hash]; ok {
+		blob.RefCount--
+		blob.UpdatedAt = now()
+	}
+}
+
+// blobReferences は blob を実際に参照している行の数を数える
+func (db *DB) blobReferences(hash string) int {
+	n := 0
+	for _, message := range db.messages {
+		if message.ContentSHA256 == hash {
+			n++
+		}
+	}
+	for _, revisions := range db.revisions {
+		for _, revision := range revisions {
+			if revision.ContentSHA256 == hash {
+				n++
+			}
+		}
+	}
+	for _, attachment := range db.attachments {
+		if attachment.SHA256 == hash {
+			n++
+		}
+	}
+	return n
+}
+
+// deleteMessages はメッセージとその返信を削除し、本文の参照を外す
+// 外部キーの ON DELETE CASCADE の代わりに、リアクションやピン留めなども合わせて削除する
+// 他のメッセージに添付されていない添付ファイルも削除し、本体の参照を外す
+func (db *DB) deleteMessages(messageIDs []uuid.UUID) {
+	targets := map[uuid.UUID]bool{}
+	for _, id := range messageIDs {
+		if _, ok := db.messages[id]; ok {
+			targets[id] = true
+		}
+	}
+	for id, message := range db.messages {
+		if message.ParentMessageID.Valid && targets[message.ParentMessageID.UUID] {
+			targets[id] = true
+		}
+	}
+
+	attachmentIDs := map[uuid.UUID]bool{}
+	for id := range targets {
+		for _, attachmentID := range db.messageAttachments[id] {
+			attachmentIDs[attachmentID] = true
+		}
+		db.releaseBlob(db.messages[id].ContentSHA256)
+		for _, revision := range db.revisions[id] {
+			db.releaseBlob(revision.ContentSHA256)
+		}
+		delete(db.messages, id)
+		delete(db.revisions, id)
+		delete(db.pins, id)
+		delete(db.messageAttachments, id)
+	}
+
+	reactions := db.reactions[:0]
+	for _, reaction := range db.reactions {
+		if !targets[reaction.MessageID] {
+			reactions = append(reactions, reaction)
+		}
+	}
+	db.reactions = reactions
+	db.deleteMentions(func(mention *model.MessageMention) bool {
+		return targets[mention.MessageID]
+	})
+
+	for _, ids := range db.messageAttachments {
+		for _, id := range ids {
+			delete(attachmentIDs, id)
+		}
+	}
+	unlinked := make([]uuid.UUID, 0, len(attachmentIDs))
+	for id := range attachmentIDs {
+		unlinked = append(unlinked, id)
+	}
+	db.deleteAttachments(unlinked)
+}
+
+// deleteAttachments は添付ファイルを削除し、本体の参照を外す。メッセージからの参照も外す
+func (db *DB) deleteAttachments(attachmentIDs []uuid.UUID) {
+	targets := map[uuid.UUID]bool{}
+	for _, id := range attachmentIDs {
+		attachment, ok := db.attachments[id]
+		if !ok {
+			continue
+		}
+		targets[id] = true
+		db.releaseBlob(attachment.SHA256)
+		delete(db.attachments, id)
+	}
+
+	for messageID, ids := range db.messageAttachments {
+		kept := ids[:0]
+		for _, id := range ids {
+			if !targets[id] {
+				kept = append(kept, id)
+			}
+		}
+		db.messageAttachments[messageID] = kept
+	}
+}
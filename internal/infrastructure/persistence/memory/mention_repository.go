@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type mentionRepository struct {
+	db *DB
+}
+
+func NewMentionRepository(db *DB) repository.MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// mention は messageID で userID に宛てたメンションを返す。なければ nil
+func (db *DB) mention(messageID uuid.UUID, userID uuid.UUID) *model.MessageMention {
+	for _, mention := range db.mentions {
+		if mention.MessageID == messageID && mention.UserID == userID {
+			return mention
+		}
+	}
+	return nil
+}
+
+// deleteMentions は条件に合うメンションを削除する
+func (db *DB) deleteMentions(match func(mention *model.MessageMention) bool) {
+	mentions := db.mentions[:0]
+	for _, mention := range db.mentions {
+		if !match(mention) {
+			mentions = append(mentions, mention)
+		}
+	}
+	db.mentions = mentions
+}
+
+func (r *mentionRepository) SaveMentions(ctx context.Context, messageID uuid.UUID, targets *model.MentionTargets) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	r.db.deleteMentions(func(mention *model.MessageMention) bool {
+		return mention.MessageID == messageID
+	})
+	message, ok := r.db.messages[messageID]
+	if !ok {
+		return nil
+	}
+	channel := r.db.channels[message.ChannelID]
+	add := func(userID uuid.UUID, kind string) {
+		if userID == message.UserID || r.db.mention(messageID, userID) != nil {
+			return
+		}
+		r.db.mentions = append(r.db.mentions, &model.MessageMention{
+			MessageID: messageID,
+			UserID:    userID,
+			ChannelID: message.ChannelID,
+			Kind:      kind,
+			CreatedAt: message.CreatedAt,
+		})
+	}
+
+	// 名前で宛てたユーザーを先に登録し、@channel と重なっても MentionKindUser にする
+	for _, userName := range targets.UserNames {
+		for _, user := range r.db.users {
+			if !strings.EqualFold(user.UserName, userName) {
+				continue
+			}
+			if channel.Visibility == model.ChannelVisibilityPublic || r.db.channelMember(channel.ChannelID, user.UserID) != nil {
+				add(user.UserID, model.MentionKindUser)
+			}
+		}
+	}
+	if targets.Channel {
+		for _, member := range r.db.members {
+			if member.ChannelID == channel.ChannelID {
+				add(member.UserID, model.MentionKindChannel)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *mentionRepository) GetMentions(ctx context.Context, userID uuid.UUID, query *model.MentionQuery) (*model.MentionPage, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	type found struct {
+		mention *model.MessageMention
+		read    bool
+	}
+	t := now()
+	var candidates []found
+	for _, mention := range r.db.mentions {
+		if mention.UserID != userID {
+			continue
+		}
+		message := r.db.messages[mention.MessageID]
+		channel := r.db.channels[mention.ChannelID]
+		member := r.db.channelMember(mention.ChannelID, userID)
+		if !visible(message, t) || channel.DeletedAt != nil || (channel.Visibility != model.ChannelVisibilityPublic && member == nil) {
+			continue
+		}
+		cursor := model.CursorOf(message)
+		if query.Before != nil && !newer(query.Before, cursor) {
+			continue
+		}
+
+		// 既読かどうかは未読数と同じく既読の位置で決め、位置がなければ参加した日時を使う
+		var position *model.MessageCursor
+		if state := r.db.readState(userID, mention.ChannelID); state != nil {
+			position = &model.MessageCursor{CreatedAt: state.LastReadAt, MessageID: state.LastReadMessageID}
+		} else if member != nil {
+			position = &model.MessageCursor{CreatedAt: member.CreatedAt}
+		}
+		read := position != nil && !newer(cursor, position)
+		if query.UnreadOnly && read {
+			continue
+		}
+		candidates = append(candidates, found{mention: mention, read: read})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return newer(model.CursorOf(r.db.messages[candidates[i].mention.MessageID]), model.CursorOf(r.db.messages[candidates[j].mention.MessageID]))
+	})
+
+	page := &model.MentionPage{Mentions: []*model.Mention{}}
+	if len(candidates) > query.Limit {
+		candidates = candidates[:query.Limit]
+		page.NextCursor = model.CursorOf(r.db.messages[candidates[len(candidates)-1].mention.MessageID])
+	}
+	for _, candidate := range candidates {
+		page.Mentions = append(page.Mentions, &model.Mention{
+			Message: r.db.loadMessage(r.db.messages[candidate.mention.MessageID]),
+			Kind:    candidate.mention.Kind,
+			Read:    candidate.read,
+		})
+	}
+	return page, nil
+}
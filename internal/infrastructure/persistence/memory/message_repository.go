@@ -0,0 +1,500 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type messageRepository struct {
+	db *DB
+}
+
+func NewMessageRepository(db *DB) repository.MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// expired は期限切れのメッセージか。削除されるまでの間も読めないようにする
+func expired(message *model.Message, t time.Time) bool {
+	return message.ExpiresAt != nil && !message.ExpiresAt.After(t)
+}
+
+// visible はゴミ箱に入っておらず、期限切れでもないメッセージか
+func visible(message *model.Message, t time.Time) bool {
+	return message.DeletedAt == nil && !expired(message, t)
+}
+
+// newer は (created_at, message_id) の順で a が b より新しいか
+func newer(a, b *model.MessageCursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.MessageID.String() > b.MessageID.String()
+}
+
+// sortNewestFirst はメッセージを新しい順に並べる
+func sortNewestFirst(messages []*model.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		return newer(model.CursorOf(messages[i]), model.CursorOf(messages[j]))
+	})
+}
+
+// loadMessage は本文とスレッドの返信数・最終返信日時、編集の有無を含めたメッセージのコピーを返す
+func (db *DB) loadMessage(row *model.Message) *model.Message {
+	t := now()
+	message := *row
+	if blob, ok := db.blobs[row.ContentSHA256]; ok && blob.Content != nil {
+		message.Content = *blob.Content
+	}
+
+	message.ReplyCount = 0
+	message.LastReplyAt = nil
+	for _, reply := range db.messages {
+		if !reply.ParentMessageID.Valid || reply.ParentMessageID.UUID != row.MessageID || !visible(reply, t) {
+			continue
+		}
+		message.ReplyCount++
+		if message.LastReplyAt == nil || reply.CreatedAt.After(*message.LastReplyAt) {
+			lastReplyAt := reply.CreatedAt
+			message.LastReplyAt = &lastReplyAt
+		}
+	}
+
+	message.RevisionCount = len(db.revisions[row.MessageID])
+	message.Edited = message.RevisionCount > 0
+	return &message
+}
+
+// loadMessages は条件に合うメッセージを新しい順に返す
+func (db *DB) loadMessages(match func(message *model.Message) bool) []*model.Message {
+	var rows []*model.Message
+	for _, row := range db.messages {
+		if match(row) {
+			rows = append(rows, row)
+		}
+	}
+	sortNewestFirst(rows)
+
+	var messages []*model.Message
+	for _, row := range rows {
+		messages = append(messages, db.loadMessage(row))
+	}
+	return messages
+}
+
+func (r *messageRepository) CreateMessage(ctx context.Context, req *model.RequestCreateMessage) (*model.Message, error) {
+	messageID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	// 他の実装の外部キー制約の代わりに、参照先があることを確かめる
+	if _, ok := r.db.channels[req.ChannelID]; !ok {
+		return nil, model.ErrChannelNotFound
+	}
+	if _, ok := r.db.users[req.UserID]; !ok {
+		return nil, model.ErrUserNotFound
+	}
+	if req.ParentMessageID.Valid {
+		if _, ok := r.db.messages[req.ParentMessageID.UUID]; !ok {
+			return nil, model.ErrInvalidParentMessage
+		}
+	}
+	for _, attachmentID := range req.AttachmentIDs {
+		if _, ok := r.db.attachments[attachmentID]; !ok {
+			return nil, model.ErrAttachmentNotFound
+		}
+	}
+
+	contentSHA256, deduplicated := r.db.acquireContentBlob(req.Content)
+
+	t := now()
+	message := &model.Message{
+		MessageID:       messageID,
+		ChannelID:       req.ChannelID,
+		UserID:          req.UserID,
+		ParentMessageID: req.ParentMessageID,
+		ContentSHA256:   contentSHA256,
+		ContentType:     req.ContentType,
+		BurnAfterRead:   req.BurnAfterRead,
+		Encrypted:       req.Encrypted,
+		Ciphertext:      req.Ciphertext,
+		Nonce:           req.Nonce,
+		CreatedAt:       t,
+		UpdatedAt:       t,
+	}
+	if req.Language != "" {
+		language := req.Language
+		message.Language = &language
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := req.ExpiresAt.UTC()
+		message.ExpiresAt = &expiresAt
+	}
+	if req.KeyID != "" {
+		keyID := req.KeyID
+		message.KeyID = &keyID
+	}
+	r.db.messages[messageID] = message
+	if len(req.AttachmentIDs) > 0 {
+		r.db.messageAttachments[messageID] = append([]uuid.UUID(nil), req.AttachmentIDs...)
+	}
+
+	created := r.db.loadMessage(message)
+	created.Deduplicated = &deduplicated
+	return created, nil
+}
+
+func (r *messageRepository) GetMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	row, ok := r.db.messages[messageID]
+	if !ok || !visible(row, now()) {
+		return nil, model.ErrMessageNotFound
+	}
+	return r.db.loadMessage(row), nil
+}
+
+func (r *messageRepository) GetDeletedMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	row, ok := r.db.messages[messageID]
+	if !ok || row.DeletedAt == nil || expired(row, now()) {
+		return nil, model.ErrMessageNotFound
+	}
+	return r.db.loadMessage(row), nil
+}
+
+func (r *messageRepository) GetMessages(ctx context.Context, channelID uuid.UUID, query *model.MessageQuery) (*model.MessagePage, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	t := now()
+	messages := r.db.loadMessages(func(message *model.Message) bool {
+		switch {
+		case message.ChannelID != channelID, expired(message, t):
+			return false
+		case !query.IncludeDeleted && message.DeletedAt != nil:
+			return false
+		case query.ExcludeReplies && message.ParentMessageID.Valid:
+			return false
+		case query.ContentType != "" && message.ContentType != query.ContentType:
+			return false
+		case query.Before != nil && !newer(query.Before, model.CursorOf(message)):
+			return false
+		case query.After != nil && !newer(model.CursorOf(message), query.After):
+			return false
+		}
+		return true
+	})
+
+	var hasMore bool
+	switch {
+	case query.After != nil:
+		// After の直後から Limit 件を取る
+		if len(messages) > query.Limit {
+			messages = messages[len(messages)-query.Limit:]
+		}
+	case query.Before != nil:
+		hasMore = len(messages) > query.Limit
+		if hasMore {
+			messages = messages[:query.Limit]
+		}
+	default:
+		if query.Offset >= len(messages) {
+			messages = nil
+		} else {
+			messages = messages[query.Offset:]
+		}
+		hasMore = len(messages) > query.Limit
+		if hasMore {
+			messages = messages[:query.Limit]
+		}
+	}
+
+	page := &model.MessagePage{Messages: messages}
+	if query.After != nil {
+		// After より古いメッセージ（少なくとも After 自身）は必ず存在する
+		if len(messages) > 0 {
+			page.NextCursor = model.CursorOf(messages[len(messages)-1])
+			page.PrevCursor = model.CursorOf(messages[0])
+		} else {
+			page.PrevCursor = query.After
+		}
+		return page, nil
+	}
+
+	if len(messages) > 0 {
+		// 新着は随時届くため、より新しい方向のカーソルは常に返す
+		page.PrevCursor = model.CursorOf(messages[0])
+		if hasMore {
+			page.NextCursor = model.CursorOf(messages[len(messages)-1])
+		}
+	} else if query.Before != nil {
+		page.PrevCursor = query.Before
+	}
+	return page, nil
+}
+
+func (r *messageRepository) GetMessagesInDuration(ctx context.Context, channelID uuid.UUID, start, end time.Time) ([]*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	t := now()
+	return r.db.loadMessages(func(message *model.Message) bool {
+		return message.ChannelID == channelID && visible(message, t) &&
+			!message.CreatedAt.Before(start) && !message.CreatedAt.After(end)
+	}), nil
+}
+
+func (r *messageRepository) GetPinnedMessages(ctx context.Context, channelID uuid.UUID) ([]*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	t := now()
+	messages := r.db.loadMessages(func(message *model.Message) bool {
+		_, pinned := r.db.pins[message.MessageID]
+		return pinned && message.ChannelID == channelID && visible(message, t)
+	})
+	// ピン留めした日時の新しい順に並べる
+	sort.SliceStable(messages, func(i, j int) bool {
+		return r.db.pins[messages[i].MessageID].After(r.db.pins[messages[j].MessageID])
+	})
+	return messages, nil
+}
+
+func (r *messageRepository) GetReplies(ctx context.Context, messageID uuid.UUID) ([]*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	t := now()
+	messages := r.db.loadMessages(func(message *model.Message) bool {
+		return message.ParentMessageID.Valid && message.ParentMessageID.UUID == messageID && visible(message, t)
+	})
+	// 返信は古い順に並べる
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+func (r *messageRepository) PatchMessage(ctx context.Context, messageID uuid.UUID, req *model.RequestPatchMessage) (*model.Message, error) {
+	if req.IsEmpty() {
+		return nil, model.ErrNoFieldsToUpdate
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	message, ok := r.db.messages[messageID]
+	if !ok || !visible(message, now()) {
+		return nil, model.ErrMessageNotFound
+	}
+
+	// 更新前の版を記録する。created_at にはその版が書かれた日時を入れる
+	// 古い本文の参照は記録した版に引き継ぐ
+	revisions := r.db.revisions[messageID]
+	r.db.revisions[messageID] = append(revisions, &model.MessageRevision{
+		MessageID:     messageID,
+		Revision:      len(revisions) + 1,
+		ContentSHA256: message.ContentSHA256,
+		ContentType:   message.ContentType,
+		Language:      message.Language,
+		Encrypted:     message.Encrypted,
+		Ciphertext:    message.Ciphertext,
+		Nonce:         message.Nonce,
+		KeyID:         message.KeyID,
+		CreatedAt:     message.UpdatedAt,
+	})
+
+	if req.Content == nil {
+		// 本文が変わらなければ、記録した版とメッセージの両方が同じ本文を参照する
+		if blob, ok := r.db.blobs[message.ContentSHA256]; ok {
+			blob.RefCount++
+			blob.UpdatedAt = now()
+		}
+	} else {
+		message.ContentSHA256, _ = r.db.acquireContentBlob(*req.Content)
+	}
+	if req.ContentType != nil {
+		message.ContentType = *req.ContentType
+	}
+	if req.Language != nil {
+		if *req.Language == "" {
+			message.Language = nil
+		} else {
+			language := *req.Language
+			message.Language = &language
+		}
+	}
+	if req.Ciphertext != nil {
+		message.Ciphertext = req.Ciphertext
+		message.Nonce = req.Nonce
+		message.KeyID = req.KeyID
+	}
+	message.UpdatedAt = now()
+
+	return r.db.loadMessage(message), nil
+}
+
+func (r *messageRepository) GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.MessageRevision, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var revisions []*model.MessageRevision
+	for _, row := range r.db.revisions[messageID] {
+		revision := *row
+		if blob, ok := r.db.blobs[row.ContentSHA256]; ok && blob.Content != nil {
+			revision.Content = *blob.Content
+		}
+		revisions = append(revisions, &revision)
+	}
+	return revisions, nil
+}
+
+func (r *messageRepository) PinnMessage(ctx context.Context, messageID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.messages[messageID]; !ok {
+		return model.ErrMessageNotFound
+	}
+	if _, ok := r.db.pins[messageID]; ok {
+		return model.ErrMessageAlreadyPinned
+	}
+	r.db.pins[messageID] = now()
+	return nil
+}
+
+func (r *messageRepository) UnpinnMessage(ctx context.Context, messageID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.pins[messageID]; !ok {
+		return model.ErrMessageNotPinned
+	}
+	delete(r.db.pins, messageID)
+	return nil
+}
+
+// DeleteMessage はメッセージをゴミ箱に入れる。スレッドの親であれば返信もまとめてゴミ箱に入れる
+// 親と返信には同じ日時を記録し、復元するときにまとめて戻せるようにする
+func (r *messageRepository) DeleteMessage(ctx context.Context, messageID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	message, ok := r.db.messages[messageID]
+	if !ok || message.DeletedAt != nil {
+		return model.ErrMessageNotFound
+	}
+
+	// updated_at は編集日時として使うため、削除では変えない
+	deletedAt := now()
+	message.DeletedAt = &deletedAt
+	for _, reply := range r.db.messages {
+		if reply.ParentMessageID.Valid && reply.ParentMessageID.UUID == messageID && reply.DeletedAt == nil {
+			reply.DeletedAt = &deletedAt
+		}
+	}
+	return nil
+}
+
+// RestoreMessage はゴミ箱に入ったメッセージと、一緒にゴミ箱に入った返信を元に戻す
+// 返信を先に個別に削除していた場合、その返信は戻さない
+func (r *messageRepository) RestoreMessage(ctx context.Context, messageID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	message, ok := r.db.messages[messageID]
+	if !ok || message.DeletedAt == nil {
+		return model.ErrMessageNotFound
+	}
+
+	deletedAt := *message.DeletedAt
+	message.DeletedAt = nil
+	for _, reply := range r.db.messages {
+		if reply.ParentMessageID.Valid && reply.ParentMessageID.UUID == messageID &&
+			reply.DeletedAt != nil && reply.DeletedAt.Equal(deletedAt) {
+			reply.DeletedAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *messageRepository) PurgeDeletedMessages(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var messages []*model.Message
+	for _, message := range r.db.messages {
+		if message.DeletedAt != nil && message.DeletedAt.Before(deletedBefore) {
+			messages = append(messages, message)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].DeletedAt.Before(*messages[j].DeletedAt)
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.MessageID)
+	}
+	r.db.deleteMessages(ids)
+	return len(ids), nil
+}
+
+func (r *messageRepository) BurnMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	// 取得と削除の間は mu で排他するため、同時に読まれても返すのは1回だけ
+	row, ok := r.db.messages[messageID]
+	if !ok || !visible(row, now()) {
+		return nil, model.ErrMessageNotFound
+	}
+	message := r.db.loadMessage(row)
+	r.db.deleteMessages([]uuid.UUID{messageID})
+	return message, nil
+}
+
+func (r *messageRepository) DeleteExpiredMessages(ctx context.Context, limit int) ([]*model.Message, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	t := now()
+	var rows []*model.Message
+	for _, message := range r.db.messages {
+		if expired(message, t) {
+			rows = append(rows, message)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ExpiresAt.Before(*rows[j].ExpiresAt)
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	// 他の実装と同じく、ID とチャンネルのみ返す
+	messages := make([]*model.Message, 0, len(rows))
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, &model.Message{MessageID: row.MessageID, ChannelID: row.ChannelID})
+		ids = append(ids, row.MessageID)
+	}
+	r.db.deleteMessages(ids)
+	return messages, nil
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type reactionRepository struct {
+	db *DB
+}
+
+func NewReactionRepository(db *DB) repository.ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+func (r *reactionRepository) AddReaction(ctx context.Context, reaction *model.Reaction) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	for _, other := range r.db.reactions {
+		if other.MessageID == reaction.MessageID && other.UserID == reaction.UserID && other.Emoji == reaction.Emoji {
+			return model.ErrAlreadyReacted
+		}
+	}
+	if _, ok := r.db.messages[reaction.MessageID]; !ok {
+		return model.ErrMessageNotFound
+	}
+	if _, ok := r.db.users[reaction.UserID]; !ok {
+		return model.ErrUserNotFound
+	}
+
+	r.db.reactions = append(r.db.reactions, &model.Reaction{
+		MessageID: reaction.MessageID,
+		UserID:    reaction.UserID,
+		Emoji:     reaction.Emoji,
+		CreatedAt: now(),
+	})
+	return nil
+}
+
+func (r *reactionRepository) RemoveReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	for i, reaction := range r.db.reactions {
+		if reaction.MessageID == messageID && reaction.UserID == userID && reaction.Emoji == emoji {
+			r.db.reactions = append(r.db.reactions[:i], r.db.reactions[i+1:]...)
+			return nil
+		}
+	}
+	return model.ErrReactionNotFound
+}
+
+func (r *reactionRepository) GetReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]*model.ReactionSummary, error) {
+	summaries := make(map[uuid.UUID][]*model.ReactionSummary, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	type key struct {
+		messageID uuid.UUID
+		emoji     string
+	}
+	targets := map[uuid.UUID]bool{}
+	for _, id := range messageIDs {
+		targets[id] = true
+	}
+	rows := map[key]*model.ReactionSummary{}
+	firstReactedAt := map[key]time.Time{}
+	for _, reaction := range r.db.reactions {
+		if !targets[reaction.MessageID] {
+			continue
+		}
+		k := key{reaction.MessageID, reaction.Emoji}
+		row, ok := rows[k]
+		if !ok {
+			row = &model.ReactionSummary{MessageID: reaction.MessageID, Emoji: reaction.Emoji}
+			rows[k] = row
+			firstReactedAt[k] = reaction.CreatedAt
+		}
+		row.Count++
+		row.Reacted = row.Reacted || reaction.UserID == viewerID
+		if reaction.CreatedAt.Before(firstReactedAt[k]) {
+			firstReactedAt[k] = reaction.CreatedAt
+		}
+	}
+
+	// 絵文字は最初にリアクションされた順に並べる
+	keys := make([]key, 0, len(rows))
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !firstReactedAt[keys[i]].Equal(firstReactedAt[keys[j]]) {
+			return firstReactedAt[keys[i]].Before(firstReactedAt[keys[j]])
+		}
+		return keys[i].emoji < keys[j].emoji
+	})
+	for _, k := range keys {
+		summaries[k.messageID] = append(summaries[k.messageID], rows[k])
+	}
+	return summaries, nil
+}
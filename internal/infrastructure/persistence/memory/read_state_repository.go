@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type readStateRepository struct {
+	db *DB
+}
+
+func NewReadStateRepository(db *DB) repository.ReadStateRepository {
+	return &readStateRepository{db: db}
+}
+
+// readState はユーザーのチャンネルの既読の位置を返す。記録していなければ nil
+func (db *DB) readState(userID uuid.UUID, channelID uuid.UUID) *model.ChannelReadState {
+	for _, state := range db.readStates {
+		if state.UserID == userID && state.ChannelID == channelID {
+			return state
+		}
+	}
+	return nil
+}
+
+func (r *readStateRepository) MarkRead(ctx context.Context, state *model.ChannelReadState) (*model.ChannelReadState, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	// 他の実装の外部キー違反と同じく、ユーザーとチャンネルのどちらがなくても ErrChannelNotFound を返す
+	_, userExists := r.db.users[state.UserID]
+	if _, ok := r.db.channels[state.ChannelID]; !ok || !userExists {
+		return nil, model.ErrChannelNotFound
+	}
+
+	position := &model.MessageCursor{CreatedAt: state.LastReadAt.UTC(), MessageID: state.LastReadMessageID}
+	current := r.db.readState(state.UserID, state.ChannelID)
+	if current == nil {
+		current = &model.ChannelReadState{UserID: state.UserID, ChannelID: state.ChannelID}
+		r.db.readStates = append(r.db.readStates, current)
+	} else if !newer(position, &model.MessageCursor{CreatedAt: current.LastReadAt, MessageID: current.LastReadMessageID}) {
+		// 既読の位置は先へ進めるだけで、古いメッセージを読んでも戻さない
+		found := *current
+		return &found, nil
+	}
+	current.LastReadMessageID = position.MessageID
+	current.LastReadAt = position.CreatedAt
+	current.UpdatedAt = now()
+
+	found := *current
+	return &found, nil
+}
+
+func (r *readStateRepository) GetUnreadCounts(ctx context.Context, userID uuid.UUID) ([]*model.ChannelUnread, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.users[userID]; !ok {
+		return nil, model.ErrUserNotFound
+	}
+
+	t := now()
+	var unread []*model.ChannelUnread
+	for _, channel := range r.db.channels {
+		if channel.DeletedAt != nil {
+			continue
+		}
+		member := r.db.channelMember(channel.ChannelID, userID)
+		state := r.db.readState(userID, channel.ChannelID)
+		if member == nil && (state == nil || channel.Visibility != model.ChannelVisibilityPublic) {
+			continue
+		}
+
+		// 既読の位置がなければ参加した日時から数える
+		counts := &model.ChannelUnread{ChannelID: channel.ChannelID, ChannelName: channel.ChannelName}
+		position := &model.MessageCursor{}
+		if state != nil {
+			position.CreatedAt, position.MessageID = state.LastReadAt, state.LastReadMessageID
+			lastReadAt := state.LastReadAt
+			counts.LastReadMessageID = uuid.NullUUID{UUID: state.LastReadMessageID, Valid: true}
+			counts.LastReadAt = &lastReadAt
+		} else {
+			position.CreatedAt = member.CreatedAt
+		}
+
+		for _, message := range r.db.messages {
+			if message.ChannelID != channel.ChannelID || message.UserID == userID || !visible(message, t) {
+				continue
+			}
+			if !newer(model.CursorOf(message), position) {
+				continue
+			}
+			counts.UnreadCount++
+			if r.db.mention(message.MessageID, userID) != nil {
+				counts.MentionCount++
+			}
+		}
+		unread = append(unread, counts)
+	}
+	sort.Slice(unread, func(i, j int) bool {
+		return unread[i].ChannelName < unread[j].ChannelName
+	})
+	return unread, nil
+}
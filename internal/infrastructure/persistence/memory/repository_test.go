@@ -0,0 +1,24 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository/repositorytest"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/memory"
+)
+
+func TestRepositories(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repositorytest.Repositories {
+		db := memory.NewDB()
+		return &repositorytest.Repositories{
+			User:       memory.NewUserRepository(db),
+			Channel:    memory.NewChannelRepository(db),
+			Message:    memory.NewMessageRepository(db),
+			ReadState:  memory.NewReadStateRepository(db),
+			Mention:    memory.NewMentionRepository(db),
+			Attachment: memory.NewAttachmentRepository(db),
+			Blob:       memory.NewBlobRepository(db),
+			Tx:         memory.NewTxManager(db),
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+)
+
+type searchRepository struct {
+	db *DB
+}
+
+func NewSearchRepository(db *DB) repository.SearchRepository {
+	return &searchRepository{db: db}
+}
+
+func (r *searchRepository) SearchMessages(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	t := now()
+	messages := r.db.loadMessages(func(message *model.Message) bool {
+		channel, ok := r.db.channels[message.ChannelID]
+		switch {
+		case !ok, channel.DeletedAt != nil, !visible(message, t):
+			return false
+		// 一度きりのメッセージはスニペットから本文が漏れるため検索対象にしない
+		case message.BurnAfterRead, message.Encrypted:
+			return false
+		case channel.Visibility != model.ChannelVisibilityPublic && r.db.channelMember(channel.ChannelID, query.ViewerID) == nil:
+			return false
+		case !query.ChannelID.IsNil() && message.ChannelID != query.ChannelID:
+			return false
+		case !query.UserID.IsNil() && message.UserID != query.UserID:
+			return false
+		case query.From != nil && message.CreatedAt.Before(*query.From):
+			return false
+		case query.To != nil && message.CreatedAt.After(*query.To):
+			return false
+		case query.Before != nil && !newer(query.Before, model.CursorOf(message)):
+			return false
+		}
+		return true
+	})
+
+	// 検索語を全て含むメッセージを、大文字小文字を区別せずに探す
+	page := &model.SearchPage{Results: make([]*model.SearchResult, 0, query.Limit)}
+	for _, message := range messages {
+		if !containsAll(message.Content, query.Terms) {
+			continue
+		}
+		if len(page.Results) == query.Limit {
+			page.NextCursor = model.CursorOf(page.Results[len(page.Results)-1].Message)
+			break
+		}
+		page.Results = append(page.Results, &model.SearchResult{Message: message})
+	}
+	return page, nil
+}
+
+func containsAll(content string, terms []string) bool {
+	content = strings.ToLower(content)
+	for _, term := range terms {
+		if !strings.Contains(content, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
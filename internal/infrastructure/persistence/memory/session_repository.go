@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+)
+
+type sessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) repository.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.users[session.UserID]; !ok {
+		return fmt.Errorf("failed to insert into u_session: %w", model.ErrUserNotFound)
+	}
+	if _, ok := r.db.sessions[session.TokenHash]; ok {
+		return fmt.Errorf("failed to insert into u_session: duplicate token hash")
+	}
+	r.db.sessions[session.TokenHash] = &model.Session{
+		TokenHash: session.TokenHash,
+		UserID:    session.UserID,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: now(),
+	}
+	return nil
+}
+
+func (r *sessionRepository) GetSession(ctx context.Context, tokenHash string) (*model.Session, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	session, ok := r.db.sessions[tokenHash]
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *sessionRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.sessions[tokenHash]; !ok {
+		return model.ErrUnauthorized
+	}
+	delete(r.db.sessions, tokenHash)
+	return nil
+}
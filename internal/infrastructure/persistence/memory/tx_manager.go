@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+)
+
+type txKey struct{}
+
+type txManager struct {
+	db *DB
+}
+
+// NewTxManager はメモリ上の DB のトランザクションを扱う
+// トランザクションの間は DB 全体を排他し、ロールバックでは始めたときの複製に戻す
+func NewTxManager(db *DB) repository.TxManager {
+	return &txManager{db: db}
+}
+
+// inTx は ctx がこの DB のトランザクションの中かを返す
+func (db *DB) inTx(ctx context.Context) bool {
+	tx, ok := ctx.Value(txKey{}).(*DB)
+	return ok && tx == db
+}
+
+func (m *txManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.db.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	m.db.mu.Lock()
+	defer m.db.mu.Unlock()
+
+	saved := m.db.tables.clone()
+	defer func() {
+		if p := recover(); p != nil {
+			m.db.tables = saved
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, m.db)); err != nil {
+		m.db.tables = saved
+		return err
+	}
+	return nil
+}
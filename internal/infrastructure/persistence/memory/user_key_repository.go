@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+)
+
+type userKeyRepository struct {
+	db *DB
+}
+
+func NewUserKeyRepository(db *DB) repository.UserKeyRepository {
+	return &userKeyRepository{db: db}
+}
+
+func (r *userKeyRepository) CreateUserKey(ctx context.Context, key *model.UserKey) (*model.UserKey, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	for _, other := range r.db.userKeys {
+		if other.KeyID == key.KeyID || (other.UserID == key.UserID && other.Fingerprint == key.Fingerprint) {
+			return nil, model.ErrUserKeyAlreadyExists
+		}
+	}
+	if _, ok := r.db.users[key.UserID]; !ok {
+		return nil, model.ErrUserNotFound
+	}
+
+	created := &model.UserKey{
+		KeyID:       key.KeyID,
+		UserID:      key.UserID,
+		Algorithm:   key.Algorithm,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		CreatedAt:   now(),
+	}
+	r.db.userKeys[key.KeyID] = created
+
+	found := *created
+	return &found, nil
+}
+
+func (r *userKeyRepository) GetUserKeys(ctx context.Context, userID uuid.UUID) ([]*model.UserKey, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var keys []*model.UserKey
+	for _, key := range r.db.userKeys {
+		if key.UserID == userID {
+			found := *key
+			keys = append(keys, &found)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].KeyID.String() < keys[j].KeyID.String()
+	})
+	return keys, nil
+}
+
+func (r *userKeyRepository) DeleteUserKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	key, ok := r.db.userKeys[keyID]
+	if !ok || key.UserID != userID {
+		return model.ErrUserKeyNotFound
+	}
+	delete(r.db.userKeys, keyID)
+	return nil
+}
@@ -0,0 +1,278 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/gofrs/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type userRepository struct {
+	db *DB
+}
+
+func NewUserRepository(db *DB) repository.UserRepository {
+	return &userRepository{db: db}
+}
+
+// dummyPasswordHash は存在しないユーザーのログイン時に比較対象として使うハッシュ
+const dummyPasswordHash = "$2a$10$ZlZHjACgXYAUORuwbRppgeP3udPG21Np8MY42LfZa.2tNAB7Rpcjq"
+
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hashedPassword), nil
+}
+
+// findUserByName はユーザー名のユーザーを返す。他の実装の一意性に合わせ、大文字小文字を区別しない
+func (db *DB) findUserByName(userName string) *userRow {
+	for _, user := range db.users {
+		if strings.EqualFold(user.UserName, userName) {
+			return user
+		}
+	}
+	return nil
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, req *model.RequestCreateUser) (*model.User, error) {
+	userID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+
+	hashedPassword, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if r.db.findUserByName(req.UserName) != nil {
+		return nil, model.ErrAlreadyExistUserName
+	}
+
+	t := now()
+	user := &userRow{
+		User: model.User{
+			UserID:    userID,
+			UserName:  req.UserName,
+			Nickname:  req.Nickname,
+			Status:    req.Status,
+			CreatedAt: t,
+			UpdatedAt: t,
+		},
+		PasswordHash: hashedPassword,
+	}
+	r.db.users[userID] = user
+
+	created := user.User
+	return &created, nil
+}
+
+func (r *userRepository) GetUsers(ctx context.Context) ([]*model.User, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	var users []*model.User
+	for _, row := range r.db.users {
+		user := row.User
+		users = append(users, &user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].UserID.String() < users[j].UserID.String()
+	})
+	return users, nil
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	row, ok := r.db.users[userID]
+	if !ok {
+		return nil, model.ErrUserNotFound
+	}
+	user := row.User
+	return &user, nil
+}
+
+func (r *userRepository) VerifyPassword(ctx context.Context, userName string, password string) (*model.User, error) {
+	r.db.lock(ctx)
+	row := r.db.findUserByName(userName)
+	var user model.User
+	var passwordHash string
+	if row != nil {
+		user, passwordHash = row.User, row.PasswordHash
+	}
+	r.db.unlock(ctx)
+
+	if row == nil {
+		// ユーザーの有無を応答時間から推測されないよう、存在しない場合も比較を行う
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return nil, model.ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil {
+		return nil, model.ErrInvalidCredentials
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) PatchUser(ctx context.Context, userID uuid.UUID, req *model.RequestPatchUser) (*model.User, error) {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	row, ok := r.db.users[userID]
+	if !ok {
+		return nil, model.ErrUserNotFound
+	}
+
+	if req.UserName != nil {
+		if other := r.db.findUserByName(*req.UserName); other != nil && other.UserID != userID {
+			return nil, model.ErrAlreadyExistUserName
+		}
+	}
+	// メールアドレスは空にした場合は未設定に戻す
+	var email *string
+	if req.Email != nil && *req.Email != "" {
+		for _, other := range r.db.users {
+			if other.UserID != userID && other.Email != nil && strings.EqualFold(*other.Email, *req.Email) {
+				return nil, model.ErrAlreadyExistEmail
+			}
+		}
+		email = req.Email
+	}
+
+	if req.UserName == nil && req.Nickname == nil && req.Status == nil && req.Email == nil {
+		user := row.User
+		return &user, nil
+	}
+
+	if req.UserName != nil {
+		row.UserName = *req.UserName
+	}
+	if req.Nickname != nil {
+		row.Nickname = *req.Nickname
+	}
+	if req.Status != nil {
+		row.Status = *req.Status
+	}
+	if req.Email != nil {
+		row.Email = email
+	}
+	row.UpdatedAt = now()
+
+	user := row.User
+	return &user, nil
+}
+
+func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, req *model.RequestChangePassword) error {
+	r.db.lock(ctx)
+	row, ok := r.db.users[userID]
+	var storedHash string
+	if ok {
+		storedHash = row.PasswordHash
+	}
+	r.db.unlock(ctx)
+
+	if !ok {
+		return model.ErrUserNotFound
+	}
+
+	// Verify old password
+	if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(req.OldPassword)); err != nil {
+		return fmt.Errorf("old password does not match: %w", model.ErrInvalidCredentials)
+	}
+
+	// Hash new password
+	newHashedPassword, err := hashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	row, ok = r.db.users[userID]
+	if !ok {
+		return model.ErrUserNotFound
+	}
+	row.PasswordHash = newHashedPassword
+	return nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	r.db.lock(ctx)
+	defer r.db.unlock(ctx)
+
+	if _, ok := r.db.users[userID]; !ok {
+		return model.ErrUserNotFound
+	}
+
+	// メッセージ（他人の返信を含む）と添付ファイルは、ユーザーと一緒に削除する
+	var messageIDs []uuid.UUID
+	for id, message := range r.db.messages {
+		if message.UserID == userID {
+			messageIDs = append(messageIDs, id)
+		}
+	}
+	r.db.deleteMessages(messageIDs)
+
+	var attachmentIDs []uuid.UUID
+	for id, attachment := range r.db.attachments {
+		if attachment.OwnerID == userID {
+			attachmentIDs = append(attachmentIDs, id)
+		}
+	}
+	r.db.deleteAttachments(attachmentIDs)
+
+	for tokenHash, session := range r.db.sessions {
+		if session.UserID == userID {
+			delete(r.db.sessions, tokenHash)
+		}
+	}
+	members := r.db.members[:0]
+	for _, member := range r.db.members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	r.db.members = members
+	reactions := r.db.reactions[:0]
+	for _, reaction := range r.db.reactions {
+		if reaction.UserID != userID {
+			reactions = append(reactions, reaction)
+		}
+	}
+	r.db.reactions = reactions
+	readStates := r.db.readStates[:0]
+	for _, state := range r.db.readStates {
+		if state.UserID != userID {
+			readStates = append(readStates, state)
+		}
+	}
+	r.db.readStates = readStates
+	r.db.deleteMentions(func(mention *model.MessageMention) bool {
+		return mention.UserID == userID
+	})
+	for keyID, key := range r.db.userKeys {
+		if key.UserID == userID {
+			delete(r.db.userKeys, keyID)
+		}
+	}
+
+	delete(r.db.users, userID)
+	return nil
+}
@@ -22,6 +22,8 @@ func MySQL() *mysql.Config {
 	c.Collation = "utf8mb4_general_ci"
 	c.AllowNativePasswords = true
 	c.ParseTime = true
+	// 他のデータベースと同じく、UPDATE の影響行数は値が変わらなくても条件に一致した行数にする
+	c.ClientFoundRows = true
 
 	return c
 }
@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/rdb"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/util"
+	"github.com/jmoiron/sqlx"
+)
+
+// Driver は DB_DRIVER 環境変数 (mysql / postgres / sqlite) の値。sqlx.DB.DriverName と同じ名前
+func Driver() string {
+	return util.GetEnvOrDefault("DB_DRIVER", "mysql")
+}
+
+// Open は DB_DRIVER に応じたデータベースに接続する
+func Open() (*sqlx.DB, error) {
+	switch driver := Driver(); driver {
+	case "mysql":
+		return sqlx.Connect(driver, MySQL().FormatDSN())
+	case "postgres":
+		return sqlx.Connect(driver, Postgres())
+	case "sqlite":
+		db, err := sqlx.Connect(driver, SQLite())
+		if err != nil {
+			return nil, err
+		}
+		// 書き込みは1つずつしかできず、":memory:" は接続ごとに別のデータベースになるため、接続を1本にする
+		db.SetMaxOpenConns(1)
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER: %s", driver)
+	}
+}
+
+// Repositories はデータベースに保存する全てのリポジトリ
+type Repositories struct {
+	User       repository.UserRepository
+	Channel    repository.ChannelRepository
+	Message    repository.MessageRepository
+	Session    repository.SessionRepository
+	Search     repository.SearchRepository
+	Reaction   repository.ReactionRepository
+	Attachment repository.AttachmentRepository
+	Blob       repository.BlobRepository
+	UserKey    repository.UserKeyRepository
+	ReadState  repository.ReadStateRepository
+	Mention    repository.MentionRepository
+	// Tx は上のリポジトリの操作を1つのトランザクションで実行する
+	Tx repository.TxManager
+}
+
+// NewRepositories は db のドライバーの Dialect でリポジトリを作成する
+func NewRepositories(db *sqlx.DB) (*Repositories, error) {
+	dialect, err := rdb.DialectOf(db.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	return &Repositories{
+		User:       rdb.NewUserRepository(db, dialect),
+		Channel:    rdb.NewChannelRepository(db, dialect),
+		Message:    rdb.NewMessageRepository(db, dialect),
+		Session:    rdb.NewSessionRepository(db, dialect),
+		Search:     rdb.NewSearchRepository(db, dialect),
+		Reaction:   rdb.NewReactionRepository(db, dialect),
+		Attachment: rdb.NewAttachmentRepository(db, dialect),
+		Blob:       rdb.NewBlobRepository(db, dialect),
+		UserKey:    rdb.NewUserKeyRepository(db, dialect),
+		ReadState:  rdb.NewReadStateRepository(db, dialect),
+		Mention:    rdb.NewMentionRepository(db, dialect),
+		Tx:         dbtx.NewTxManager(db),
+	}, nil
+}
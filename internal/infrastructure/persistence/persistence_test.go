@@ -0,0 +1,141 @@
+package persistence_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository/repositorytest"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/migration"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// newRepositories はマイグレーションを適用した db のリポジトリを返す
+func newRepositories(t *testing.T, db *sqlx.DB) *repositorytest.Repositories {
+	t.Helper()
+	t.Cleanup(func() { db.Close() })
+
+	if err := migration.MigrateTables(db); err != nil {
+		t.Fatalf("MigrateTables: %v", err)
+	}
+	repos, err := persistence.NewRepositories(db)
+	if err != nil {
+		t.Fatalf("NewRepositories: %v", err)
+	}
+	return &repositorytest.Repositories{
+		User:       repos.User,
+		Channel:    repos.Channel,
+		Message:    repos.Message,
+		ReadState:  repos.ReadState,
+		Mention:    repos.Mention,
+		Attachment: repos.Attachment,
+		Blob:       repos.Blob,
+		Tx:         repos.Tx,
+	}
+}
+
+// databaseName はテストごとに作る使い捨てのデータベースの名前
+func databaseName() string {
+	return "clipboard_test_" + strings.ReplaceAll(uuid.Must(uuid.NewV4()).String(), "-", "")[:16]
+}
+
+func TestSQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	repositorytest.Run(t, func(t *testing.T) *repositorytest.Repositories {
+		t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "clipboard.db"))
+		db, err := persistence.Open()
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		return newRepositories(t, db)
+	})
+}
+
+// testAddr は環境変数からテスト用のデータベースの接続先を返す
+// 未設定ならスキップするが、CI のように TEST_REQUIRE_DB が設定されていれば失敗にして、検証されないまま通るのを防ぐ
+func testAddr(t *testing.T, key string) string {
+	t.Helper()
+	addr := os.Getenv(key)
+	if addr == "" {
+		if os.Getenv("TEST_REQUIRE_DB") != "" {
+			t.Fatalf("%s is not set but TEST_REQUIRE_DB is", key)
+		}
+		t.Skipf("%s is not set; this backend is not verified", key)
+	}
+	return addr
+}
+
+// TestMySQL は TEST_MYSQL_ADDR (host:port) の MySQL / MariaDB に、テストごとにデータベースを作って実行する
+// docker compose --profile test up -d mysql-test で起動したものなら TEST_MYSQL_ADDR=localhost:3307
+func TestMySQL(t *testing.T) {
+	addr := testAddr(t, "TEST_MYSQL_ADDR")
+
+	repositorytest.Run(t, func(t *testing.T) *repositorytest.Repositories {
+		config := persistence.MySQL()
+		config.Addr = addr
+		config.DBName = ""
+		admin, err := sqlx.Connect("mysql", config.FormatDSN())
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		t.Cleanup(func() { admin.Close() })
+
+		config.DBName = databaseName()
+		if _, err := admin.Exec("CREATE DATABASE " + config.DBName); err != nil {
+			t.Fatalf("create database: %v", err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP DATABASE " + config.DBName) })
+
+		db, err := sqlx.Connect("mysql", config.FormatDSN())
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		repos := newRepositories(t, db)
+
+		// 他のバックエンドと同じく空のデータベースから始めるため、マイグレーションで入るテストデータを消す
+		for _, table := range []string{"u_channel", "u_user", "u_blob"} {
+			if _, err := db.Exec("DELETE FROM " + table); err != nil {
+				t.Fatalf("delete test data from %s: %v", table, err)
+			}
+		}
+		return repos
+	})
+}
+
+// TestPostgres は TEST_POSTGRES_ADDR (host:port) の PostgreSQL に、テストごとにデータベースを作って実行する
+// docker compose --profile test up -d postgres-test で起動したものなら TEST_POSTGRES_ADDR=localhost:5433
+func TestPostgres(t *testing.T) {
+	addr := testAddr(t, "TEST_POSTGRES_ADDR")
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("TEST_POSTGRES_ADDR: %v", err)
+	}
+	t.Setenv("DB_HOST", host)
+	t.Setenv("DB_PORT", port)
+
+	repositorytest.Run(t, func(t *testing.T) *repositorytest.Repositories {
+		t.Setenv("DB_NAME", "postgres")
+		admin, err := sqlx.Connect("postgres", persistence.Postgres())
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		t.Cleanup(func() { admin.Close() })
+
+		name := databaseName()
+		if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+			t.Fatalf("create database: %v", err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP DATABASE " + name + " WITH (FORCE)") })
+
+		t.Setenv("DB_NAME", name)
+		db, err := sqlx.Connect("postgres", persistence.Postgres())
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		return newRepositories(t, db)
+	})
+}
@@ -0,0 +1,29 @@
+package persistence
+
+import (
+	"net"
+	"net/url"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/util"
+)
+
+func Postgres() string {
+	q := url.Values{}
+	q.Set("sslmode", util.GetEnvOrDefault("DB_SSLMODE", "disable"))
+	q.Set("timezone", "UTC")
+
+	u := url.URL{
+		Scheme: "postgres",
+		User: url.UserPassword(
+			util.GetEnvOrDefault("DB_USER", "postgres"),
+			util.GetEnvOrDefault("DB_PASS", "password"),
+		),
+		Host: net.JoinHostPort(
+			util.GetEnvOrDefault("DB_HOST", "localhost"),
+			util.GetEnvOrDefault("DB_PORT", "5432"),
+		),
+		Path:     "/" + util.GetEnvOrDefault("DB_NAME", "app"),
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
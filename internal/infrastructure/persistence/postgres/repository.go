@@ -0,0 +1,54 @@
+// Package postgres は PostgreSQL のリポジトリを作成する
+// SQL は rdb のものを共有し、Dialect だけを PostgreSQL に固定する
+// DB_DRIVER で切り替える場合は persistence.NewRepositories を使う
+package postgres
+
+import (
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/rdb"
+	"github.com/jmoiron/sqlx"
+)
+
+func NewUserRepository(db *sqlx.DB) repository.UserRepository {
+	return rdb.NewUserRepository(db, rdb.Postgres)
+}
+
+func NewChannelRepository(db *sqlx.DB) repository.ChannelRepository {
+	return rdb.NewChannelRepository(db, rdb.Postgres)
+}
+
+func NewMessageRepository(db *sqlx.DB) repository.MessageRepository {
+	return rdb.NewMessageRepository(db, rdb.Postgres)
+}
+
+func NewSessionRepository(db *sqlx.DB) repository.SessionRepository {
+	return rdb.NewSessionRepository(db, rdb.Postgres)
+}
+
+func NewSearchRepository(db *sqlx.DB) repository.SearchRepository {
+	return rdb.NewSearchRepository(db, rdb.Postgres)
+}
+
+func NewReactionRepository(db *sqlx.DB) repository.ReactionRepository {
+	return rdb.NewReactionRepository(db, rdb.Postgres)
+}
+
+func NewAttachmentRepository(db *sqlx.DB) repository.AttachmentRepository {
+	return rdb.NewAttachmentRepository(db, rdb.Postgres)
+}
+
+func NewBlobRepository(db *sqlx.DB) repository.BlobRepository {
+	return rdb.NewBlobRepository(db, rdb.Postgres)
+}
+
+func NewUserKeyRepository(db *sqlx.DB) repository.UserKeyRepository {
+	return rdb.NewUserKeyRepository(db, rdb.Postgres)
+}
+
+func NewReadStateRepository(db *sqlx.DB) repository.ReadStateRepository {
+	return rdb.NewReadStateRepository(db, rdb.Postgres)
+}
+
+func NewMentionRepository(db *sqlx.DB) repository.MentionRepository {
+	return rdb.NewMentionRepository(db, rdb.Postgres)
+}
@@ -0,0 +1,148 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// selectAttachments は本体のストレージキーを含めて添付ファイルを取得するクエリの先頭部分
+const selectAttachments = `SELECT a.*, b.storage_key FROM u_attachment a
+JOIN u_blob b ON a.sha256 = b.sha256`
+
+type attachmentRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewAttachmentRepository(db *sqlx.DB, dialect Dialect) repository.AttachmentRepository {
+	return &attachmentRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *attachmentRepository) CreateAttachment(ctx context.Context, attachment *model.Attachment) (*model.Attachment, error) {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, deduplicated, err := acquireStoredBlob(ctx, tx, r.dialect, attachment.SHA256, attachment.Size, attachment.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO u_attachment (attachment_id, owner_id, file_name, mime_type, size, sha256) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, tx.Rebind(query),
+		attachment.AttachmentID.String(),
+		attachment.OwnerID.String(),
+		attachment.FileName,
+		attachment.MimeType,
+		attachment.Size,
+		attachment.SHA256,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert into u_attachment: %w", err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	created, err := r.GetAttachment(ctx, attachment.AttachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch created attachment: %w", err)
+	}
+	created.Deduplicated = &deduplicated
+	return created, nil
+}
+
+func (r *attachmentRepository) GetAttachment(ctx context.Context, attachmentID uuid.UUID) (*model.Attachment, error) {
+	query := selectAttachments + ` WHERE a.attachment_id = ?`
+	var attachment model.Attachment
+	if err := r.db.GetContext(ctx, &attachment, r.db.Rebind(query), attachmentID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) GetAttachmentsByMessageIDs(ctx context.Context, messageIDs []uuid.UUID) (map[uuid.UUID][]*model.Attachment, error) {
+	attachments := make(map[uuid.UUID][]*model.Attachment, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	ids := make([]string, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		ids = append(ids, id.String())
+	}
+
+	query, args, err := sqlx.In(`SELECT a.*, b.storage_key, ma.message_id FROM u_attachment a
+	JOIN u_blob b ON a.sha256 = b.sha256
+	JOIN u_message_attachment ma ON a.attachment_id = ma.attachment_id
+	WHERE ma.message_id IN (?) ORDER BY ma.message_id, ma.position`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*model.Attachment
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		attachments[row.MessageID] = append(attachments[row.MessageID], row)
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) GetAttachmentChannels(ctx context.Context, attachmentID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT m.channel_id FROM u_message m
+	JOIN u_message_attachment ma ON m.message_id = ma.message_id
+	WHERE ma.attachment_id = ? AND m.deleted_at IS NULL`
+	var channelIDs []uuid.UUID
+	if err := r.db.SelectContext(ctx, &channelIDs, r.db.Rebind(query), attachmentID.String()); err != nil {
+		return nil, err
+	}
+	return channelIDs, nil
+}
+
+func (r *attachmentRepository) DeleteAttachment(ctx context.Context, attachmentID uuid.UUID) error {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := releaseBlobs(ctx, tx, r.dialect, `SELECT sha256 FROM u_attachment WHERE attachment_id = ?`, attachmentID.String()); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM u_attachment WHERE attachment_id = ?`), attachmentID.String())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrAttachmentNotFound
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
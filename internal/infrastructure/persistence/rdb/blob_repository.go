@@ -0,0 +1,124 @@
+package rdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/jmoiron/sqlx"
+)
+
+type blobRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewBlobRepository(db *sqlx.DB, dialect Dialect) repository.BlobRepository {
+	return &blobRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// acquireContentBlob はメッセージ本文を u_blob に登録して参照数を1増やす
+// 同じ本文が既にあれば新たに保存せず、deduplicated として true を返す
+func acquireContentBlob(ctx context.Context, tx *dbtx.Tx, d Dialect, content string) (string, bool, error) {
+	hash := contentHash(content)
+	deduplicated, err := d.UpsertBlob(ctx, tx, hash, int64(len(content)), "content", content)
+	if err != nil {
+		return "", false, err
+	}
+	return hash, deduplicated, nil
+}
+
+// acquireStoredBlob はファイル本体を u_blob に登録して参照数を1増やし、実際に使うストレージキーを返す
+// 既に同じハッシュの本体があればそのキーを返し、deduplicated として true を返す
+func acquireStoredBlob(ctx context.Context, tx *dbtx.Tx, d Dialect, hash string, size int64, storageKey string) (string, bool, error) {
+	if _, err := d.UpsertBlob(ctx, tx, hash, size, "storage_key", storageKey); err != nil {
+		return "", false, err
+	}
+
+	var actualKey string
+	if err := tx.GetContext(ctx, &actualKey, tx.Rebind(`SELECT storage_key FROM u_blob WHERE sha256 = ?`), hash); err != nil {
+		return "", false, fmt.Errorf("failed to fetch u_blob: %w", err)
+	}
+	return actualKey, actualKey != storageKey, nil
+}
+
+// releaseBlobs は refsQuery が返す sha256 列の出現回数だけ参照数を減らす
+// 外部キーの ON DELETE CASCADE では参照数が減らないため、行を削除する前に呼ぶ
+// UPDATE での結合はデータベースごとに書き方が違うため、相関サブクエリで数える
+func releaseBlobs(ctx context.Context, tx *dbtx.Tx, d Dialect, refsQuery string, args ...interface{}) error {
+	query := `UPDATE u_blob SET
+		ref_count = ref_count - (SELECT COUNT(*) FROM (` + refsQuery + `) refs WHERE refs.sha256 = u_blob.sha256),
+		updated_at = ` + d.Now() + `
+	WHERE sha256 IN (SELECT refs.sha256 FROM (` + refsQuery + `) refs)`
+	args = append(append([]interface{}{}, args...), args...)
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to release u_blob: %w", err)
+	}
+	return nil
+}
+
+func (r *blobRepository) DeleteUnreferencedBlobs(ctx context.Context, olderThan time.Time, limit int) ([]*model.Blob, error) {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 同じハッシュを新たに参照しようとする書き込みは行ロックで待たせる
+	query := `SELECT sha256, size, storage_key, ref_count, created_at, updated_at FROM u_blob
+	WHERE ref_count <= 0 AND updated_at < ? ORDER BY updated_at LIMIT ?` + r.dialect.ForUpdate("u_blob")
+	var blobs []*model.Blob
+	if err := tx.SelectContext(ctx, &blobs, tx.Rebind(query), r.dialect.Time(olderThan), limit); err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL では外部キー制約の違反がトランザクション全体を中断させるため、参照が残っていないことを条件に削除する
+	deleteQuery := tx.Rebind(`DELETE FROM u_blob WHERE sha256 = ?
+	AND NOT EXISTS (SELECT 1 FROM u_message WHERE content_sha256 = ?)
+	AND NOT EXISTS (SELECT 1 FROM u_message_revision WHERE content_sha256 = ?)
+	AND NOT EXISTS (SELECT 1 FROM u_attachment WHERE sha256 = ?)`)
+	deleted := make([]*model.Blob, 0, len(blobs))
+	for _, blob := range blobs {
+		result, err := tx.ExecContext(ctx, deleteQuery, blob.SHA256, blob.SHA256, blob.SHA256, blob.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete from u_blob: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if rowsAffected == 0 {
+			// 参照数がずれていてまだ参照が残っていれば、数え直して残す
+			log.Printf("blob %s is still referenced; recounting references", blob.SHA256)
+			recount := `UPDATE u_blob SET ref_count =
+				(SELECT COUNT(*) FROM u_message WHERE content_sha256 = ?) +
+				(SELECT COUNT(*) FROM u_message_revision WHERE content_sha256 = ?) +
+				(SELECT COUNT(*) FROM u_attachment WHERE sha256 = ?),
+				updated_at = ` + r.dialect.Now() + `
+			WHERE sha256 = ?`
+			if _, err := tx.ExecContext(ctx, tx.Rebind(recount), blob.SHA256, blob.SHA256, blob.SHA256, blob.SHA256); err != nil {
+				return nil, fmt.Errorf("failed to recount u_blob references: %w", err)
+			}
+			continue
+		}
+		deleted = append(deleted, blob)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,301 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type channelRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewChannelRepository(db *sqlx.DB, dialect Dialect) repository.ChannelRepository {
+	return &channelRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *channelRepository) CreateChannel(ctx context.Context, req *model.RequestCreateChannel) (*model.Channel, error) {
+	channelID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO u_channel (channel_id, channel_name, display_name, description, visibility) VALUES (?, ?, ?, ?, ?)`
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), channelID.String(), req.ChannelName, req.DisplayName, req.Description, req.Visibility)
+	if err != nil {
+		if isDuplicateKey(err) {
+			return nil, model.ErrAlreadyExistChannelName
+		}
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, model.ErrChannelNotFound
+	}
+
+	// 作成者をオーナーとして登録する
+	if !req.OwnerID.IsNil() {
+		memberQuery := `INSERT INTO u_channel_member (channel_id, user_id, role) VALUES (?, ?, ?)`
+		_, err = tx.ExecContext(ctx, tx.Rebind(memberQuery), channelID.String(), req.OwnerID.String(), model.ChannelRoleOwner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert into u_channel_member: %w", err)
+		}
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	var createdChannel model.Channel
+	selectQuery := `SELECT * FROM u_channel WHERE channel_id = ?`
+
+	if err := r.db.GetContext(ctx, &createdChannel, r.db.Rebind(selectQuery), channelID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("channel not found after successful insert: %w", err)
+		}
+		return nil, fmt.Errorf("failed to fetch created channel: %w", err)
+	}
+
+	return &createdChannel, nil
+}
+
+func (r *channelRepository) GetChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error) {
+	query := `SELECT * FROM u_channel WHERE channel_id = ? AND deleted_at IS NULL`
+	var channel model.Channel
+	if err := r.db.GetContext(ctx, &channel, r.db.Rebind(query), channelID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrChannelNotFound
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *channelRepository) GetDeletedChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error) {
+	query := `SELECT * FROM u_channel WHERE channel_id = ? AND deleted_at IS NOT NULL`
+	var channel model.Channel
+	if err := r.db.GetContext(ctx, &channel, r.db.Rebind(query), channelID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrChannelNotFound
+		}
+		return nil, err
+	}
+	return &channel, nil
+}
+
+func (r *channelRepository) GetChannels(ctx context.Context, viewerID uuid.UUID, includeDeleted bool) ([]*model.Channel, error) {
+	query := `SELECT c.* FROM u_channel c
+	WHERE c.deleted_at IS NULL AND (c.visibility = ?
+	OR EXISTS (SELECT 1 FROM u_channel_member cm WHERE cm.channel_id = c.channel_id AND cm.user_id = ?))`
+	args := []interface{}{model.ChannelVisibilityPublic, viewerID.String()}
+	if includeDeleted {
+		query += `
+	OR c.deleted_at IS NOT NULL AND EXISTS (SELECT 1 FROM u_channel_member cm
+		WHERE cm.channel_id = c.channel_id AND cm.user_id = ? AND cm.role IN (?, ?))`
+		args = append(args, viewerID.String(), model.ChannelRoleOwner, model.ChannelRoleAdmin)
+	}
+	var channels []*model.Channel
+	if err := r.db.SelectContext(ctx, &channels, r.db.Rebind(query), args...); err != nil {
+		if err == sql.ErrNoRows {
+			return []*model.Channel{}, nil
+		}
+		return nil, err
+	}
+	return channels, nil
+}
+
+func (r *channelRepository) PatchChannel(ctx context.Context, channelID uuid.UUID, req *model.RequestPatchChannel) (*model.Channel, error) {
+	setClauses := []string{"updated_at = " + r.dialect.Now()}
+	args := []interface{}{}
+
+	if req.DisplayName != nil {
+		setClauses = append(setClauses, "display_name = ?")
+		args = append(args, *req.DisplayName)
+	}
+	if req.Description != nil {
+		setClauses = append(setClauses, "description = ?")
+		args = append(args, *req.Description)
+	}
+	if req.Visibility != nil {
+		setClauses = append(setClauses, "visibility = ?")
+		args = append(args, *req.Visibility)
+	}
+
+	if len(setClauses) == 1 {
+		return r.GetChannel(ctx, channelID)
+	}
+
+	args = append(args, channelID.String())
+	query := fmt.Sprintf("UPDATE u_channel SET %s WHERE channel_id = ? AND deleted_at IS NULL", strings.Join(setClauses, ", "))
+
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, model.ErrChannelNotFound
+	}
+
+	return r.GetChannel(ctx, channelID)
+}
+
+func (r *channelRepository) DeleteChannel(ctx context.Context, channelID uuid.UUID) error {
+	// updated_at は設定の変更日時として使うため、削除では変えない
+	query := `UPDATE u_channel SET deleted_at = ` + r.dialect.Now() + `, updated_at = updated_at WHERE channel_id = ? AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), channelID.String())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrChannelNotFound
+	}
+	return nil
+}
+
+func (r *channelRepository) RestoreChannel(ctx context.Context, channelID uuid.UUID) error {
+	query := `UPDATE u_channel SET deleted_at = NULL, updated_at = updated_at WHERE channel_id = ? AND deleted_at IS NOT NULL`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), channelID.String())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrChannelNotFound
+	}
+	return nil
+}
+
+func (r *channelRepository) PurgeDeletedChannels(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT channel_id FROM u_channel
+	WHERE deleted_at < ? ORDER BY deleted_at LIMIT ?` + r.dialect.ForUpdate("u_channel")
+	var ids []string
+	if err := tx.SelectContext(ctx, &ids, tx.Rebind(query), r.dialect.Time(deletedBefore), limit); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// メッセージは CASCADE に任せず、本文と添付ファイルの参照を外しながら先に削除する
+	messagesQuery, args, err := sqlx.In(`SELECT message_id FROM u_message WHERE channel_id IN (?)`, ids)
+	if err != nil {
+		return 0, err
+	}
+	var messageIDs []string
+	if err := tx.SelectContext(ctx, &messageIDs, tx.Rebind(messagesQuery), args...); err != nil {
+		return 0, err
+	}
+	if len(messageIDs) > 0 {
+		if _, err := deleteMessages(ctx, tx, r.dialect, messageIDs); err != nil {
+			return 0, err
+		}
+	}
+
+	deleteQuery, args, err := sqlx.In(`DELETE FROM u_channel WHERE channel_id IN (?)`, ids)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteQuery), args...); err != nil {
+		return 0, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(ids), nil
+}
+
+func (r *channelRepository) AddChannelMember(ctx context.Context, member *model.ChannelMember) (*model.ChannelMember, error) {
+	query := `INSERT INTO u_channel_member (channel_id, user_id, role) VALUES (?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), member.ChannelID.String(), member.UserID.String(), member.Role)
+	if err != nil {
+		switch {
+		case isDuplicateKey(err):
+			return nil, model.ErrAlreadyChannelMember
+		case isForeignKeyViolation(err):
+			// 外部キー制約違反: チャンネルかユーザーが存在しない
+			return nil, model.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to insert into u_channel_member: %w", err)
+	}
+
+	return r.GetChannelMember(ctx, member.ChannelID, member.UserID)
+}
+
+func (r *channelRepository) GetChannelMember(ctx context.Context, channelID uuid.UUID, userID uuid.UUID) (*model.ChannelMember, error) {
+	query := `SELECT * FROM u_channel_member WHERE channel_id = ? AND user_id = ?`
+	var member model.ChannelMember
+	if err := r.db.GetContext(ctx, &member, r.db.Rebind(query), channelID.String(), userID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrChannelMemberNotFound
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *channelRepository) GetChannelMembers(ctx context.Context, channelID uuid.UUID) ([]*model.ChannelMember, error) {
+	query := `SELECT * FROM u_channel_member WHERE channel_id = ? ORDER BY created_at`
+	var members []*model.ChannelMember
+	if err := r.db.SelectContext(ctx, &members, r.db.Rebind(query), channelID.String()); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (r *channelRepository) RemoveChannelMember(ctx context.Context, channelID uuid.UUID, userID uuid.UUID) error {
+	query := `DELETE FROM u_channel_member WHERE channel_id = ? AND user_id = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), channelID.String(), userID.String())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrChannelMemberNotFound
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+)
+
+// Dialect はデータベースごとに書き方が異なる SQL をまとめる
+// プレースホルダーはどのデータベースでも ? で書き、dbtx の Rebind でデータベースの形式に書き換える
+type Dialect interface {
+	// Now は現在の UTC の日時を表す式を返す
+	Now() string
+	// Time は日時をクエリの引数として渡す値にする
+	Time(t time.Time) interface{}
+	// ForUpdate は選択した行をトランザクションの終わりまでロックする句を返す
+	// table はロックする表の名前か別名。結合したクエリでも table の行だけをロックする
+	ForUpdate(table string) string
+	// OnConflict は INSERT が conflict の列の一意制約に反したとき、既存の行を set で更新する句を返す
+	// set が空なら何もしない。set の中では Excluded で挿入しようとした値を参照する
+	OnConflict(table string, conflict []string, set string) string
+	// Excluded は OnConflict の set で、挿入しようとした column の値を表す式を返す
+	Excluded(column string) string
+	// FoldCase は user_name の一意性と同じく、大文字と小文字を区別せずに比べるための式を返す
+	// 比べる値は小文字にして渡す
+	FoldCase(expr string) string
+	// MatchTerms は column が検索語を全て含む条件とその引数を返す
+	MatchTerms(column string, terms []string) (string, []interface{})
+	// UpsertBlob は u_blob に行を追加し、同じ sha256 の行があれば参照数を1増やす
+	// column は追加する行で value を入れる列で、既存の行でその列が NULL なら value で埋める
+	// 既存の行を更新したかを返す
+	UpsertBlob(ctx context.Context, tx *dbtx.Tx, hash string, size int64, column string, value interface{}) (bool, error)
+}
+
+// データベースごとの Dialect
+var (
+	MySQL    Dialect = mysqlDialect{}
+	Postgres Dialect = postgresDialect{}
+	SQLite   Dialect = sqliteDialect{}
+)
+
+// DialectOf は sqlx.DB.DriverName と同じドライバー名に対応する Dialect を返す
+func DialectOf(driver string) (Dialect, error) {
+	switch driver {
+	case "mysql":
+		return MySQL, nil
+	case "postgres":
+		return Postgres, nil
+	case "sqlite":
+		return SQLite, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %s", driver)
+	}
+}
+
+// insertBlob は UpsertBlob の INSERT の部分。column は呼び出し元が決めた列名のみ
+func insertBlob(column string) string {
+	return `INSERT INTO u_blob (sha256, size, ` + column + `, ref_count) VALUES (?, ?, ?, 1) `
+}
+
+// incrementBlob は UpsertBlob で既存の行を更新する set
+func incrementBlob(d Dialect, column string) string {
+	return `ref_count = u_blob.ref_count + 1, ` +
+		column + ` = COALESCE(u_blob.` + column + `, ` + d.Excluded(column) + `), updated_at = ` + d.Now()
+}
+
+func nullTime(d Dialect, t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return d.Time(*t)
+}
@@ -0,0 +1,60 @@
+package rdb
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	driver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// MySQL のエラー番号
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+)
+
+// PostgreSQL の SQLSTATE
+const (
+	postgresUniqueViolation     = "23505"
+	postgresForeignKeyViolation = "23503"
+)
+
+// isDuplicateKey は一意制約に違反したエラーかを判定する
+func isDuplicateKey(err error) bool {
+	var (
+		mysqlErr  *mysql.MySQLError
+		pqErr     *pq.Error
+		sqliteErr *driver.Error
+	)
+	switch {
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == mysqlDuplicateEntry
+	case errors.As(err, &pqErr):
+		return pqErr.Code == postgresUniqueViolation
+	case errors.As(err, &sqliteErr):
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	}
+	return false
+}
+
+// isForeignKeyViolation は外部キー制約に違反したエラーかを判定する
+// 参照先がない行の挿入と、参照されている行の削除のどちらも含む
+func isForeignKeyViolation(err error) bool {
+	var (
+		mysqlErr  *mysql.MySQLError
+		pqErr     *pq.Error
+		sqliteErr *driver.Error
+	)
+	switch {
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == mysqlNoReferencedRow || mysqlErr.Number == mysqlRowIsReferenced
+	case errors.As(err, &pqErr):
+		return pqErr.Code == postgresForeignKeyViolation
+	case errors.As(err, &sqliteErr):
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// mentionRow は u_mention の行に、宛先のユーザーが既読にしたかを加えたもの
+type mentionRow struct {
+	model.MessageMention
+	Read bool `db:"is_read"`
+}
+
+type mentionRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewMentionRepository(db *sqlx.DB, dialect Dialect) repository.MentionRepository {
+	return &mentionRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *mentionRepository) SaveMentions(ctx context.Context, messageID uuid.UUID, targets *model.MentionTargets) error {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM u_mention WHERE message_id = ?`), messageID.String()); err != nil {
+		return fmt.Errorf("failed to delete from u_mention: %w", err)
+	}
+
+	// user_name は大文字と小文字を区別せずに一意なので、小文字にして比べる
+	if len(targets.UserNames) > 0 {
+		userNames := make([]string, 0, len(targets.UserNames))
+		for _, userName := range targets.UserNames {
+			userNames = append(userNames, strings.ToLower(userName))
+		}
+		query, args, err := sqlx.In(`INSERT INTO u_mention (message_id, user_id, channel_id, kind, created_at)
+		SELECT m.message_id, u.user_id, m.channel_id, ?, m.created_at
+		FROM u_message m
+		JOIN u_channel c ON c.channel_id = m.channel_id
+		JOIN u_user u ON `+r.dialect.FoldCase("u.user_name")+` IN (?)
+		WHERE m.message_id = ? AND u.user_id <> m.user_id
+			AND (c.visibility = ? OR EXISTS (SELECT 1 FROM u_channel_member cm WHERE cm.channel_id = c.channel_id AND cm.user_id = u.user_id))`,
+			model.MentionKindUser, userNames, messageID.String(), model.ChannelVisibilityPublic)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			return fmt.Errorf("failed to insert into u_mention: %w", err)
+		}
+	}
+
+	// 名前でも宛てたユーザーは既に登録済みなので、重複は無視する
+	if targets.Channel {
+		query := `INSERT INTO u_mention (message_id, user_id, channel_id, kind, created_at)
+		SELECT m.message_id, cm.user_id, m.channel_id, ?, m.created_at
+		FROM u_message m
+		JOIN u_channel_member cm ON cm.channel_id = m.channel_id
+		WHERE m.message_id = ? AND cm.user_id <> m.user_id` +
+			r.dialect.OnConflict("u_mention", []string{"message_id", "user_id"}, "")
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), model.MentionKindChannel, messageID.String()); err != nil {
+			return fmt.Errorf("failed to insert into u_mention: %w", err)
+		}
+	}
+
+	// commit transaction
+	return tx.Commit()
+}
+
+func (r *mentionRepository) GetMentions(ctx context.Context, userID uuid.UUID, query *model.MentionQuery) (*model.MentionPage, error) {
+	// 既読かどうかは未読数と同じく u_channel_read_state の位置で決め、位置がなければ未読とする
+	whereClauses := []string{
+		"mn.user_id = ?", "c.deleted_at IS NULL", "(c.visibility = ? OR cm.user_id IS NOT NULL)", notDeleted, notExpired(r.dialect),
+	}
+	args := []interface{}{userID.String(), model.ChannelVisibilityPublic}
+	if query.Before != nil {
+		whereClauses = append(whereClauses, "(mn.created_at < ? OR (mn.created_at = ? AND mn.message_id < ?))")
+		args = append(args, r.dialect.Time(query.Before.CreatedAt), r.dialect.Time(query.Before.CreatedAt), query.Before.MessageID.String())
+	}
+	if query.UnreadOnly {
+		whereClauses = append(whereClauses, "COALESCE("+unreadPosition+", TRUE)")
+	}
+	// 続きがあるかを判定するために1件多く取得する
+	args = append(args, query.Limit+1)
+
+	sqlQuery := `SELECT mn.*, NOT COALESCE(` + unreadPosition + `, TRUE) AS is_read
+	FROM u_mention mn
+	JOIN u_message m ON m.message_id = mn.message_id
+	JOIN u_channel c ON c.channel_id = mn.channel_id
+	LEFT JOIN u_channel_member cm ON cm.channel_id = mn.channel_id AND cm.user_id = mn.user_id
+	LEFT JOIN u_channel_read_state s ON s.channel_id = mn.channel_id AND s.user_id = mn.user_id
+	WHERE ` + strings.Join(whereClauses, " AND ") + `
+	ORDER BY mn.created_at DESC, mn.message_id DESC LIMIT ?`
+
+	var rows []*mentionRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(sqlQuery), args...); err != nil {
+		return nil, err
+	}
+	page := &model.MentionPage{Mentions: []*model.Mention{}}
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = &model.MessageCursor{CreatedAt: last.CreatedAt, MessageID: last.MessageID}
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.MessageID.String())
+	}
+	messagesQuery, messageArgs, err := sqlx.In(selectMessages(r.dialect)+` WHERE m.message_id IN (?)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := selectMessageRows(ctx, r.db, r.db.Rebind(messagesQuery), messageArgs...)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*model.Message, len(messages))
+	for _, message := range messages {
+		byID[message.MessageID] = message
+	}
+	for _, row := range rows {
+		if message, ok := byID[row.MessageID]; ok {
+			page.Mentions = append(page.Mentions, &model.Mention{Message: message, Kind: row.Kind, Read: row.Read})
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,685 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// selectMessages は本文とスレッドの返信数・最終返信日時を含めてメッセージを取得するクエリの先頭部分
+// u_message には m、本文を持つ u_blob には b という別名が付く
+func selectMessages(d Dialect) string {
+	return `SELECT m.*, b.content,
+	(SELECT COUNT(*) FROM u_message r WHERE r.parent_message_id = m.message_id AND r.deleted_at IS NULL
+		AND (r.expires_at IS NULL OR r.expires_at > ` + d.Now() + `)) AS reply_count,
+	(SELECT MAX(r.created_at) FROM u_message r WHERE r.parent_message_id = m.message_id AND r.deleted_at IS NULL
+		AND (r.expires_at IS NULL OR r.expires_at > ` + d.Now() + `)) AS last_reply_at,
+	EXISTS (SELECT 1 FROM u_message_revision rv WHERE rv.message_id = m.message_id) AS edited,
+	(SELECT COUNT(*) FROM u_message_revision rv WHERE rv.message_id = m.message_id) AS revision_count
+FROM u_message m
+JOIN u_blob b ON m.content_sha256 = b.sha256`
+}
+
+// notExpired は期限切れのメッセージを除く条件。削除されるまでの間も読めないようにする
+func notExpired(d Dialect) string {
+	return "(m.expires_at IS NULL OR m.expires_at > " + d.Now() + ")"
+}
+
+// notDeleted はゴミ箱に入ったメッセージを除く条件
+const notDeleted = "m.deleted_at IS NULL"
+
+// messageRow は selectMessages で取得する行。last_reply_at は式のため exprTime で読む
+type messageRow struct {
+	model.Message
+	LastReplyAt exprTime `db:"last_reply_at"`
+}
+
+func (row *messageRow) message() *model.Message {
+	row.Message.LastReplyAt = row.LastReplyAt.Ptr()
+	return &row.Message
+}
+
+// getMessageRow は selectMessages で始まるクエリで1件のメッセージを取得する
+func getMessageRow(ctx context.Context, q sqlx.QueryerContext, query string, args ...interface{}) (*model.Message, error) {
+	var row messageRow
+	if err := sqlx.GetContext(ctx, q, &row, query, args...); err != nil {
+		return nil, err
+	}
+	return row.message(), nil
+}
+
+// selectMessageRows は selectMessages で始まるクエリでメッセージを取得する
+func selectMessageRows(ctx context.Context, q sqlx.QueryerContext, query string, args ...interface{}) ([]*model.Message, error) {
+	var rows []*messageRow
+	if err := sqlx.SelectContext(ctx, q, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	messages := make([]*model.Message, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, row.message())
+	}
+	return messages, nil
+}
+
+type messageRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewMessageRepository(db *sqlx.DB, dialect Dialect) repository.MessageRepository {
+	return &messageRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func nullUUIDString(id uuid.NullUUID) interface{} {
+	if !id.Valid {
+		return nil
+	}
+	return id.UUID.String()
+}
+
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func (r *messageRepository) CreateMessage(ctx context.Context, req *model.RequestCreateMessage) (*model.Message, error) {
+	messageID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	contentSHA256, deduplicated, err := acquireContentBlob(ctx, tx, r.dialect, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO u_message (message_id, channel_id, user_id, parent_message_id, content_sha256, content_type, language, expires_at, burn_after_read,
+		encrypted, ciphertext, nonce, key_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := tx.ExecContext(ctx, tx.Rebind(query),
+		messageID.String(),
+		req.ChannelID.String(),
+		req.UserID.String(),
+		nullUUIDString(req.ParentMessageID),
+		contentSHA256,
+		req.ContentType,
+		nullString(req.Language),
+		nullTime(r.dialect, req.ExpiresAt),
+		req.BurnAfterRead,
+		req.Encrypted,
+		req.Ciphertext,
+		req.Nonce,
+		nullString(req.KeyID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, model.ErrMessageNotFound
+	}
+
+	attachmentQuery := `INSERT INTO u_message_attachment (message_id, attachment_id, position) VALUES (?, ?, ?)`
+	for i, attachmentID := range req.AttachmentIDs {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(attachmentQuery), messageID.String(), attachmentID.String(), i); err != nil {
+			if isForeignKeyViolation(err) {
+				return nil, model.ErrAttachmentNotFound
+			}
+			return nil, fmt.Errorf("failed to insert into u_message_attachment: %w", err)
+		}
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	createdMessage, err := r.GetMessage(ctx, messageID)
+	if err != nil {
+		if err == model.ErrMessageNotFound {
+			return nil, fmt.Errorf("message not found after successful insert: %w", err)
+		}
+		return nil, fmt.Errorf("failed to fetch created message: %w", err)
+	}
+	createdMessage.Deduplicated = &deduplicated
+
+	return createdMessage, nil
+}
+
+func (r *messageRepository) GetMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	query := selectMessages(r.dialect) + ` WHERE m.message_id = ? AND ` + notDeleted + ` AND ` + notExpired(r.dialect) + ` LIMIT 1`
+	message, err := getMessageRow(ctx, r.db, r.db.Rebind(query), messageID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+func (r *messageRepository) GetDeletedMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	query := selectMessages(r.dialect) + ` WHERE m.message_id = ? AND m.deleted_at IS NOT NULL AND ` + notExpired(r.dialect) + ` LIMIT 1`
+	message, err := getMessageRow(ctx, r.db, r.db.Rebind(query), messageID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+func (r *messageRepository) GetMessages(ctx context.Context, channelID uuid.UUID, query *model.MessageQuery) (*model.MessagePage, error) {
+	whereClauses := []string{"m.channel_id = ?", notExpired(r.dialect)}
+	args := []interface{}{channelID.String()}
+	if !query.IncludeDeleted {
+		whereClauses = append(whereClauses, notDeleted)
+	}
+	if query.ExcludeReplies {
+		whereClauses = append(whereClauses, "m.parent_message_id IS NULL")
+	}
+	if query.ContentType != "" {
+		whereClauses = append(whereClauses, "m.content_type = ?")
+		args = append(args, query.ContentType)
+	}
+
+	// 続きがあるかを判定するために1件多く取得する
+	var order string
+	switch {
+	case query.Before != nil:
+		whereClauses = append(whereClauses, "(m.created_at < ? OR (m.created_at = ? AND m.message_id < ?))")
+		args = append(args, r.dialect.Time(query.Before.CreatedAt), r.dialect.Time(query.Before.CreatedAt), query.Before.MessageID.String())
+		order = " ORDER BY m.created_at DESC, m.message_id DESC LIMIT ?"
+		args = append(args, query.Limit+1)
+	case query.After != nil:
+		whereClauses = append(whereClauses, "(m.created_at > ? OR (m.created_at = ? AND m.message_id > ?))")
+		args = append(args, r.dialect.Time(query.After.CreatedAt), r.dialect.Time(query.After.CreatedAt), query.After.MessageID.String())
+		order = " ORDER BY m.created_at ASC, m.message_id ASC LIMIT ?"
+		args = append(args, query.Limit+1)
+	default:
+		order = " ORDER BY m.created_at DESC, m.message_id DESC LIMIT ? OFFSET ?"
+		args = append(args, query.Limit+1, query.Offset)
+	}
+	sqlQuery := selectMessages(r.dialect) + " WHERE " + strings.Join(whereClauses, " AND ") + order
+
+	messages, err := selectMessageRows(ctx, r.db, r.db.Rebind(sqlQuery), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(messages) > query.Limit
+	if hasMore {
+		messages = messages[:query.Limit]
+	}
+
+	page := &model.MessagePage{Messages: messages}
+	if query.After != nil {
+		// 古い順に取得したので新しい順に並べ直す
+		for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+			messages[i], messages[j] = messages[j], messages[i]
+		}
+		// After より古いメッセージ（少なくとも After 自身）は必ず存在する
+		if len(messages) > 0 {
+			page.NextCursor = model.CursorOf(messages[len(messages)-1])
+			page.PrevCursor = model.CursorOf(messages[0])
+		} else {
+			page.PrevCursor = query.After
+		}
+		return page, nil
+	}
+
+	if len(messages) > 0 {
+		// 新着は随時届くため、より新しい方向のカーソルは常に返す
+		page.PrevCursor = model.CursorOf(messages[0])
+		if hasMore {
+			page.NextCursor = model.CursorOf(messages[len(messages)-1])
+		}
+	} else if query.Before != nil {
+		page.PrevCursor = query.Before
+	}
+	return page, nil
+}
+
+func (r *messageRepository) GetMessagesInDuration(ctx context.Context, channelID uuid.UUID, start, end time.Time) ([]*model.Message, error) {
+	query := selectMessages(r.dialect) + ` WHERE m.channel_id = ? AND m.created_at BETWEEN ? AND ? AND ` + notDeleted + ` AND ` + notExpired(r.dialect) + ` ORDER BY m.created_at DESC`
+	return selectMessageRows(ctx, r.db, r.db.Rebind(query), channelID.String(), r.dialect.Time(start), r.dialect.Time(end))
+}
+
+func (r *messageRepository) GetPinnedMessages(ctx context.Context, channelID uuid.UUID) ([]*model.Message, error) {
+	query := selectMessages(r.dialect) + `
+	JOIN u_pinned_message pm ON m.message_id = pm.message_id
+	WHERE m.channel_id = ? AND ` + notDeleted + ` AND ` + notExpired(r.dialect) + ` ORDER BY pm.created_at DESC`
+	return selectMessageRows(ctx, r.db, r.db.Rebind(query), channelID.String())
+}
+
+func (r *messageRepository) GetReplies(ctx context.Context, messageID uuid.UUID) ([]*model.Message, error) {
+	query := selectMessages(r.dialect) + ` WHERE m.parent_message_id = ? AND ` + notDeleted + ` AND ` + notExpired(r.dialect) + ` ORDER BY m.created_at ASC, m.message_id ASC`
+	return selectMessageRows(ctx, r.db, r.db.Rebind(query), messageID.String())
+}
+
+func (r *messageRepository) PatchMessage(ctx context.Context, messageID uuid.UUID, req *model.RequestPatchMessage) (*model.Message, error) {
+	if req.IsEmpty() {
+		return nil, model.ErrNoFieldsToUpdate
+	}
+
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 同時に編集されても版の番号が重ならないよう、メッセージの行をロックする
+	var revision int
+	lockQuery := `SELECT (SELECT COUNT(*) FROM u_message_revision WHERE message_id = m.message_id) + 1
+	FROM u_message m WHERE m.message_id = ? AND ` + notDeleted + ` AND ` + notExpired(r.dialect) + r.dialect.ForUpdate("m")
+	if err := tx.GetContext(ctx, &revision, tx.Rebind(lockQuery), messageID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	// 更新前の版を記録する。created_at にはその版が書かれた日時を入れる
+	// 版の番号は SELECT の中の引数だと PostgreSQL が型を決められないため、数値のまま埋め込む
+	revisionQuery := `INSERT INTO u_message_revision
+		(message_id, revision, content_sha256, content_type, language, encrypted, ciphertext, nonce, key_id, created_at)
+	SELECT message_id, ` + strconv.Itoa(revision) + `, content_sha256, content_type, language, encrypted, ciphertext, nonce, key_id, updated_at
+	FROM u_message WHERE message_id = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(revisionQuery), messageID.String()); err != nil {
+		return nil, fmt.Errorf("failed to insert into u_message_revision: %w", err)
+	}
+
+	setClauses := []string{"updated_at = " + r.dialect.Now()}
+	args := []interface{}{}
+
+	if req.Content == nil {
+		// 本文が変わらなければ、記録した版とメッセージの両方が同じ本文を参照する
+		retainQuery := `UPDATE u_blob SET ref_count = ref_count + 1, updated_at = ` + r.dialect.Now() + `
+		WHERE sha256 = (SELECT content_sha256 FROM u_message WHERE message_id = ?)`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(retainQuery), messageID.String()); err != nil {
+			return nil, fmt.Errorf("failed to retain u_blob: %w", err)
+		}
+	} else {
+		// 古い本文の参照は記録した版に引き継ぎ、メッセージは新しい本文を参照する
+		contentSHA256, _, err := acquireContentBlob(ctx, tx, r.dialect, *req.Content)
+		if err != nil {
+			return nil, err
+		}
+		setClauses = append(setClauses, "content_sha256 = ?")
+		args = append(args, contentSHA256)
+	}
+	if req.ContentType != nil {
+		setClauses = append(setClauses, "content_type = ?")
+		args = append(args, *req.ContentType)
+	}
+	if req.Language != nil {
+		setClauses = append(setClauses, "language = ?")
+		args = append(args, nullString(*req.Language))
+	}
+	if req.Ciphertext != nil {
+		setClauses = append(setClauses, "ciphertext = ?", "nonce = ?", "key_id = ?")
+		args = append(args, req.Ciphertext, req.Nonce, *req.KeyID)
+	}
+
+	args = append(args, messageID.String())
+	query := fmt.Sprintf("UPDATE u_message SET %s WHERE message_id = ?",
+		strings.Join(setClauses, ", "))
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	updatedMessage, err := r.GetMessage(ctx, messageID)
+	if err != nil {
+		if err == model.ErrMessageNotFound {
+			return nil, fmt.Errorf("message not found after successful update: %w", err)
+		}
+		return nil, fmt.Errorf("failed to fetch updated message: %w", err)
+	}
+
+	return updatedMessage, nil
+}
+
+func (r *messageRepository) GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.MessageRevision, error) {
+	query := `SELECT rv.*, b.content FROM u_message_revision rv
+	JOIN u_blob b ON rv.content_sha256 = b.sha256
+	WHERE rv.message_id = ? ORDER BY rv.revision`
+	var revisions []*model.MessageRevision
+	if err := r.db.SelectContext(ctx, &revisions, r.db.Rebind(query), messageID.String()); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *messageRepository) PinnMessage(ctx context.Context, messageID uuid.UUID) error {
+	var message struct {
+		ChannelID uuid.UUID `db:"channel_id"`
+	}
+	// First, get the channel_id from the message
+	err := r.db.GetContext(ctx, &message, r.db.Rebind("SELECT channel_id FROM u_message WHERE message_id = ?"), messageID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.ErrMessageNotFound
+		}
+		return err
+	}
+
+	// Now, insert into u_pinned_message with both message_id and channel_id
+	query := `INSERT INTO u_pinned_message (message_id, channel_id) VALUES (?, ?)`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), messageID.String(), message.ChannelID.String())
+	if err != nil {
+		if isDuplicateKey(err) {
+			return model.ErrMessageAlreadyPinned
+		}
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		// This case should ideally not be reached if the insert succeeds without error
+		return fmt.Errorf("failed to pin message, no rows affected")
+	}
+	return nil
+}
+
+func (r *messageRepository) UnpinnMessage(ctx context.Context, messageID uuid.UUID) error {
+	query := `DELETE FROM u_pinned_message WHERE message_id = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), messageID.String())
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrMessageNotPinned
+	}
+	return nil
+}
+
+// messageBatchSize は deleteMessages が1つのクエリに渡すメッセージ ID の数の上限
+// ユーザーやチャンネルの全てのメッセージを消すときも、SQLite のプレースホルダーの数の上限を超えないようにする
+const messageBatchSize = 500
+
+// deleteMessages はメッセージとその返信を削除し、本文の参照を外す。削除した（返信を除く）メッセージ数を返す
+// 他のメッセージに添付されていない添付ファイルも削除し、本体の参照を外す
+func deleteMessages(ctx context.Context, tx *dbtx.Tx, d Dialect, messageIDs []string) (int64, error) {
+	var deleted int64
+	for start := 0; start < len(messageIDs); start += messageBatchSize {
+		n, err := deleteMessageBatch(ctx, tx, d, messageIDs[start:min(start+messageBatchSize, len(messageIDs))])
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+func deleteMessageBatch(ctx context.Context, tx *dbtx.Tx, d Dialect, messageIDs []string) (int64, error) {
+	attachmentsQuery, args, err := sqlx.In(`SELECT DISTINCT ma.attachment_id FROM u_message_attachment ma
+	JOIN u_message m ON ma.message_id = m.message_id
+	WHERE m.message_id IN (?) OR m.parent_message_id IN (?)`, messageIDs, messageIDs)
+	if err != nil {
+		return 0, err
+	}
+	var attachmentIDs []string
+	if err := tx.SelectContext(ctx, &attachmentIDs, tx.Rebind(attachmentsQuery), args...); err != nil {
+		return 0, err
+	}
+
+	refsQuery, args, err := sqlx.In(`SELECT content_sha256 AS sha256 FROM u_message WHERE message_id IN (?) OR parent_message_id IN (?)
+	UNION ALL
+	SELECT rv.content_sha256 FROM u_message_revision rv JOIN u_message m ON rv.message_id = m.message_id
+	WHERE m.message_id IN (?) OR m.parent_message_id IN (?)`, messageIDs, messageIDs, messageIDs, messageIDs)
+	if err != nil {
+		return 0, err
+	}
+	if err := releaseBlobs(ctx, tx, d, refsQuery, args...); err != nil {
+		return 0, err
+	}
+
+	repliesQuery, args, err := sqlx.In(`DELETE FROM u_message WHERE parent_message_id IN (?)`, messageIDs)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(repliesQuery), args...); err != nil {
+		return 0, fmt.Errorf("failed to delete replies: %w", err)
+	}
+
+	query, args, err := sqlx.In(`DELETE FROM u_message WHERE message_id IN (?)`, messageIDs)
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(attachmentIDs) > 0 {
+		if err := deleteUnlinkedAttachments(ctx, tx, d, attachmentIDs); err != nil {
+			return 0, err
+		}
+	}
+	return rowsAffected, nil
+}
+
+// deleteUnlinkedAttachments は attachmentIDs のうち、どのメッセージにも添付されていないものを削除し、本体の参照を外す
+func deleteUnlinkedAttachments(ctx context.Context, tx *dbtx.Tx, d Dialect, attachmentIDs []string) error {
+	const unlinked = ` WHERE attachment_id IN (?)
+	AND NOT EXISTS (SELECT 1 FROM u_message_attachment ma WHERE ma.attachment_id = u_attachment.attachment_id)`
+	refsQuery, args, err := sqlx.In(`SELECT sha256 FROM u_attachment`+unlinked, attachmentIDs)
+	if err != nil {
+		return err
+	}
+	if err := releaseBlobs(ctx, tx, d, refsQuery, args...); err != nil {
+		return err
+	}
+
+	query, args, err := sqlx.In(`DELETE FROM u_attachment`+unlinked, attachmentIDs)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to delete from u_attachment: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessage はメッセージをゴミ箱に入れる。スレッドの親であれば返信もまとめてゴミ箱に入れる
+// 親と返信には同じ日時を記録し、復元するときにまとめて戻せるようにする
+func (r *messageRepository) DeleteMessage(ctx context.Context, messageID uuid.UUID) error {
+	// 秒までしか保存しないデータベースに合わせ、親と返信で日時がずれないようにする
+	deletedAt := r.dialect.Time(time.Now().UTC().Truncate(time.Second))
+
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// updated_at は編集日時として使うため、削除では変えない
+	query := `UPDATE u_message SET deleted_at = ?, updated_at = updated_at WHERE message_id = ? AND deleted_at IS NULL`
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), deletedAt, messageID.String())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrMessageNotFound
+	}
+
+	repliesQuery := `UPDATE u_message SET deleted_at = ?, updated_at = updated_at WHERE parent_message_id = ? AND deleted_at IS NULL`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(repliesQuery), deletedAt, messageID.String()); err != nil {
+		return fmt.Errorf("failed to delete replies: %w", err)
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RestoreMessage はゴミ箱に入ったメッセージと、一緒にゴミ箱に入った返信を元に戻す
+// 返信を先に個別に削除していた場合、その返信は戻さない
+func (r *messageRepository) RestoreMessage(ctx context.Context, messageID uuid.UUID) error {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	lockQuery := `SELECT deleted_at FROM u_message WHERE message_id = ? AND deleted_at IS NOT NULL` + r.dialect.ForUpdate("u_message")
+	if err := tx.GetContext(ctx, &deletedAt, tx.Rebind(lockQuery), messageID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return model.ErrMessageNotFound
+		}
+		return err
+	}
+
+	query := `UPDATE u_message SET deleted_at = NULL, updated_at = updated_at
+	WHERE message_id = ? OR (parent_message_id = ? AND deleted_at = ?)`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), messageID.String(), messageID.String(), r.dialect.Time(deletedAt)); err != nil {
+		return err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *messageRepository) PurgeDeletedMessages(ctx context.Context, deletedBefore time.Time, limit int) (int, error) {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT message_id FROM u_message
+	WHERE deleted_at < ? ORDER BY deleted_at LIMIT ?` + r.dialect.ForUpdate("u_message")
+	var ids []string
+	if err := tx.SelectContext(ctx, &ids, tx.Rebind(query), r.dialect.Time(deletedBefore), limit); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if _, err := deleteMessages(ctx, tx, r.dialect, ids); err != nil {
+		return 0, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(ids), nil
+}
+
+func (r *messageRepository) BurnMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 同時に読まれた場合は後のリクエストをロックで待たせ、削除済みとして扱う
+	query := selectMessages(r.dialect) + ` WHERE m.message_id = ? AND ` + notDeleted + ` AND ` + notExpired(r.dialect) + r.dialect.ForUpdate("m")
+	message, err := getMessageRow(ctx, tx, tx.Rebind(query), messageID.String())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	if _, err := deleteMessages(ctx, tx, r.dialect, []string{messageID.String()}); err != nil {
+		return nil, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return message, nil
+}
+
+func (r *messageRepository) DeleteExpiredMessages(ctx context.Context, limit int) ([]*model.Message, error) {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT message_id, channel_id FROM u_message
+	WHERE expires_at <= ` + r.dialect.Now() + ` ORDER BY expires_at LIMIT ?` + r.dialect.ForUpdate("u_message")
+	var messages []*model.Message
+	if err := tx.SelectContext(ctx, &messages, tx.Rebind(query), limit); err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return messages, nil
+	}
+
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.MessageID.String())
+	}
+	if _, err := deleteMessages(ctx, tx, r.dialect, ids); err != nil {
+		return nil, err
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return messages, nil
+}
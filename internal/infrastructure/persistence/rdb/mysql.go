@@ -0,0 +1,68 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+)
+
+// mysqlDialect は MySQL / MariaDB の Dialect
+// updated_at は ON UPDATE CURRENT_TIMESTAMP で自動で変わるため、変えない更新では updated_at = updated_at を指定する
+type mysqlDialect struct{}
+
+func (mysqlDialect) Now() string {
+	return "UTC_TIMESTAMP()"
+}
+
+func (mysqlDialect) Time(t time.Time) interface{} {
+	return t
+}
+
+// ForUpdate は結合した全ての表の行をロックする。MariaDB は FOR UPDATE OF に対応していない
+func (mysqlDialect) ForUpdate(string) string {
+	return " FOR UPDATE"
+}
+
+// OnConflict は一意制約を区別しないため conflict を使わない
+// 何もしない場合も INSERT IGNORE は他のエラーまで無視するため、同じ値の代入で代える
+func (mysqlDialect) OnConflict(table string, conflict []string, set string) string {
+	if set == "" {
+		set = table + "." + conflict[0] + " = " + table + "." + conflict[0]
+	}
+	return " ON DUPLICATE KEY UPDATE " + set
+}
+
+func (mysqlDialect) Excluded(column string) string {
+	return "VALUES(" + column + ")"
+}
+
+// FoldCase は照合順序が大文字と小文字を区別しないため、そのまま比べる
+func (mysqlDialect) FoldCase(expr string) string {
+	return expr
+}
+
+// MatchTerms は FULLTEXT インデックスで、検索語を全て含む本文を前方一致で探す
+func (mysqlDialect) MatchTerms(column string, terms []string) (string, []interface{}) {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		parts = append(parts, "+"+term+"*")
+	}
+	return "MATCH(" + column + ") AGAINST(? IN BOOLEAN MODE)", []interface{}{strings.Join(parts, " ")}
+}
+
+// UpsertBlob は ON DUPLICATE KEY UPDATE で既存の行を更新した場合に影響行数が 2 になることで判定する
+func (d mysqlDialect) UpsertBlob(ctx context.Context, tx *dbtx.Tx, hash string, size int64, column string, value interface{}) (bool, error) {
+	query := insertBlob(column) + d.OnConflict("u_blob", []string{"sha256"}, incrementBlob(d, column))
+	result, err := tx.ExecContext(ctx, query, hash, size, value)
+	if err != nil {
+		return false, fmt.Errorf("failed to upsert u_blob: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected == 2, nil
+}
@@ -0,0 +1,64 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+)
+
+// postgresDialect は PostgreSQL の Dialect
+type postgresDialect struct{}
+
+func (postgresDialect) Now() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (postgresDialect) Time(t time.Time) interface{} {
+	return t
+}
+
+// ForUpdate は外部結合した表をロックできないため、table の行だけをロックする
+func (postgresDialect) ForUpdate(table string) string {
+	return " FOR UPDATE OF " + table
+}
+
+func (postgresDialect) OnConflict(_ string, conflict []string, set string) string {
+	target := " ON CONFLICT (" + strings.Join(conflict, ", ") + ")"
+	if set == "" {
+		return target + " DO NOTHING"
+	}
+	return target + " DO UPDATE SET " + set
+}
+
+func (postgresDialect) Excluded(column string) string {
+	return "EXCLUDED." + column
+}
+
+// FoldCase は user_name の一意インデックスと同じく lower() で比べる
+func (postgresDialect) FoldCase(expr string) string {
+	return "lower(" + expr + ")"
+}
+
+// MatchTerms は to_tsquery で、検索語を全て含む本文を前方一致で探す
+// 検索語は引用符で囲み、演算子として解釈されないようにする
+func (postgresDialect) MatchTerms(column string, terms []string) (string, []interface{}) {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted := strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(term)
+		parts = append(parts, "'"+quoted+"':*")
+	}
+	return "to_tsvector('simple', coalesce(" + column + ", '')) @@ to_tsquery('simple', ?)", []interface{}{strings.Join(parts, " & ")}
+}
+
+// UpsertBlob は既存の行を更新した場合に xmax に更新したトランザクションの ID が入ることで判定する
+func (d postgresDialect) UpsertBlob(ctx context.Context, tx *dbtx.Tx, hash string, size int64, column string, value interface{}) (bool, error) {
+	query := insertBlob(column) + d.OnConflict("u_blob", []string{"sha256"}, incrementBlob(d, column)) + ` RETURNING xmax::text <> '0'`
+	var existed bool
+	if err := tx.GetContext(ctx, &existed, tx.Rebind(query), hash, size, value); err != nil {
+		return false, fmt.Errorf("failed to upsert u_blob: %w", err)
+	}
+	return existed, nil
+}
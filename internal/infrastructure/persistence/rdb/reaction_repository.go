@@ -0,0 +1,81 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type reactionRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewReactionRepository(db *sqlx.DB, dialect Dialect) repository.ReactionRepository {
+	return &reactionRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *reactionRepository) AddReaction(ctx context.Context, reaction *model.Reaction) error {
+	query := `INSERT INTO u_message_reaction (message_id, user_id, emoji) VALUES (?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), reaction.MessageID.String(), reaction.UserID.String(), reaction.Emoji)
+	if err != nil {
+		switch {
+		case isDuplicateKey(err):
+			return model.ErrAlreadyReacted
+		case isForeignKeyViolation(err):
+			return model.ErrMessageNotFound
+		}
+		return fmt.Errorf("failed to insert into u_message_reaction: %w", err)
+	}
+	return nil
+}
+
+func (r *reactionRepository) RemoveReaction(ctx context.Context, messageID uuid.UUID, userID uuid.UUID, emoji string) error {
+	query := `DELETE FROM u_message_reaction WHERE message_id = ? AND user_id = ? AND emoji = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), messageID.String(), userID.String(), emoji)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrReactionNotFound
+	}
+	return nil
+}
+
+func (r *reactionRepository) GetReactionSummaries(ctx context.Context, messageIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID][]*model.ReactionSummary, error) {
+	summaries := make(map[uuid.UUID][]*model.ReactionSummary, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return summaries, nil
+	}
+
+	ids := make([]string, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		ids = append(ids, id.String())
+	}
+
+	// 絵文字は最初にリアクションされた順に並べる
+	query, args, err := sqlx.In(`SELECT message_id, emoji, COUNT(*) AS count, COUNT(CASE WHEN user_id = ? THEN 1 END) > 0 AS reacted
+	FROM u_message_reaction WHERE message_id IN (?)
+	GROUP BY message_id, emoji ORDER BY MIN(created_at), emoji`, viewerID.String(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*model.ReactionSummary
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		summaries[row.MessageID] = append(summaries[row.MessageID], row)
+	}
+	return summaries, nil
+}
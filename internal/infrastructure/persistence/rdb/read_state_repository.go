@@ -0,0 +1,98 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// unreadMessages は既読の位置より後の他人のメッセージに絞る条件
+// u_channel には c、u_channel_member には cm、u_channel_read_state には s という別名が付く
+// 既読の位置がなければ参加した日時から数える。チャンネルの全てのメッセージは数えず、
+// u_message の idx_channel_created_message を位置から範囲で読む
+func unreadMessages(d Dialect) string {
+	return `m.channel_id = c.channel_id AND m.user_id <> ? AND ` + notDeleted + ` AND ` + notExpired(d) + ` AND ` + unreadPosition
+}
+
+// unreadPosition はメッセージ m が既読の位置より後にある条件。位置がなければ NULL になる
+const unreadPosition = `m.created_at >= COALESCE(s.last_read_at, cm.created_at)
+	AND (m.created_at > COALESCE(s.last_read_at, cm.created_at) OR m.message_id > COALESCE(s.last_read_message_id, '00000000-0000-0000-0000-000000000000'))`
+
+type readStateRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewReadStateRepository(db *sqlx.DB, dialect Dialect) repository.ReadStateRepository {
+	return &readStateRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *readStateRepository) MarkRead(ctx context.Context, state *model.ChannelReadState) (*model.ChannelReadState, error) {
+	// 既読の位置は先へ進めるだけで、古いメッセージを読んでも戻さない
+	// MySQL は代入を左から順に行うため、位置を比べる列を最後に代入する
+	d := r.dialect
+	advance := `(` + d.Excluded("last_read_at") + ` > u_channel_read_state.last_read_at
+		OR (` + d.Excluded("last_read_at") + ` = u_channel_read_state.last_read_at
+			AND ` + d.Excluded("last_read_message_id") + ` > u_channel_read_state.last_read_message_id))`
+	set := `updated_at = CASE WHEN ` + advance + ` THEN ` + d.Now() + ` ELSE u_channel_read_state.updated_at END,
+		last_read_message_id = CASE WHEN ` + advance + ` THEN ` + d.Excluded("last_read_message_id") + ` ELSE u_channel_read_state.last_read_message_id END,
+		last_read_at = CASE WHEN ` + advance + ` THEN ` + d.Excluded("last_read_at") + ` ELSE u_channel_read_state.last_read_at END`
+	query := `INSERT INTO u_channel_read_state (user_id, channel_id, last_read_message_id, last_read_at) VALUES (?, ?, ?, ?)` +
+		d.OnConflict("u_channel_read_state", []string{"user_id", "channel_id"}, set)
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), state.UserID.String(), state.ChannelID.String(), state.LastReadMessageID.String(), d.Time(state.LastReadAt))
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, model.ErrChannelNotFound
+		}
+		return nil, fmt.Errorf("failed to upsert u_channel_read_state: %w", err)
+	}
+
+	var marked model.ChannelReadState
+	query = `SELECT * FROM u_channel_read_state WHERE user_id = ? AND channel_id = ?`
+	if err := r.db.GetContext(ctx, &marked, r.db.Rebind(query), state.UserID.String(), state.ChannelID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("read state not found after successful upsert: %w", model.ErrChannelNotFound)
+		}
+		return nil, fmt.Errorf("failed to fetch read state: %w", err)
+	}
+	return &marked, nil
+}
+
+func (r *readStateRepository) GetUnreadCounts(ctx context.Context, userID uuid.UUID) ([]*model.ChannelUnread, error) {
+	var exists bool
+	if err := r.db.GetContext(ctx, &exists, r.db.Rebind(`SELECT EXISTS (SELECT 1 FROM u_user WHERE user_id = ?)`), userID.String()); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.ErrUserNotFound
+	}
+
+	query := `SELECT c.channel_id, c.channel_name, s.last_read_message_id, s.last_read_at,
+		(SELECT COUNT(*) FROM u_message m WHERE ` + unreadMessages(r.dialect) + `) AS unread_count,
+		(SELECT COUNT(*) FROM u_mention mn JOIN u_message m ON m.message_id = mn.message_id
+			WHERE mn.user_id = ? AND mn.channel_id = c.channel_id AND ` + unreadMessages(r.dialect) + `) AS mention_count
+	FROM u_channel c
+	LEFT JOIN u_channel_member cm ON cm.channel_id = c.channel_id AND cm.user_id = ?
+	LEFT JOIN u_channel_read_state s ON s.channel_id = c.channel_id AND s.user_id = ?
+	WHERE c.deleted_at IS NULL AND (cm.user_id IS NOT NULL OR (s.user_id IS NOT NULL AND c.visibility = ?))
+	ORDER BY c.channel_name`
+
+	var unread []*model.ChannelUnread
+	err := r.db.SelectContext(ctx, &unread, r.db.Rebind(query),
+		userID.String(),
+		userID.String(), userID.String(),
+		userID.String(),
+		userID.String(),
+		model.ChannelVisibilityPublic,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return unread, nil
+}
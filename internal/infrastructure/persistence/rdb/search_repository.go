@@ -0,0 +1,78 @@
+package rdb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/jmoiron/sqlx"
+)
+
+type searchRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewSearchRepository(db *sqlx.DB, dialect Dialect) repository.SearchRepository {
+	return &searchRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *searchRepository) SearchMessages(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error) {
+	match, args := r.dialect.MatchTerms("b.content", query.Terms)
+	whereClauses := []string{
+		match,
+		notDeleted,
+		notExpired(r.dialect),
+		"c.deleted_at IS NULL",
+		// 一度きりのメッセージはスニペットから本文が漏れるため検索対象にしない
+		"m.burn_after_read = FALSE",
+		"m.encrypted = FALSE",
+		`(c.visibility = ? OR EXISTS (SELECT 1 FROM u_channel_member cm WHERE cm.channel_id = c.channel_id AND cm.user_id = ?))`,
+	}
+	args = append(args, model.ChannelVisibilityPublic, query.ViewerID.String())
+
+	if !query.ChannelID.IsNil() {
+		whereClauses = append(whereClauses, "m.channel_id = ?")
+		args = append(args, query.ChannelID.String())
+	}
+	if !query.UserID.IsNil() {
+		whereClauses = append(whereClauses, "m.user_id = ?")
+		args = append(args, query.UserID.String())
+	}
+	if query.From != nil {
+		whereClauses = append(whereClauses, "m.created_at >= ?")
+		args = append(args, r.dialect.Time(*query.From))
+	}
+	if query.To != nil {
+		whereClauses = append(whereClauses, "m.created_at <= ?")
+		args = append(args, r.dialect.Time(*query.To))
+	}
+	if query.Before != nil {
+		whereClauses = append(whereClauses, "(m.created_at < ? OR (m.created_at = ? AND m.message_id < ?))")
+		args = append(args, r.dialect.Time(query.Before.CreatedAt), r.dialect.Time(query.Before.CreatedAt), query.Before.MessageID.String())
+	}
+
+	// 続きがあるかを判定するために1件多く取得する
+	args = append(args, query.Limit+1)
+	sqlQuery := selectMessages(r.dialect) + `
+	JOIN u_channel c ON m.channel_id = c.channel_id
+	WHERE ` + strings.Join(whereClauses, " AND ") + `
+	ORDER BY m.created_at DESC, m.message_id DESC LIMIT ?`
+
+	messages, err := selectMessageRows(ctx, r.db, r.db.Rebind(sqlQuery), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.SearchPage{Results: make([]*model.SearchResult, 0, len(messages))}
+	if len(messages) > query.Limit {
+		messages = messages[:query.Limit]
+		page.NextCursor = model.CursorOf(messages[len(messages)-1])
+	}
+	for _, message := range messages {
+		page.Results = append(page.Results, &model.SearchResult{Message: message})
+	}
+	return page, nil
+}
@@ -0,0 +1,57 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/jmoiron/sqlx"
+)
+
+type sessionRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewSessionRepository(db *sqlx.DB, dialect Dialect) repository.SessionRepository {
+	return &sessionRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *sessionRepository) CreateSession(ctx context.Context, session *model.Session) error {
+	query := `INSERT INTO u_session (token_hash, user_id, expires_at) VALUES (?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), session.TokenHash, session.UserID.String(), r.dialect.Time(session.ExpiresAt)); err != nil {
+		return fmt.Errorf("failed to insert into u_session: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionRepository) GetSession(ctx context.Context, tokenHash string) (*model.Session, error) {
+	query := `SELECT * FROM u_session WHERE token_hash = ?`
+	var session model.Session
+	if err := r.db.GetContext(ctx, &session, r.db.Rebind(query), tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrUnauthorized
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	query := `DELETE FROM u_session WHERE token_hash = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), tokenHash)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrUnauthorized
+	}
+	return nil
+}
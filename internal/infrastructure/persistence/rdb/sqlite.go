@@ -0,0 +1,71 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+)
+
+// sqliteTimeFormat は SQLite に日時を保存する形式。文字列のまま比較しても順序が正しくなるよう、UTC で桁数を揃える
+const sqliteTimeFormat = "2006-01-02 15:04:05.000"
+
+// sqliteDialect は SQLite の Dialect
+// トランザクションは始めた時点で書き込みロックを取るため、行のロックは要らない
+type sqliteDialect struct{}
+
+// Now は sqliteTimeFormat と同じ形式で現在日時を返す。列の DEFAULT と同じもの
+func (sqliteDialect) Now() string {
+	return "strftime('%Y-%m-%d %H:%M:%f', 'now')"
+}
+
+func (sqliteDialect) Time(t time.Time) interface{} {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+func (sqliteDialect) ForUpdate(string) string {
+	return ""
+}
+
+func (sqliteDialect) OnConflict(_ string, conflict []string, set string) string {
+	target := " ON CONFLICT (" + strings.Join(conflict, ", ") + ")"
+	if set == "" {
+		return target + " DO NOTHING"
+	}
+	return target + " DO UPDATE SET " + set
+}
+
+func (sqliteDialect) Excluded(column string) string {
+	return "excluded." + column
+}
+
+// FoldCase は user_name が COLLATE NOCASE のため、そのまま比べる
+func (sqliteDialect) FoldCase(expr string) string {
+	return expr
+}
+
+// MatchTerms は全文検索のインデックスがないため、検索語をそれぞれ LIKE で部分一致させる
+func (sqliteDialect) MatchTerms(column string, terms []string) (string, []interface{}) {
+	clauses := make([]string, 0, len(terms))
+	args := make([]interface{}, 0, len(terms))
+	for _, term := range terms {
+		clauses = append(clauses, column+` LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)+"%")
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// UpsertBlob は先に行の有無を確かめる。書き込みロックを持っているため、upsert までの間に他から作られることはない
+func (d sqliteDialect) UpsertBlob(ctx context.Context, tx *dbtx.Tx, hash string, size int64, column string, value interface{}) (bool, error) {
+	var existed bool
+	if err := tx.GetContext(ctx, &existed, `SELECT EXISTS (SELECT 1 FROM u_blob WHERE sha256 = ?)`, hash); err != nil {
+		return false, fmt.Errorf("failed to fetch u_blob: %w", err)
+	}
+	query := insertBlob(column) + d.OnConflict("u_blob", []string{"sha256"}, incrementBlob(d, column))
+	if _, err := tx.ExecContext(ctx, query, hash, size, value); err != nil {
+		return false, fmt.Errorf("failed to upsert u_blob: %w", err)
+	}
+	return existed, nil
+}
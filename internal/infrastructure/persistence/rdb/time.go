@@ -0,0 +1,45 @@
+package rdb
+
+import (
+	"fmt"
+	"time"
+)
+
+// exprTimeLayout は exprTime が文字列を読む形式。小数点以下の秒は桁数によらず読める
+const exprTimeLayout = "2006-01-02 15:04:05"
+
+// exprTime は式の結果の日時を読む
+// SQLite では DATETIME 型の列と違い、式の値はドライバーが日時に変換せず文字列のまま返す
+type exprTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (t *exprTime) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = exprTime{}
+		return nil
+	case time.Time:
+		*t = exprTime{Time: v, Valid: true}
+		return nil
+	case string:
+		parsed, err := time.Parse(exprTimeLayout, v)
+		if err != nil {
+			return fmt.Errorf("failed to parse time %q: %w", v, err)
+		}
+		*t = exprTime{Time: parsed, Valid: true}
+		return nil
+	case []byte:
+		return t.Scan(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into time", src)
+	}
+}
+
+func (t exprTime) Ptr() *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
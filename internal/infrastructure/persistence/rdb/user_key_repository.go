@@ -0,0 +1,70 @@
+package rdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
+	"github.com/gofrs/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type userKeyRepository struct {
+	db      *dbtx.DB
+	dialect Dialect
+}
+
+func NewUserKeyRepository(db *sqlx.DB, dialect Dialect) repository.UserKeyRepository {
+	return &userKeyRepository{db: dbtx.New(db), dialect: dialect}
+}
+
+func (r *userKeyRepository) CreateUserKey(ctx context.Context, key *model.UserKey) (*model.UserKey, error) {
+	query := `INSERT INTO u_user_key (key_id, user_id, algorithm, public_key, fingerprint) VALUES (?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), key.KeyID.String(), key.UserID.String(), key.Algorithm, key.PublicKey, key.Fingerprint)
+	if err != nil {
+		switch {
+		case isDuplicateKey(err):
+			return nil, model.ErrUserKeyAlreadyExists
+		case isForeignKeyViolation(err):
+			return nil, model.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to insert into u_user_key: %w", err)
+	}
+
+	var created model.UserKey
+	if err := r.db.GetContext(ctx, &created, r.db.Rebind(`SELECT * FROM u_user_key WHERE key_id = ?`), key.KeyID.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user key not found after successful insert: %w", model.ErrUserKeyNotFound)
+		}
+		return nil, fmt.Errorf("failed to fetch created user key: %w", err)
+	}
+	return &created, nil
+}
+
+func (r *userKeyRepository) GetUserKeys(ctx context.Context, userID uuid.UUID) ([]*model.UserKey, error) {
+	query := `SELECT * FROM u_user_key WHERE user_id = ? ORDER BY created_at, key_id`
+	var keys []*model.UserKey
+	if err := r.db.SelectContext(ctx, &keys, r.db.Rebind(query), userID.String()); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *userKeyRepository) DeleteUserKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	query := `DELETE FROM u_user_key WHERE user_id = ? AND key_id = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), userID.String(), keyID.String())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return model.ErrUserKeyNotFound
+	}
+	return nil
+}
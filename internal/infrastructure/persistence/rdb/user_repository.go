@@ -1,4 +1,4 @@
-package mysql
+package rdb
 
 import (
 	"context"
@@ -8,20 +8,24 @@ import (
 
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
-	"github.com/go-sql-driver/mysql"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/dbtx"
 	"github.com/gofrs/uuid"
 	"github.com/jmoiron/sqlx"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type userRepository struct {
-	db *sqlx.DB
+	db      *dbtx.DB
+	dialect Dialect
 }
 
-func NewUserRepository(db *sqlx.DB) repository.UserRepository {
-	return &userRepository{db: db}
+func NewUserRepository(db *sqlx.DB, dialect Dialect) repository.UserRepository {
+	return &userRepository{db: dbtx.New(db), dialect: dialect}
 }
 
+// dummyPasswordHash は存在しないユーザーのログイン時に比較対象として使うハッシュ
+const dummyPasswordHash = "$2a$10$ZlZHjACgXYAUORuwbRppgeP3udPG21Np8MY42LfZa.2tNAB7Rpcjq"
+
 func hashPassword(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -49,16 +53,16 @@ func (r *userRepository) CreateUser(ctx context.Context, req *model.RequestCreat
 	defer tx.Rollback()
 
 	userQuery := `INSERT INTO u_user (user_id, user_name, nickname, status) VALUES (?, ?, ?, ?)`
-	_, err = tx.ExecContext(ctx, userQuery, userID.String(), req.UserName, req.Nickname, req.Status)
+	_, err = tx.ExecContext(ctx, tx.Rebind(userQuery), userID.String(), req.UserName, req.Nickname, req.Status)
 	if err != nil {
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+		if isDuplicateKey(err) {
 			return nil, model.ErrAlreadyExistUserName
 		}
 		return nil, fmt.Errorf("failed to insert into u_user: %w", err)
 	}
 
 	privateQuery := `INSERT INTO u_user_private (user_id, password_hash) VALUES (?, ?)`
-	_, err = tx.ExecContext(ctx, privateQuery, userID.String(), string(hashedPassword))
+	_, err = tx.ExecContext(ctx, tx.Rebind(privateQuery), userID.String(), hashedPassword)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert into u_user_private: %w", err)
 	}
@@ -68,26 +72,17 @@ func (r *userRepository) CreateUser(ctx context.Context, req *model.RequestCreat
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	var createdUser model.User
-	selectQuery := `SELECT * FROM u_user WHERE user_id = ?`
-
-	if err := r.db.GetContext(ctx, &createdUser, selectQuery, userID.String()); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found after successful insert: %w", err)
-		}
+	createdUser, err := r.GetUserByID(ctx, userID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to fetch created user: %w", err)
 	}
-
-	return &createdUser, nil
+	return createdUser, nil
 }
 
 func (r *userRepository) GetUsers(ctx context.Context) ([]*model.User, error) {
 	query := `SELECT * FROM u_user`
 	var users []*model.User
 	if err := r.db.SelectContext(ctx, &users, query); err != nil {
-		if err == sql.ErrNoRows {
-			return []*model.User{}, nil
-		}
 		return nil, err
 	}
 	return users, nil
@@ -96,7 +91,7 @@ func (r *userRepository) GetUsers(ctx context.Context) ([]*model.User, error) {
 func (r *userRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*model.User, error) {
 	query := `SELECT * FROM u_user WHERE user_id = ?`
 	var user model.User
-	if err := r.db.GetContext(ctx, &user, query, userID.String()); err != nil {
+	if err := r.db.GetContext(ctx, &user, r.db.Rebind(query), userID.String()); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, model.ErrUserNotFound
 		}
@@ -105,18 +100,39 @@ func (r *userRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*mo
 	return &user, nil
 }
 
+func (r *userRepository) VerifyPassword(ctx context.Context, userName string, password string) (*model.User, error) {
+	var row struct {
+		model.User
+		PasswordHash string `db:"password_hash"`
+	}
+	// 一意性と同じく大文字小文字を区別せずに照合する
+	query := `SELECT u.*, p.password_hash FROM u_user u
+	JOIN u_user_private p ON u.user_id = p.user_id
+	WHERE ` + r.dialect.FoldCase("u.user_name") + ` = ?`
+	if err := r.db.GetContext(ctx, &row, r.db.Rebind(query), strings.ToLower(userName)); err != nil {
+		if err == sql.ErrNoRows {
+			// ユーザーの有無を応答時間から推測されないよう、存在しない場合も比較を行う
+			bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+			return nil, model.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to fetch user credentials: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(row.PasswordHash), []byte(password)); err != nil {
+		return nil, model.ErrInvalidCredentials
+	}
+
+	return &row.User, nil
+}
+
 func (r *userRepository) PatchUser(ctx context.Context, userID uuid.UUID, req *model.RequestPatchUser) (*model.User, error) {
-	setClauses := []string{}
+	setClauses := []string{"updated_at = " + r.dialect.Now()}
 	args := []interface{}{}
 
 	if req.UserName != nil {
 		setClauses = append(setClauses, "user_name = ?")
 		args = append(args, *req.UserName)
 	}
-	if req.Email != nil {
-		setClauses = append(setClauses, "email = ?")
-		args = append(args, *req.Email)
-	}
 	if req.Nickname != nil {
 		setClauses = append(setClauses, "nickname = ?")
 		args = append(args, *req.Nickname)
@@ -126,16 +142,23 @@ func (r *userRepository) PatchUser(ctx context.Context, userID uuid.UUID, req *m
 		args = append(args, *req.Status)
 	}
 
-	if len(setClauses) == 0 {
+	if len(setClauses) == 1 && req.Email == nil {
 		return r.GetUserByID(ctx, userID)
 	}
 
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	args = append(args, userID.String())
 	query := "UPDATE u_user SET " + strings.Join(setClauses, ", ") + " WHERE user_id = ?"
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), args...)
 	if err != nil {
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+		if isDuplicateKey(err) {
 			return nil, model.ErrAlreadyExistUserName
 		}
 		return nil, fmt.Errorf("failed to update user: %w", err)
@@ -149,13 +172,29 @@ func (r *userRepository) PatchUser(ctx context.Context, userID uuid.UUID, req *m
 		return nil, model.ErrUserNotFound
 	}
 
+	// メールアドレスは u_user_private に持つ。空にした場合は未設定に戻す
+	if req.Email != nil {
+		emailQuery := `UPDATE u_user_private SET email = ?, updated_at = ` + r.dialect.Now() + ` WHERE user_id = ?`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(emailQuery), nullString(*req.Email), userID.String()); err != nil {
+			if isDuplicateKey(err) {
+				return nil, model.ErrAlreadyExistEmail
+			}
+			return nil, fmt.Errorf("failed to update email: %w", err)
+		}
+	}
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return r.GetUserByID(ctx, userID)
 }
 
 func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, req *model.RequestChangePassword) error {
 	var storedHash string
 	query := `SELECT password_hash FROM u_user_private WHERE user_id = ?`
-	err := r.db.GetContext(ctx, &storedHash, query, userID.String())
+	err := r.db.GetContext(ctx, &storedHash, r.db.Rebind(query), userID.String())
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return model.ErrUserNotFound
@@ -165,7 +204,7 @@ func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, r
 
 	// Verify old password
 	if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(req.OldPassword)); err != nil {
-		return fmt.Errorf("old password does not match: %w", err)
+		return fmt.Errorf("old password does not match: %w", model.ErrInvalidCredentials)
 	}
 
 	// Hash new password
@@ -174,8 +213,8 @@ func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, r
 		return err
 	}
 
-	updateQuery := `UPDATE u_user_private SET password_hash = ? WHERE user_id = ?`
-	result, err := r.db.ExecContext(ctx, updateQuery, newHashedPassword, userID.String())
+	updateQuery := `UPDATE u_user_private SET password_hash = ?, updated_at = ` + r.dialect.Now() + ` WHERE user_id = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(updateQuery), newHashedPassword, userID.String())
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
@@ -192,8 +231,31 @@ func (r *userRepository) ChangePassword(ctx context.Context, userID uuid.UUID, r
 }
 
 func (r *userRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error {
+	// begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// メッセージ（他人の返信を含む）は CASCADE に任せず、本文と添付ファイルの参照を外しながら先に削除する
+	var messageIDs []string
+	if err := tx.SelectContext(ctx, &messageIDs, tx.Rebind(`SELECT message_id FROM u_message WHERE user_id = ?`), userID.String()); err != nil {
+		return err
+	}
+	if len(messageIDs) > 0 {
+		if _, err := deleteMessages(ctx, tx, r.dialect, messageIDs); err != nil {
+			return err
+		}
+	}
+
+	// 残った自分の添付ファイルは CASCADE で消えるため、先に本体の参照を外しておく
+	if err := releaseBlobs(ctx, tx, r.dialect, `SELECT sha256 FROM u_attachment WHERE owner_id = ?`, userID.String()); err != nil {
+		return err
+	}
+
 	query := `DELETE FROM u_user WHERE user_id = ?`
-	result, err := r.db.ExecContext(ctx, query, userID.String())
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), userID.String())
 	if err != nil {
 		return err
 	}
@@ -204,5 +266,10 @@ func (r *userRepository) DeleteUser(ctx context.Context, userID uuid.UUID) error
 	if rowsAffected == 0 {
 		return model.ErrUserNotFound
 	}
-	return err
+
+	// commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
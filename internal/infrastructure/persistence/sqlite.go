@@ -0,0 +1,19 @@
+package persistence
+
+import (
+	"net/url"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/util"
+)
+
+// SQLite は DB_PATH のファイルを開く DSN を返す。":memory:" ならメモリ上に作る
+// トランザクションは始めた時点で書き込みロックを取り、MySQL の SELECT ... FOR UPDATE の代わりにする
+func SQLite() string {
+	q := url.Values{}
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Set("_txlock", "immediate")
+
+	return "file:" + util.GetEnvOrDefault("DB_PATH", "clipboard.db") + "?" + q.Encode()
+}
@@ -0,0 +1,54 @@
+// Package sqlite は SQLite のリポジトリを作成する
+// SQL は rdb のものを共有し、Dialect だけを SQLite に固定する
+// DB_DRIVER で切り替える場合は persistence.NewRepositories を使う
+package sqlite
+
+import (
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/rdb"
+	"github.com/jmoiron/sqlx"
+)
+
+func NewUserRepository(db *sqlx.DB) repository.UserRepository {
+	return rdb.NewUserRepository(db, rdb.SQLite)
+}
+
+func NewChannelRepository(db *sqlx.DB) repository.ChannelRepository {
+	return rdb.NewChannelRepository(db, rdb.SQLite)
+}
+
+func NewMessageRepository(db *sqlx.DB) repository.MessageRepository {
+	return rdb.NewMessageRepository(db, rdb.SQLite)
+}
+
+func NewSessionRepository(db *sqlx.DB) repository.SessionRepository {
+	return rdb.NewSessionRepository(db, rdb.SQLite)
+}
+
+func NewSearchRepository(db *sqlx.DB) repository.SearchRepository {
+	return rdb.NewSearchRepository(db, rdb.SQLite)
+}
+
+func NewReactionRepository(db *sqlx.DB) repository.ReactionRepository {
+	return rdb.NewReactionRepository(db, rdb.SQLite)
+}
+
+func NewAttachmentRepository(db *sqlx.DB) repository.AttachmentRepository {
+	return rdb.NewAttachmentRepository(db, rdb.SQLite)
+}
+
+func NewBlobRepository(db *sqlx.DB) repository.BlobRepository {
+	return rdb.NewBlobRepository(db, rdb.SQLite)
+}
+
+func NewUserKeyRepository(db *sqlx.DB) repository.UserKeyRepository {
+	return rdb.NewUserKeyRepository(db, rdb.SQLite)
+}
+
+func NewReadStateRepository(db *sqlx.DB) repository.ReadStateRepository {
+	return rdb.NewReadStateRepository(db, rdb.SQLite)
+}
+
+func NewMentionRepository(db *sqlx.DB) repository.MentionRepository {
+	return rdb.NewMentionRepository(db, rdb.SQLite)
+}
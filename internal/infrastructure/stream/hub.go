@@ -0,0 +1,229 @@
+package stream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/event"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// DefaultBufferSize は購読者ごとの送信バッファの既定サイズ
+	DefaultBufferSize = 64
+	// DefaultHistorySize は再接続時の再送用にチャンネルごとに保持するイベント数の既定値
+	DefaultHistorySize = 256
+	// HistoryTTL は購読者がおらずイベントも発行されないチャンネルの再送用イベントを保持する期間
+	// 削除されたチャンネルの分もこの期間が過ぎると捨てる
+	HistoryTTL = 10 * time.Minute
+	// maxHistories は再送用イベントを保持するチャンネル数の上限
+	maxHistories = 10000
+)
+
+// Hub はチャンネル単位でイベントを購読者へファンアウトするプロセス内ハブ
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[*Subscription]struct{}
+	history     map[uuid.UUID]*ring
+	bufferSize  int
+	historySize int
+	// 最後に発行したイベントID
+	// 再起動前のIDと衝突しないよう起動時刻から採番する
+	seq uint64
+	// Close 後は購読を受け付けない
+	closed bool
+}
+
+var _ event.Publisher = (*Hub)(nil)
+
+func NewHub(bufferSize, historySize int) *Hub {
+	if bufferSize < 1 {
+		bufferSize = DefaultBufferSize
+	}
+	if historySize < 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Hub{
+		subscribers: make(map[uuid.UUID]map[*Subscription]struct{}),
+		history:     make(map[uuid.UUID]*ring),
+		bufferSize:  bufferSize,
+		historySize: historySize,
+		seq:         uint64(time.Now().UnixMicro()),
+	}
+}
+
+// Subscription は1クライアント分の購読
+// バッファが溢れた購読者はハブから切り離され、Events() がクローズされる
+type Subscription struct {
+	hub       *Hub
+	channelID uuid.UUID
+	send      chan *model.Event
+	dropped   bool
+}
+
+func (s *Subscription) Events() <-chan *model.Event {
+	return s.send
+}
+
+// Dropped は送信が追いつかずにハブから切り離されたかどうかを返す
+func (s *Subscription) Dropped() bool {
+	s.hub.mu.RLock()
+	defer s.hub.mu.RUnlock()
+	return s.dropped
+}
+
+// Close は購読を解除する。複数回呼んでも安全
+func (s *Subscription) Close() {
+	s.hub.remove(s, false)
+}
+
+func (h *Hub) Subscribe(channelID uuid.UUID) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.subscribe(channelID)
+}
+
+// SubscribeSince は購読を開始し、lastID より後に発行された保持中のイベントを返す
+// 購読開始と再送対象の取得は同じロック内で行うため、取りこぼしも重複も起きない
+func (h *Hub) SubscribeSince(channelID uuid.UUID, lastID uint64) (*Subscription, []*model.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []*model.Event
+	if r, ok := h.history[channelID]; ok {
+		replay = r.since(lastID)
+	}
+	return h.subscribe(channelID), replay
+}
+
+func (h *Hub) subscribe(channelID uuid.UUID) *Subscription {
+	sub := &Subscription{
+		hub:       h,
+		channelID: channelID,
+		send:      make(chan *model.Event, h.bufferSize),
+	}
+	if h.closed {
+		close(sub.send)
+		return sub
+	}
+
+	subs, ok := h.subscribers[channelID]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		h.subscribers[channelID] = subs
+	}
+	subs[sub] = struct{}{}
+
+	return sub
+}
+
+// Publish はイベントにIDを採番し、チャンネルの全購読者へ送る。呼び出し元をブロックしない
+func (h *Hub) Publish(e *model.Event) {
+	var slow []*Subscription
+
+	h.mu.Lock()
+	h.seq++
+	e.ID = h.seq
+
+	now := time.Now()
+	r, ok := h.history[e.ChannelID]
+	if !ok {
+		if len(h.history) >= maxHistories {
+			h.evictOldestHistory()
+		}
+		r = newRing(h.historySize)
+		h.history[e.ChannelID] = r
+	}
+	r.push(e, now)
+
+	for sub := range h.subscribers[e.ChannelID] {
+		select {
+		case sub.send <- e:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, sub := range slow {
+		h.remove(sub, true)
+	}
+}
+
+// EvictIdleHistory は購読者がおらず HistoryTTL の間イベントが発行されていないチャンネルの
+// 再送用イベントを捨て、捨てたチャンネル数を返す。定期的に呼び出す
+func (h *Hub) EvictIdleHistory() int {
+	return h.evictIdleHistory(time.Now().Add(-HistoryTTL))
+}
+
+func (h *Hub) evictIdleHistory(pushedBefore time.Time) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	evicted := 0
+	for channelID, r := range h.history {
+		if _, ok := h.subscribers[channelID]; ok {
+			continue
+		}
+		if r.pushedAt.Before(pushedBefore) {
+			delete(h.history, channelID)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// evictOldestHistory は購読者のいないチャンネルのうち、最後にイベントが発行されたのが最も古いものの再送用イベントを捨てる
+// 全てのチャンネルに購読者がいる場合は何もしない
+func (h *Hub) evictOldestHistory() {
+	var (
+		oldestID uuid.UUID
+		oldest   *ring
+	)
+	for channelID, r := range h.history {
+		if _, ok := h.subscribers[channelID]; ok {
+			continue
+		}
+		if oldest == nil || r.pushedAt.Before(oldest.pushedAt) {
+			oldestID, oldest = channelID, r
+		}
+	}
+	if oldest != nil {
+		delete(h.history, oldestID)
+	}
+}
+
+// Close は全ての購読を解除し、以降の購読をすぐにクローズする
+// サーバー停止時に配信中のハンドラを終わらせるために使う
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for channelID, subs := range h.subscribers {
+		for sub := range subs {
+			close(sub.send)
+		}
+		delete(h.subscribers, channelID)
+	}
+}
+
+func (h *Hub) remove(sub *Subscription, dropped bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subscribers[sub.channelID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.channelID)
+	}
+	sub.dropped = dropped
+	close(sub.send)
+}
@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/gofrs/uuid"
+)
+
+func publish(h *Hub, channelID uuid.UUID) {
+	h.Publish(&model.Event{ChannelID: channelID})
+}
+
+func TestEvictIdleHistory(t *testing.T) {
+	h := NewHub(DefaultBufferSize, DefaultHistorySize)
+	idle := uuid.Must(uuid.NewV4())
+	subscribed := uuid.Must(uuid.NewV4())
+	publish(h, idle)
+	publish(h, subscribed)
+	sub := h.Subscribe(subscribed)
+
+	// 直近に発行したチャンネルは残す
+	if evicted := h.EvictIdleHistory(); evicted != 0 {
+		t.Errorf("EvictIdleHistory() = %d, want 0", evicted)
+	}
+
+	// 購読者のいるチャンネルは古くても残す
+	if evicted := h.evictIdleHistory(time.Now().Add(time.Second)); evicted != 1 {
+		t.Errorf("evictIdleHistory() = %d, want 1", evicted)
+	}
+	if _, ok := h.history[idle]; ok {
+		t.Errorf("history of the idle channel was kept")
+	}
+	if _, ok := h.history[subscribed]; !ok {
+		t.Errorf("history of the subscribed channel was evicted")
+	}
+
+	// 購読をやめれば捨てる
+	sub.Close()
+	if evicted := h.evictIdleHistory(time.Now().Add(time.Second)); evicted != 1 {
+		t.Errorf("evictIdleHistory() after Close = %d, want 1", evicted)
+	}
+}
+
+// 上限に達したら、購読者のいないチャンネルのうち最も古いものを捨てる
+func TestHistoryIsCapped(t *testing.T) {
+	h := NewHub(DefaultBufferSize, 1)
+	first := uuid.Must(uuid.NewV4())
+	second := uuid.Must(uuid.NewV4())
+	publish(h, first)
+	sub := h.Subscribe(first)
+	defer sub.Close()
+	publish(h, second)
+	// 後から発行したチャンネルと時刻が重ならないようにする
+	time.Sleep(time.Millisecond)
+	for len(h.history) < maxHistories {
+		publish(h, uuid.Must(uuid.NewV4()))
+	}
+
+	publish(h, uuid.Must(uuid.NewV4()))
+	if len(h.history) != maxHistories {
+		t.Errorf("len(history) = %d, want %d", len(h.history), maxHistories)
+	}
+	if _, ok := h.history[first]; !ok {
+		t.Errorf("history of the subscribed channel was evicted")
+	}
+	if _, ok := h.history[second]; ok {
+		t.Errorf("history of the oldest idle channel was kept")
+	}
+}
@@ -0,0 +1,46 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// ring は直近のイベントを保持する固定長のリングバッファ
+type ring struct {
+	events []*model.Event
+	start  int
+	size   int
+	// 最後にイベントを追加した時刻。使われなくなった ring を捨てる判断に使う
+	pushedAt time.Time
+}
+
+func newRing(capacity int) *ring {
+	return &ring{events: make([]*model.Event, capacity)}
+}
+
+func (r *ring) push(e *model.Event, now time.Time) {
+	r.pushedAt = now
+	if len(r.events) == 0 {
+		return
+	}
+	end := (r.start + r.size) % len(r.events)
+	r.events[end] = e
+	if r.size < len(r.events) {
+		r.size++
+	} else {
+		r.start = (r.start + 1) % len(r.events)
+	}
+}
+
+// since は lastID より後に発行されたイベントを古い順に返す
+func (r *ring) since(lastID uint64) []*model.Event {
+	var events []*model.Event
+	for i := 0; i < r.size; i++ {
+		e := r.events[(r.start+i)%len(r.events)]
+		if e.ID > lastID {
+			events = append(events, e)
+		}
+	}
+	return events
+}
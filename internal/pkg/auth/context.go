@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+type contextKey struct{}
+
+// WithUser は認証済みユーザーをコンテキストに載せる
+func WithUser(ctx context.Context, user *model.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext はコンテキストから認証済みユーザーを取り出す
+func UserFromContext(ctx context.Context) (*model.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*model.User)
+	return user, ok && user != nil
+}
@@ -0,0 +1,219 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultContext は差分の前後に含める変更のない行数
+	DefaultContext = 3
+	// maxEdits はこれを超える変更量では差分を探さず、全行の置き換えとして扱う
+	maxEdits = 2000
+)
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified は old から new への行単位の差分を unified 形式で返す。差分がなければ空文字列を返す
+// 末尾の改行の有無も差分として扱い、改行のない最後の行には diff と同じく "\ No newline at end of file" を続ける
+func Unified(old, new string, context int) string {
+	ops := lineOps(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	for _, h := range hunks(ops, context) {
+		oldStart, oldLen, newStart, newLen := h.ranges()
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", formatRange(oldStart, oldLen), formatRange(newStart, newLen))
+		for _, o := range ops[h.start:h.end] {
+			switch o.kind {
+			case opEqual:
+				b.WriteString(" ")
+			case opDelete:
+				b.WriteString("-")
+			case opInsert:
+				b.WriteString("+")
+			}
+			b.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// splitLines は s を改行を含めたまま行に分ける。改行の有無だけが異なる行も別の行として比べる
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func formatRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	// 空の範囲は直前の行番号で表す
+	if length == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+// lineOps は Myers のアルゴリズムで a を b にする最短の編集手順を求める
+func lineOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		// ステップ d で読むのは k が -d から d の範囲だけなので、その範囲だけを残す
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// 各ステップの状態をたどって編集手順を復元する
+	ops := make([]op, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		// trace[d] の添字 0 が k = -d にあたる
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			ops = append(ops, op{opEqual, a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, op{opInsert, b[y]})
+		} else {
+			x--
+			ops = append(ops, op{opDelete, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		ops = append(ops, op{opEqual, a[x]})
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func replaceAll(a, b []string) []op {
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a {
+		ops = append(ops, op{opDelete, line})
+	}
+	for _, line := range b {
+		ops = append(ops, op{opInsert, line})
+	}
+	return ops
+}
+
+// hunk は ops[start:end] を1つのまとまりとして出力する範囲
+type hunk struct {
+	ops        []op
+	start, end int
+}
+
+// ranges は hunk が指す old と new の行範囲（1始まり）を返す
+func (h hunk) ranges() (oldStart, oldLen, newStart, newLen int) {
+	oldLine, newLine := 1, 1
+	for _, o := range h.ops[:h.start] {
+		if o.kind != opInsert {
+			oldLine++
+		}
+		if o.kind != opDelete {
+			newLine++
+		}
+	}
+	for _, o := range h.ops[h.start:h.end] {
+		if o.kind != opInsert {
+			oldLen++
+		}
+		if o.kind != opDelete {
+			newLen++
+		}
+	}
+	return oldLine, oldLen, newLine, newLen
+}
+
+// hunks は変更箇所の前後 context 行を含む範囲をまとめる。近い変更は1つにつなげる
+func hunks(ops []op, context int) []hunk {
+	var result []hunk
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			// 次の変更までの変更のない行が 2*context 以下ならつなげる
+			next := end
+			for next < len(ops) && ops[next].kind == opEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = next
+		}
+		if n := len(result); n > 0 && result[n-1].end >= start {
+			result[n-1].end = end
+		} else {
+			result = append(result, hunk{ops: ops, start: start, end: end})
+		}
+		i = end - 1
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package diff_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/diff"
+)
+
+// numbered は 1 から n までの番号を1行ずつ並べる。lines で指定した行は置き換える
+func numbered(n int, lines map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := lines[i]; ok {
+			b.WriteString(line)
+		} else {
+			fmt.Fprint(&b, i)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{
+			name: "both empty",
+			want: "",
+		},
+		{
+			name:    "identical",
+			context: diff.DefaultContext,
+			old:     "a\nb\n",
+			new:     "a\nb\n",
+			want:    "",
+		},
+		{
+			name:    "from empty",
+			context: diff.DefaultContext,
+			new:     "a\nb\n",
+			want:    "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "to empty",
+			context: diff.DefaultContext,
+			old:     "a\nb\n",
+			want:    "@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "insert at start",
+			context: diff.DefaultContext,
+			old:     "b\nc\n",
+			new:     "a\nb\nc\n",
+			want:    "@@ -1,2 +1,3 @@\n+a\n b\n c\n",
+		},
+		{
+			name:    "insert at end",
+			context: diff.DefaultContext,
+			old:     "a\nb\n",
+			new:     "a\nb\nc\n",
+			want:    "@@ -1,2 +1,3 @@\n a\n b\n+c\n",
+		},
+		{
+			name:    "delete at start",
+			context: diff.DefaultContext,
+			old:     "a\nb\nc\n",
+			new:     "b\nc\n",
+			want:    "@@ -1,3 +1,2 @@\n-a\n b\n c\n",
+		},
+		{
+			name:    "delete at end",
+			context: diff.DefaultContext,
+			old:     "a\nb\nc\n",
+			new:     "a\nb\n",
+			want:    "@@ -1,3 +1,2 @@\n a\n b\n-c\n",
+		},
+		{
+			name:    "single line",
+			context: diff.DefaultContext,
+			old:     "a\n",
+			new:     "b\n",
+			want:    "@@ -1 +1 @@\n-a\n+b\n",
+		},
+		{
+			name:    "distant changes in separate hunks",
+			old:     numbered(10, nil),
+			new:     numbered(10, map[int]string{2: "x", 9: "y"}),
+			context: 1,
+			want:    "@@ -1,3 +1,3 @@\n 1\n-2\n+x\n 3\n@@ -8,3 +8,3 @@\n 8\n-9\n+y\n 10\n",
+		},
+		{
+			name:    "close changes in one hunk",
+			old:     numbered(10, nil),
+			new:     numbered(10, map[int]string{3: "x", 5: "y"}),
+			context: 1,
+			want:    "@@ -2,5 +2,5 @@\n 2\n-3\n+x\n 4\n-5\n+y\n 6\n",
+		},
+		{
+			name:    "hunk ranges after an insertion",
+			old:     numbered(10, nil),
+			new:     "0\n" + numbered(10, map[int]string{9: "y"}),
+			context: 1,
+			want:    "@@ -1 +1,2 @@\n+0\n 1\n@@ -8,3 +9,3 @@\n 8\n-9\n+y\n 10\n",
+		},
+		{
+			name:    "newline added at end",
+			context: diff.DefaultContext,
+			old:     "a\nb",
+			new:     "a\nb\n",
+			want:    "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name:    "newline removed at end",
+			context: diff.DefaultContext,
+			old:     "a\nb\n",
+			new:     "a\nb",
+			want:    "@@ -1,2 +1,2 @@\n a\n-b\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "no newline at end on both sides",
+			context: diff.DefaultContext,
+			old:     "a\nb",
+			new:     "a\nc",
+			want:    "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diff.Unified(tt.old, tt.new, tt.context)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// 変更量が多すぎる場合は差分を探さず、全行を削除して追加する
+func TestUnifiedReplacesAllWhenTooManyEdits(t *testing.T) {
+	const n = 1500
+	var old, new, want strings.Builder
+	fmt.Fprintf(&want, "@@ -1,%d +1,%d @@\n", n, n)
+	for i := range n {
+		fmt.Fprintf(&old, "a%d\n", i)
+		fmt.Fprintf(&new, "b%d\n", i)
+		fmt.Fprintf(&want, "-a%d\n", i)
+	}
+	for i := range n {
+		fmt.Fprintf(&want, "+b%d\n", i)
+	}
+
+	got := diff.Unified(old.String(), new.String(), diff.DefaultContext)
+	if got != want.String() {
+		t.Errorf("Unified() did not replace every line; got %d bytes, want %d bytes", len(got), len(want.String()))
+	}
+}
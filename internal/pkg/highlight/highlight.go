@@ -0,0 +1,105 @@
+package highlight
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultContext はスニペットでマッチ箇所の前後に残す文字数
+	DefaultContext = 60
+
+	openTag  = "<mark>"
+	closeTag = "</mark>"
+	ellipsis = "…"
+)
+
+type span struct{ start, end int }
+
+// Snippet は content から terms を含む部分を切り出し、マッチ箇所を <mark> で囲んだ HTML を返す
+// マッチ以外の部分は HTML エスケープされる。マッチがなければ先頭を切り出す
+func Snippet(content string, terms []string, context int) string {
+	runes := []rune(content)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	spans := findSpans(lower, terms)
+
+	start, end := 0, len(runes)
+	if len(spans) > 0 {
+		start = max(spans[0].start-context, 0)
+		end = min(spans[0].end+context, len(runes))
+	} else {
+		end = min(2*context, len(runes))
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := start
+	for _, s := range spans {
+		if s.start >= end {
+			break
+		}
+		if s.end <= start {
+			continue
+		}
+		s.start = max(s.start, start)
+		s.end = min(s.end, end)
+		b.WriteString(html.EscapeString(string(runes[pos:s.start])))
+		b.WriteString(openTag)
+		b.WriteString(html.EscapeString(string(runes[s.start:s.end])))
+		b.WriteString(closeTag)
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// findSpans は大文字小文字を区別せずに terms の出現位置を探し、重なりをまとめて返す
+func findSpans(lower []rune, terms []string) []span {
+	var spans []span
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if equalRunes(lower[i:i+len(t)], t) {
+				spans = append(spans, span{i, i + len(t)})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := []span{spans[0]}
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			last.end = max(last.end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
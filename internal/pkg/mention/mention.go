@@ -0,0 +1,44 @@
+package mention
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+const (
+	// Channel はチャンネルのメンバー全員へのメンション。同じ名前のユーザーがいてもこちらを優先する
+	Channel = "channel"
+	// maxUserNames は1つのメッセージから読み取るユーザー名の数。これを超えた分は無視する
+	maxUserNames = 50
+)
+
+var (
+	// tokenRegex は @ に続く名前を探す。メールアドレスのように英数字などの直後にある @ は除く
+	tokenRegex = regexp.MustCompile(`(?:^|[^a-zA-Z0-9_.@-])@([a-zA-Z0-9][a-zA-Z0-9_-]*)`)
+	// userNameRegex は validate の user_name と同じ形式
+	userNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{2,30}[a-zA-Z0-9]$`)
+)
+
+// Parse は本文から @user_name と @channel を読み取る
+// ユーザー名は大文字と小文字を区別せずに重複を除き、末尾の _ と - は文の区切りとみなして取り除く
+// ユーザーが存在するかはここでは確認しない
+func Parse(content string) *model.MentionTargets {
+	targets := &model.MentionTargets{UserNames: []string{}}
+	seen := map[string]bool{}
+	for _, match := range tokenRegex.FindAllStringSubmatch(content, -1) {
+		name := strings.TrimRight(match[1], "_-")
+		if strings.EqualFold(name, Channel) {
+			targets.Channel = true
+			continue
+		}
+		key := strings.ToLower(name)
+		if !userNameRegex.MatchString(name) || seen[key] || len(targets.UserNames) >= maxUserNames {
+			continue
+		}
+		seen[key] = true
+		targets.UserNames = append(targets.UserNames, name)
+	}
+	return targets
+}
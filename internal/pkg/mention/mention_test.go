@@ -0,0 +1,95 @@
+package mention_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/mention"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantUsers   []string
+		wantChannel bool
+	}{
+		{
+			name:      "no mentions",
+			content:   "hello world",
+			wantUsers: []string{},
+		},
+		{
+			name:      "email address",
+			content:   "contact a@b.com or alice@example.com",
+			wantUsers: []string{},
+		},
+		{
+			name:      "trailing period",
+			content:   "thanks @alice.",
+			wantUsers: []string{"alice"},
+		},
+		{
+			name:      "trailing underscore",
+			content:   "ping @alice_",
+			wantUsers: []string{"alice"},
+		},
+		{
+			name:      "trailing hyphen and punctuation",
+			content:   "(@bobby-), @carol!",
+			wantUsers: []string{"bobby", "carol"},
+		},
+		{
+			name:        "channel regardless of case",
+			content:     "@Channel please look",
+			wantUsers:   []string{},
+			wantChannel: true,
+		},
+		{
+			name:        "channel with users",
+			content:     "@alice @channel",
+			wantUsers:   []string{"alice"},
+			wantChannel: true,
+		},
+		{
+			name:      "duplicates with different case keep the first spelling",
+			content:   "@Alice @alice @ALICE @bobby",
+			wantUsers: []string{"Alice", "bobby"},
+		},
+		{
+			name:      "invalid user names",
+			content:   "@bob @-dash @@bobby",
+			wantUsers: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mention.Parse(tt.content)
+			if !slices.Equal(got.UserNames, tt.wantUsers) {
+				t.Errorf("UserNames = %q, want %q", got.UserNames, tt.wantUsers)
+			}
+			if got.Channel != tt.wantChannel {
+				t.Errorf("Channel = %v, want %v", got.Channel, tt.wantChannel)
+			}
+		})
+	}
+}
+
+// 51 人目以降の名前は無視するが、@channel は上限の後でも読み取る
+func TestParseLimitsUserNames(t *testing.T) {
+	var names []string
+	for i := range 60 {
+		names = append(names, fmt.Sprintf("user%02d", i))
+	}
+	content := "@" + strings.Join(names, " @") + " @channel"
+
+	got := mention.Parse(content)
+	if !slices.Equal(got.UserNames, names[:50]) {
+		t.Errorf("UserNames = %q, want the first 50 names", got.UserNames)
+	}
+	if !got.Channel {
+		t.Errorf("Channel = false, want true")
+	}
+}
@@ -8,19 +8,31 @@ import (
 	"github.com/pressly/goose/v3"
 )
 
-//go:embed migrations/*.sql
+//go:embed migrations/mysql/*.sql migrations/postgres/*.sql migrations/sqlite/*.sql
 var embedMigrations embed.FS
 
+// dialects はドライバー名ごとの goose の方言。マイグレーションは migrations/<ドライバー名> に置く
+var dialects = map[string]string{
+	"mysql":    "mysql",
+	"postgres": "postgres",
+	"sqlite":   "sqlite3",
+}
+
 func MigrateTables(db *sqlx.DB) error {
+	dialect, ok := dialects[db.DriverName()]
+	if !ok {
+		return fmt.Errorf("unsupported driver: %s", db.DriverName())
+	}
+
 	// sqlx.DBから*sql.DBを取得
 	sqlDB := db.DB
 	goose.SetBaseFS(embedMigrations)
 
-	if err := goose.SetDialect("mysql"); err != nil {
+	if err := goose.SetDialect(dialect); err != nil {
 		return fmt.Errorf("set dialect: %w", err)
 	}
 
-	if err := goose.Up(sqlDB, "migrations"); err != nil {
+	if err := goose.Up(sqlDB, "migrations/"+db.DriverName()); err != nil {
 		return fmt.Errorf("up migration: %w", err)
 	}
 
@@ -0,0 +1,52 @@
+-- +goose Up
+-- u_blob: sha256 で重複排除したメッセージ本文・添付ファイル本体
+-- content はメッセージ本文、storage_key は BlobStore 上のファイル本体を指す
+CREATE TABLE u_blob (
+    sha256 CHAR(64) NOT NULL PRIMARY KEY,
+    size BIGINT NOT NULL,
+    content MEDIUMTEXT NULL,
+    storage_key VARCHAR(255) NULL,
+    ref_count INT NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_unreferenced (ref_count, updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 既存のメッセージ本文を u_blob に移す
+INSERT INTO u_blob (sha256, size, content, ref_count)
+SELECT SHA2(content, 256), OCTET_LENGTH(MIN(content)), MIN(content), COUNT(*)
+FROM u_message GROUP BY SHA2(content, 256);
+
+-- 既存の添付ファイルは同じハッシュのうち1つのファイルを代表にする（残りは参照されなくなる）
+INSERT INTO u_blob (sha256, size, storage_key, ref_count)
+SELECT sha256, MIN(size), MIN(storage_key), COUNT(*)
+FROM u_attachment GROUP BY sha256
+ON DUPLICATE KEY UPDATE storage_key = VALUES(storage_key), ref_count = ref_count + VALUES(ref_count);
+
+ALTER TABLE u_message ADD COLUMN content_sha256 CHAR(64) NULL AFTER parent_message_id;
+UPDATE u_message SET content_sha256 = SHA2(content, 256);
+ALTER TABLE u_message
+    MODIFY content_sha256 CHAR(64) NOT NULL,
+    DROP INDEX ft_content,
+    DROP COLUMN content,
+    ADD CONSTRAINT fk_message_blob FOREIGN KEY (content_sha256) REFERENCES u_blob(sha256);
+ALTER TABLE u_blob ADD FULLTEXT INDEX ft_content (content);
+
+ALTER TABLE u_attachment
+    DROP COLUMN storage_key,
+    ADD CONSTRAINT fk_attachment_blob FOREIGN KEY (sha256) REFERENCES u_blob(sha256);
+
+-- +goose Down
+ALTER TABLE u_attachment DROP FOREIGN KEY fk_attachment_blob;
+ALTER TABLE u_attachment ADD COLUMN storage_key VARCHAR(255) NOT NULL DEFAULT "" AFTER sha256;
+UPDATE u_attachment a JOIN u_blob b ON a.sha256 = b.sha256 SET a.storage_key = b.storage_key;
+
+ALTER TABLE u_message DROP FOREIGN KEY fk_message_blob;
+ALTER TABLE u_message ADD COLUMN content TEXT NULL AFTER content_sha256;
+UPDATE u_message m JOIN u_blob b ON m.content_sha256 = b.sha256 SET m.content = b.content;
+ALTER TABLE u_message
+    MODIFY content TEXT NOT NULL,
+    DROP COLUMN content_sha256,
+    ADD FULLTEXT INDEX ft_content (content);
+
+DROP TABLE IF EXISTS u_blob;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- メッセージ本文の種類（MIME タイプ）とコードの言語
+ALTER TABLE u_message
+    ADD COLUMN content_type VARCHAR(127) NOT NULL DEFAULT "text/plain" AFTER content_sha256,
+    ADD COLUMN language VARCHAR(32) NULL AFTER content_type,
+    ADD INDEX idx_channel_content_type (channel_id, content_type, created_at, message_id);
+
+-- +goose Down
+ALTER TABLE u_message
+    DROP INDEX idx_channel_content_type,
+    DROP COLUMN language,
+    DROP COLUMN content_type;
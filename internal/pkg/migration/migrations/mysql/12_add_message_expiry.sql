@@ -0,0 +1,12 @@
+-- +goose Up
+-- 期限付きメッセージと、一度読まれたら消えるメッセージ
+ALTER TABLE u_message
+    ADD COLUMN expires_at DATETIME NULL AFTER language,
+    ADD COLUMN burn_after_read BOOLEAN NOT NULL DEFAULT FALSE AFTER expires_at,
+    ADD INDEX idx_expires_at (expires_at);
+
+-- +goose Down
+ALTER TABLE u_message
+    DROP INDEX idx_expires_at,
+    DROP COLUMN burn_after_read,
+    DROP COLUMN expires_at;
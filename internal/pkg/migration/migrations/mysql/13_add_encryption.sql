@@ -0,0 +1,27 @@
+-- +goose Up
+-- u_user_key: エンドツーエンド暗号化でチャンネル鍵を包むためのユーザーの公開鍵
+CREATE TABLE u_user_key (
+    key_id CHAR(36) NOT NULL PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    algorithm VARCHAR(32) NOT NULL,
+    public_key VARBINARY(1024) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    UNIQUE KEY unique_user_fingerprint (user_id, fingerprint)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 暗号化されたメッセージは本文を持たず、暗号文をそのまま保存する（全文検索の対象外）
+ALTER TABLE u_message
+    ADD COLUMN encrypted BOOLEAN NOT NULL DEFAULT FALSE AFTER burn_after_read,
+    ADD COLUMN ciphertext MEDIUMBLOB NULL AFTER encrypted,
+    ADD COLUMN nonce VARBINARY(64) NULL AFTER ciphertext,
+    ADD COLUMN key_id VARCHAR(128) NULL AFTER nonce;
+
+-- +goose Down
+ALTER TABLE u_message
+    DROP COLUMN key_id,
+    DROP COLUMN nonce,
+    DROP COLUMN ciphertext,
+    DROP COLUMN encrypted;
+DROP TABLE IF EXISTS u_user_key;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- u_message_revision: 編集される前のメッセージの版。本文は u_blob を参照する
+CREATE TABLE u_message_revision (
+    message_id CHAR(36) NOT NULL,
+    revision INT NOT NULL,
+    content_sha256 CHAR(64) NOT NULL,
+    content_type VARCHAR(127) NOT NULL,
+    language VARCHAR(32) NULL,
+    encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    ciphertext MEDIUMBLOB NULL,
+    nonce VARBINARY(64) NULL,
+    key_id VARCHAR(128) NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (message_id, revision),
+    FOREIGN KEY (message_id) REFERENCES u_message(message_id) ON DELETE CASCADE,
+    CONSTRAINT fk_revision_blob FOREIGN KEY (content_sha256) REFERENCES u_blob(sha256)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+-- 版が持っていた本文の参照を外す
+UPDATE u_blob b
+JOIN (SELECT content_sha256, COUNT(*) AS n FROM u_message_revision GROUP BY content_sha256) x ON b.sha256 = x.content_sha256
+SET b.ref_count = b.ref_count - x.n;
+DROP TABLE IF EXISTS u_message_revision;
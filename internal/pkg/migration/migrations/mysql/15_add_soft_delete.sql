@@ -0,0 +1,18 @@
+-- +goose Up
+-- 削除したメッセージとチャンネルはゴミ箱に入れ、保持期間が過ぎてから完全に削除する
+-- 削除済みのチャンネルも名前は使ったままになるため、完全に削除されるまで同じ名前では作れない
+ALTER TABLE u_message
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD INDEX idx_message_deleted_at (deleted_at);
+ALTER TABLE u_channel
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD INDEX idx_channel_deleted_at (deleted_at);
+
+-- +goose Down
+-- ゴミ箱に入っていたものは削除前の状態に戻る
+ALTER TABLE u_channel
+    DROP INDEX idx_channel_deleted_at,
+    DROP COLUMN deleted_at;
+ALTER TABLE u_message
+    DROP INDEX idx_message_deleted_at,
+    DROP COLUMN deleted_at;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- メールアドレスは未設定を NULL で表す。空文字列のままだと一意制約により2人目のユーザーを作れない
+ALTER TABLE u_user_private MODIFY email VARCHAR(255) NULL;
+UPDATE u_user_private SET email = NULL WHERE email = '';
+
+-- +goose Down
+-- 未設定のユーザーが2人以上いると一意制約に違反するため、戻すとメールアドレスの一意制約は外れる
+ALTER TABLE u_user_private DROP INDEX email;
+UPDATE u_user_private SET email = '' WHERE email IS NULL;
+ALTER TABLE u_user_private MODIFY email VARCHAR(255) NOT NULL DEFAULT '';
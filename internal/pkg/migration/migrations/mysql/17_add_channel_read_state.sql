@@ -0,0 +1,18 @@
+-- +goose Up
+-- u_channel_read_state: ユーザーがチャンネルのどこまで読んだか
+-- 位置はメッセージ一覧と同じ (created_at, message_id) で持ち、未読数は u_message の
+-- idx_channel_created_message をその位置から範囲で数える。メッセージが削除されても位置は残す
+CREATE TABLE u_channel_read_state (
+    user_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    last_read_message_id CHAR(36) NOT NULL,
+    last_read_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, channel_id),
+    FOREIGN KEY (user_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (channel_id) REFERENCES u_channel(channel_id) ON DELETE CASCADE,
+    INDEX idx_channel_id (channel_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS u_channel_read_state;
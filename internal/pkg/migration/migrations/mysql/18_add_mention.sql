@@ -0,0 +1,19 @@
+-- +goose Up
+-- u_mention: メッセージで宛てられたユーザー
+-- created_at と channel_id はメッセージの値を写したもの。メンション一覧をメッセージと同じ位置で並べ、
+-- 未読数と同じ既読の位置で既読かを決めるため、u_message を読まずに範囲で絞れるようにする
+CREATE TABLE u_mention (
+    message_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    channel_id CHAR(36) NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT "user",
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (message_id, user_id),
+    FOREIGN KEY (message_id) REFERENCES u_message(message_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    INDEX idx_user_created_message (user_id, created_at, message_id),
+    INDEX idx_user_channel_created (user_id, channel_id, created_at, message_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS u_mention;
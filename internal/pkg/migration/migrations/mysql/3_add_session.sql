@@ -0,0 +1,14 @@
+-- +goose Up
+-- u_session: ログインセッション（トークンはハッシュ化して保存する）
+CREATE TABLE u_session (
+    token_hash CHAR(64) NOT NULL PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS u_session;
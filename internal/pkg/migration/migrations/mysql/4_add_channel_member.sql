@@ -0,0 +1,20 @@
+-- +goose Up
+-- u_channel.visibility: public は全員に、private はメンバーにのみ公開する
+ALTER TABLE u_channel ADD COLUMN visibility VARCHAR(16) NOT NULL DEFAULT "public" AFTER description;
+
+-- u_channel_member: チャンネルの所属メンバーと権限
+CREATE TABLE u_channel_member (
+    channel_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    role VARCHAR(16) NOT NULL DEFAULT "member",
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (channel_id, user_id),
+    FOREIGN KEY (channel_id) REFERENCES u_channel(channel_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS u_channel_member;
+ALTER TABLE u_channel DROP COLUMN visibility;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- キーセットページネーション (created_at, message_id) 用の複合インデックス
+CREATE INDEX idx_channel_created_message ON u_message (channel_id, created_at, message_id);
+
+-- +goose Down
+DROP INDEX idx_channel_created_message ON u_message;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- メッセージ本文の全文検索用インデックス
+ALTER TABLE u_message ADD FULLTEXT INDEX ft_content (content);
+
+-- +goose Down
+ALTER TABLE u_message DROP INDEX ft_content;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- u_message.parent_message_id: スレッドの親メッセージ（返信でなければ NULL）
+-- 親の削除時はアプリケーション側で返信も明示的に削除する。
+-- 外部キーの CASCADE はチャンネル削除時の連鎖削除を失敗させないためのもの
+ALTER TABLE u_message
+    ADD COLUMN parent_message_id CHAR(36) NULL DEFAULT NULL AFTER user_id,
+    ADD CONSTRAINT fk_message_parent FOREIGN KEY (parent_message_id) REFERENCES u_message(message_id) ON DELETE CASCADE,
+    ADD INDEX idx_parent_created (parent_message_id, created_at);
+
+-- +goose Down
+ALTER TABLE u_message
+    DROP FOREIGN KEY fk_message_parent,
+    DROP INDEX idx_parent_created,
+    DROP COLUMN parent_message_id;
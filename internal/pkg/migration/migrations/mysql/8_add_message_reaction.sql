@@ -0,0 +1,16 @@
+-- +goose Up
+-- u_message_reaction: メッセージへの絵文字リアクション
+-- 絵文字同士が照合順序で同一視されないよう emoji はバイナリ照合にする
+CREATE TABLE u_message_reaction (
+    message_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    emoji VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji),
+    FOREIGN KEY (message_id) REFERENCES u_message(message_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS u_message_reaction;
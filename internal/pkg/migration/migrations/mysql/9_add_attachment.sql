@@ -0,0 +1,30 @@
+-- +goose Up
+-- u_attachment: アップロードされたファイルのメタデータ（本体は BlobStore に保存する）
+CREATE TABLE u_attachment (
+    attachment_id CHAR(36) NOT NULL PRIMARY KEY,
+    owner_id CHAR(36) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(127) NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (owner_id) REFERENCES u_user(user_id) ON DELETE CASCADE,
+    INDEX idx_owner_id (owner_id),
+    INDEX idx_sha256 (sha256)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- u_message_attachment: メッセージと添付ファイルの対応
+CREATE TABLE u_message_attachment (
+    message_id CHAR(36) NOT NULL,
+    attachment_id CHAR(36) NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (message_id, attachment_id),
+    FOREIGN KEY (message_id) REFERENCES u_message(message_id) ON DELETE CASCADE,
+    FOREIGN KEY (attachment_id) REFERENCES u_attachment(attachment_id) ON DELETE CASCADE,
+    INDEX idx_attachment_id (attachment_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS u_message_attachment;
+DROP TABLE IF EXISTS u_attachment;
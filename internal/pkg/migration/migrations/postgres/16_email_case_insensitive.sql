@@ -0,0 +1,8 @@
+-- +goose Up
+-- MySQL の照合順序に合わせ、メールアドレスも大文字小文字を区別せずに一意にする
+ALTER TABLE u_user_private DROP CONSTRAINT u_user_private_email_key;
+CREATE UNIQUE INDEX idx_email ON u_user_private (lower(email));
+
+-- +goose Down
+DROP INDEX idx_email;
+ALTER TABLE u_user_private ADD CONSTRAINT u_user_private_email_key UNIQUE (email);
@@ -0,0 +1,17 @@
+-- +goose Up
+-- u_mention: メッセージで宛てられたユーザー
+-- created_at と channel_id はメッセージの値を写したもの。メンション一覧をメッセージと同じ位置で並べ、
+-- 未読数と同じ既読の位置で既読かを決めるため、u_message を読まずに範囲で絞れるようにする
+CREATE TABLE u_mention (
+    message_id UUID NOT NULL REFERENCES u_message (message_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL,
+    kind VARCHAR(16) NOT NULL DEFAULT 'user',
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (message_id, user_id)
+);
+CREATE INDEX idx_mention_user_created_message ON u_mention (user_id, created_at, message_id);
+CREATE INDEX idx_mention_user_channel_created ON u_mention (user_id, channel_id, created_at, message_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS u_mention;
@@ -0,0 +1,184 @@
+-- +goose Up
+-- MySQL の 1〜15 を適用した後と同じスキーマ。テストデータは入れない
+-- MySQL の照合順序に合わせ、ユーザー名とチャンネル名は大文字小文字を区別せずに一意にする
+
+-- u_user: ユーザー情報
+CREATE TABLE u_user (
+    user_id UUID NOT NULL PRIMARY KEY,
+    user_name VARCHAR(32) NOT NULL,
+    nickname VARCHAR(32) NOT NULL,
+    status VARCHAR(4096) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_user_name ON u_user (lower(user_name));
+
+-- u_user_private: ユーザーのプライベート情報
+CREATE TABLE u_user_private (
+    user_id UUID NOT NULL PRIMARY KEY REFERENCES u_user (user_id) ON DELETE CASCADE,
+    email VARCHAR(255) NULL UNIQUE,
+    password_hash CHAR(60) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- u_channel: チャンネル情報
+CREATE TABLE u_channel (
+    channel_id UUID NOT NULL PRIMARY KEY,
+    channel_name VARCHAR(32) NOT NULL,
+    display_name VARCHAR(32) NOT NULL,
+    description VARCHAR(256) NOT NULL DEFAULT '',
+    visibility VARCHAR(16) NOT NULL DEFAULT 'public',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_channel_name ON u_channel (lower(channel_name));
+CREATE INDEX idx_channel_deleted_at ON u_channel (deleted_at);
+
+-- u_session: ログインセッション（トークンはハッシュ化して保存する）
+CREATE TABLE u_session (
+    token_hash CHAR(64) NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_session_user_id ON u_session (user_id);
+CREATE INDEX idx_session_expires_at ON u_session (expires_at);
+
+-- u_channel_member: チャンネルの所属メンバーと権限
+CREATE TABLE u_channel_member (
+    channel_id UUID NOT NULL REFERENCES u_channel (channel_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (channel_id, user_id)
+);
+CREATE INDEX idx_channel_member_user_id ON u_channel_member (user_id);
+
+-- u_blob: sha256 で重複排除したメッセージ本文・添付ファイル本体
+-- content はメッセージ本文、storage_key は BlobStore 上のファイル本体を指す
+CREATE TABLE u_blob (
+    sha256 CHAR(64) NOT NULL PRIMARY KEY,
+    size BIGINT NOT NULL,
+    content TEXT NULL,
+    storage_key VARCHAR(255) NULL,
+    ref_count INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_blob_unreferenced ON u_blob (ref_count, updated_at);
+CREATE INDEX ft_blob_content ON u_blob USING GIN (to_tsvector('simple', coalesce(content, '')));
+
+-- u_message: メッセージ情報
+-- 親の削除時はアプリケーション側で返信も明示的に削除する。
+-- parent_message_id の CASCADE はチャンネル削除時の連鎖削除を失敗させないためのもの
+CREATE TABLE u_message (
+    message_id UUID NOT NULL PRIMARY KEY,
+    channel_id UUID NOT NULL REFERENCES u_channel (channel_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    parent_message_id UUID NULL REFERENCES u_message (message_id) ON DELETE CASCADE,
+    content_sha256 CHAR(64) NOT NULL REFERENCES u_blob (sha256),
+    content_type VARCHAR(127) NOT NULL DEFAULT 'text/plain',
+    language VARCHAR(32) NULL,
+    expires_at TIMESTAMPTZ NULL,
+    burn_after_read BOOLEAN NOT NULL DEFAULT FALSE,
+    encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    ciphertext BYTEA NULL,
+    nonce BYTEA NULL,
+    key_id VARCHAR(128) NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ NULL
+);
+CREATE INDEX idx_message_user_id ON u_message (user_id);
+CREATE INDEX idx_channel_created_message ON u_message (channel_id, created_at, message_id);
+CREATE INDEX idx_parent_created ON u_message (parent_message_id, created_at);
+CREATE INDEX idx_channel_content_type ON u_message (channel_id, content_type, created_at, message_id);
+CREATE INDEX idx_message_content_sha256 ON u_message (content_sha256);
+CREATE INDEX idx_message_expires_at ON u_message (expires_at);
+CREATE INDEX idx_message_deleted_at ON u_message (deleted_at);
+
+-- u_pinned_message: ピン留めされたメッセージ情報
+CREATE TABLE u_pinned_message (
+    message_id UUID NOT NULL REFERENCES u_message (message_id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES u_channel (channel_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (message_id, channel_id)
+);
+CREATE INDEX idx_pinned_channel_id ON u_pinned_message (channel_id);
+
+-- u_message_reaction: メッセージへの絵文字リアクション
+CREATE TABLE u_message_reaction (
+    message_id UUID NOT NULL REFERENCES u_message (message_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    emoji VARCHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+CREATE INDEX idx_reaction_user_id ON u_message_reaction (user_id);
+
+-- u_attachment: アップロードされたファイルのメタデータ（本体は BlobStore に保存する）
+CREATE TABLE u_attachment (
+    attachment_id UUID NOT NULL PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(127) NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL REFERENCES u_blob (sha256),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_attachment_owner_id ON u_attachment (owner_id);
+CREATE INDEX idx_attachment_sha256 ON u_attachment (sha256);
+
+-- u_message_attachment: メッセージと添付ファイルの対応
+CREATE TABLE u_message_attachment (
+    message_id UUID NOT NULL REFERENCES u_message (message_id) ON DELETE CASCADE,
+    attachment_id UUID NOT NULL REFERENCES u_attachment (attachment_id) ON DELETE CASCADE,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (message_id, attachment_id)
+);
+CREATE INDEX idx_message_attachment_attachment_id ON u_message_attachment (attachment_id);
+
+-- u_user_key: エンドツーエンド暗号化でチャンネル鍵を包むためのユーザーの公開鍵
+CREATE TABLE u_user_key (
+    key_id UUID NOT NULL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    algorithm VARCHAR(32) NOT NULL,
+    public_key BYTEA NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, fingerprint)
+);
+
+-- u_message_revision: 編集される前のメッセージの版。本文は u_blob を参照する
+CREATE TABLE u_message_revision (
+    message_id UUID NOT NULL REFERENCES u_message (message_id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    content_sha256 CHAR(64) NOT NULL REFERENCES u_blob (sha256),
+    content_type VARCHAR(127) NOT NULL,
+    language VARCHAR(32) NULL,
+    encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    ciphertext BYTEA NULL,
+    nonce BYTEA NULL,
+    key_id VARCHAR(128) NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (message_id, revision)
+);
+CREATE INDEX idx_revision_content_sha256 ON u_message_revision (content_sha256);
+
+-- +goose Down
+DROP TABLE IF EXISTS u_message_revision;
+DROP TABLE IF EXISTS u_user_key;
+DROP TABLE IF EXISTS u_message_attachment;
+DROP TABLE IF EXISTS u_attachment;
+DROP TABLE IF EXISTS u_message_reaction;
+DROP TABLE IF EXISTS u_pinned_message;
+DROP TABLE IF EXISTS u_message;
+DROP TABLE IF EXISTS u_blob;
+DROP TABLE IF EXISTS u_channel_member;
+DROP TABLE IF EXISTS u_session;
+DROP TABLE IF EXISTS u_channel;
+DROP TABLE IF EXISTS u_user_private;
+DROP TABLE IF EXISTS u_user;
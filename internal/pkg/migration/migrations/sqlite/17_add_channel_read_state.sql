@@ -0,0 +1,16 @@
+-- +goose Up
+-- u_channel_read_state: ユーザーがチャンネルのどこまで読んだか
+-- 位置はメッセージ一覧と同じ (created_at, message_id) で持ち、未読数は u_message の
+-- idx_channel_created_message をその位置から範囲で数える。メッセージが削除されても位置は残す
+CREATE TABLE u_channel_read_state (
+    user_id TEXT NOT NULL REFERENCES u_user (user_id) ON DELETE CASCADE,
+    channel_id TEXT NOT NULL REFERENCES u_channel (channel_id) ON DELETE CASCADE,
+    last_read_message_id TEXT NOT NULL,
+    last_read_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (user_id, channel_id)
+);
+CREATE INDEX idx_read_state_channel_id ON u_channel_read_state (channel_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS u_channel_read_state;
@@ -0,0 +1,154 @@
+package sniff
+
+import (
+	"encoding/json"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+)
+
+// minLanguageScore は言語と判定するのに必要な特徴の一致数
+const minLanguageScore = 2
+
+// interpreters はシバンのインタプリタ名と言語の対応
+var interpreters = map[string]string{
+	"sh":      "shell",
+	"bash":    "shell",
+	"zsh":     "shell",
+	"python":  "python",
+	"python3": "python",
+	"node":    "javascript",
+	"deno":    "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+}
+
+type languageRule struct {
+	language string
+	patterns []*regexp.Regexp
+}
+
+// languageRules は言語ごとの特徴的な記述。一致した数が最も多い言語を採用する
+var languageRules = []languageRule{
+	{"go", compile(`(?m)^package \w+$`, `(?m)^func (\(\w+ \*?\w+\) )?\w+\(`, `:= `, `(?m)^import \($`, `\bif err != nil\b`)},
+	{"python", compile(`(?m)^\s*def \w+\(.*\):$`, `(?m)^(from \S+ )?import \w+`, `\bself\.`, `(?m)^\s*(elif|except)\b.*:$`, `(?m)^if __name__ == `)},
+	{"javascript", compile(`\b(const|let) \w+ = `, `=> \{?`, `\bfunction \w*\(`, `\bconsole\.log\(`, `\brequire\(['"]`)},
+	{"typescript", compile(`(?m)^(export )?interface \w+ \{`, `:\s*(string|number|boolean)\b`, `(?m)^import .* from ['"]`, `(?m)^(export )?type \w+ = `)},
+	{"java", compile(`\bpublic (static )?(class|void|final)\b`, `\bSystem\.out\.print`, `(?m)^import java\.`, `@Override\b`)},
+	{"rust", compile(`(?m)^\s*(pub )?fn \w+`, `\blet mut\b`, `(?m)^\s*impl\b`, `(?m)^use \w+::`, `\bprintln!\(`)},
+	{"c", compile(`(?m)^#include [<"]`, `\bint main\(`, `\bprintf\(`, `(?m)^#define \w+`)},
+	{"sql", compile(`(?im)^\s*select\b.+\bfrom\b`, `(?im)^\s*(insert into|update \w+ set|delete from)\b`, `(?im)^\s*create (table|index)\b`, `(?i)\bwhere\b.+=`)},
+	{"shell", compile(`(?m)^\$ \S`, `(?m)^\s*(sudo|echo|export|cd) `, `\$\{?\w+\}?`, `(?m)^\s*(fi|done|esac)$`)},
+	{"html", compile(`(?i)<!doctype html>`, `(?i)<(html|head|body|div|span)\b`, `(?i)</\w+>`)},
+	{"css", compile(`(?m)^[.#]?[\w-]+( [.#]?[\w-]+)* \{$`, `(?m)^\s*[\w-]+: [^;]+;$`)},
+}
+
+func compile(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		compiled = append(compiled, regexp.MustCompile(p))
+	}
+	return compiled
+}
+
+// Detect は本文の種類と、コードであればその言語を推定する
+// JSON、URL、シバン、言語ごとの特徴の順に判定し、いずれにも当てはまらなければプレーンテキストとする
+func Detect(content string) (contentType string, language string) {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return model.ContentTypeText, ""
+	}
+	if isJSON(trimmed) {
+		return model.ContentTypeJSON, "json"
+	}
+	if isURLList(trimmed) {
+		return model.ContentTypeURL, ""
+	}
+	if language := Language(trimmed); language != "" {
+		return model.ContentTypeCode, language
+	}
+	return model.ContentTypeText, ""
+}
+
+// Language はコードの言語を推定する。推定できなければ空文字列を返す
+func Language(content string) string {
+	if language := shebangLanguage(content); language != "" {
+		return language
+	}
+
+	best, bestScore := "", 0
+	for _, rule := range languageRules {
+		score := 0
+		for _, pattern := range rule.patterns {
+			if pattern.MatchString(content) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = rule.language, score
+		}
+	}
+	if bestScore < minLanguageScore {
+		return ""
+	}
+	return best
+}
+
+// isJSON はオブジェクトか配列の JSON かを判定する。単なる数値や文字列はテキストとして扱う
+func isJSON(content string) bool {
+	if content[0] != '{' && content[0] != '[' {
+		return false
+	}
+	return json.Valid([]byte(content))
+}
+
+// isURLList は全ての行が絶対 URL かを判定する
+func isURLList(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.ContainsAny(line, " \t") {
+			return false
+		}
+		u, err := url.Parse(line)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		switch u.Scheme {
+		case "http", "https", "ftp":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// shebangLanguage は "#!/usr/bin/env python3" のようなシバンから言語を判定する
+func shebangLanguage(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	line, _, _ := strings.Cut(content[2:], "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		// env -S のようなオプションは読み飛ばす
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+	return interpreters[interpreter]
+}
@@ -0,0 +1,269 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalid は Struct が返すエラーが errors.Is で一致する値
+var ErrInvalid = errors.New("validation failed")
+
+// フィールドエラーのコード。クライアントが分岐に使うため変更しない
+const (
+	CodeRequired      = "required"
+	CodeTooLong       = "too_long"
+	CodeTooShort      = "too_short"
+	CodeInvalidChoice = "invalid_choice"
+	CodeInvalidFormat = "invalid_format"
+	CodeWeakPassword  = "weak_password"
+	CodeDuplicate     = "duplicate"
+)
+
+// FieldError は1つのフィールドの検証エラー。Field は JSON のフィールド名
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors は検証で見つかった全てのフィールドエラー
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return ErrInvalid.Error() + ": " + strings.Join(msgs, ", ")
+}
+
+func (e Errors) Unwrap() error {
+	return ErrInvalid
+}
+
+// Add はフィールドエラーを追加する。タグで表せない検証に使う
+func (e *Errors) Add(field, code, message string) {
+	*e = append(*e, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err はエラーがあれば Errors を、なければ nil を返す
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// formats は format= で指定できる形式
+var formats = map[string]*regexp.Regexp{
+	"user_name":    regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{2,30}[a-zA-Z0-9]$`),
+	"channel_name": regexp.MustCompile(`^[a-zA-Z0-9_-]{4,32}$`),
+	"email":        regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`),
+}
+
+// Password はパスワードが8文字以上で大文字・小文字・数字を含むかを判定する
+func Password(password string) bool {
+	if len(password) < 8 {
+		return false
+	}
+
+	var (
+		hasUpper bool
+		hasLower bool
+		hasDigit bool
+	)
+
+	for _, char := range password {
+		if unicode.IsUpper(char) {
+			hasUpper = true
+		} else if unicode.IsLower(char) {
+			hasLower = true
+		} else if unicode.IsDigit(char) {
+			hasDigit = true
+		}
+	}
+
+	return hasUpper && hasLower && hasDigit
+}
+
+// Struct は構造体の validate タグに従ってフィールドを検証し、見つかった全てのエラーを返す
+//
+// タグはカンマ区切りで次の規則を並べる
+//
+//	required           空でないこと。文字列は空白だけでもエラーにする
+//	required_unless=f  JSON 名 f の bool フィールドが false なら required と同じ
+//	max=N / min=N      文字列は文字数、[]byte とスライスは要素数
+//	maxbytes=N         文字列のバイト数。TEXT 型の列に合わせる
+//	oneof=a b c        空白区切りの値のいずれか
+//	format=name        formats に登録した正規表現に一致すること
+//	password           Password を満たすこと
+//	unique             スライスの要素が重複しないこと
+//
+// ポインタのフィールドは nil なら検証しない (PATCH で省略されたフィールド)
+// required 以外の規則は空の値を検証しない
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: Struct called with %s", rv.Kind()))
+	}
+
+	var errs Errors
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		field := rv.Field(i)
+		if field.Kind() == reflect.Pointer {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if fe, ok := check(rv, jsonName(sf), field, tag); !ok {
+			errs = append(errs, fe)
+		}
+	}
+	return errs.Err()
+}
+
+// jsonName は JSON のフィールド名を返す。json タグがなければ Go のフィールド名を使う
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+// check は1つのフィールドを規則の順に検証し、最初に失敗した規則のエラーを返す
+func check(parent reflect.Value, name string, field reflect.Value, tag string) (FieldError, bool) {
+	fail := func(code, format string, args ...any) (FieldError, bool) {
+		return FieldError{Field: name, Code: code, Message: fmt.Sprintf(format, args...)}, false
+	}
+
+	empty := isEmpty(field)
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			if empty {
+				return fail(CodeRequired, "is required")
+			}
+		case "required_unless":
+			if empty && !boolField(parent, arg) {
+				return fail(CodeRequired, "is required unless %s is true", arg)
+			}
+		}
+		if empty {
+			continue
+		}
+
+		switch key {
+		case "required", "required_unless":
+		case "max":
+			if n := mustAtoi(arg); length(field) > n {
+				return fail(CodeTooLong, "must be at most %d %s", n, unit(field))
+			}
+		case "min":
+			if n := mustAtoi(arg); length(field) < n {
+				return fail(CodeTooShort, "must be at least %d %s", n, unit(field))
+			}
+		case "maxbytes":
+			if n := mustAtoi(arg); len(field.String()) > n {
+				return fail(CodeTooLong, "must be at most %d bytes", n)
+			}
+		case "oneof":
+			choices := strings.Fields(arg)
+			if !slices.Contains(choices, field.String()) {
+				return fail(CodeInvalidChoice, "must be one of %s", strings.Join(choices, ", "))
+			}
+		case "format":
+			re, ok := formats[arg]
+			if !ok {
+				panic("validate: unknown format " + arg)
+			}
+			if !re.MatchString(field.String()) {
+				return fail(CodeInvalidFormat, "does not match the %s format", arg)
+			}
+		case "password":
+			if !Password(field.String()) {
+				return fail(CodeWeakPassword, "must be at least 8 characters and contain upper case, lower case and digits")
+			}
+		case "unique":
+			seen := make(map[any]struct{}, field.Len())
+			for j := 0; j < field.Len(); j++ {
+				elem := field.Index(j).Interface()
+				if _, ok := seen[elem]; ok {
+					return fail(CodeDuplicate, "must not contain duplicates")
+				}
+				seen[elem] = struct{}{}
+			}
+		default:
+			panic("validate: unknown rule " + key)
+		}
+	}
+	return FieldError{}, true
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func length(v reflect.Value) int {
+	if v.Kind() == reflect.String {
+		return utf8.RuneCountInString(v.String())
+	}
+	return v.Len()
+}
+
+func unit(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.String:
+		return "characters"
+	case v.Type().Elem().Kind() == reflect.Uint8:
+		return "bytes"
+	default:
+		return "items"
+	}
+}
+
+// boolField は JSON 名が name の bool フィールドの値を返す
+func boolField(parent reflect.Value, name string) bool {
+	for i := 0; i < parent.NumField(); i++ {
+		if jsonName(parent.Type().Field(i)) == name {
+			return parent.Field(i).Bool()
+		}
+	}
+	panic("validate: unknown field " + name)
+}
+
+func mustAtoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		panic("validate: invalid number " + s)
+	}
+	return n
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/gofrs/uuid"
+)
+
+// sniffLen は http.DetectContentType が参照するバイト数
+const sniffLen = 512
+
+type attachmentUsecase struct {
+	attachmentRepo repository.AttachmentRepository
+	channelRepo    repository.ChannelRepository
+	blobStore      repository.BlobStore
+}
+
+func NewAttachmentUsecase(attachmentRepo repository.AttachmentRepository, channelRepo repository.ChannelRepository, blobStore repository.BlobStore) usecase.AttachmentUsecase {
+	return &attachmentUsecase{
+		attachmentRepo: attachmentRepo,
+		channelRepo:    channelRepo,
+		blobStore:      blobStore,
+	}
+}
+
+// sanitizeFileName はパス区切りや制御文字を取り除いたファイル名を返す
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+func (a *attachmentUsecase) UploadAttachment(ctx context.Context, req *model.RequestUploadAttachment) (*model.Attachment, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+
+	// サイズとハッシュを確定させるため、一度一時ファイルに書き出す
+	tmp, err := os.CreateTemp("", "clipboard-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(req.Body, model.MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	if size > model.MaxAttachmentSize {
+		return nil, model.ErrAttachmentTooLarge
+	}
+
+	mimeType := req.MimeType
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err != nil || mediaType == "application/octet-stream" {
+		head := make([]byte, sniffLen)
+		n, _ := tmp.ReadAt(head, 0)
+		mimeType = http.DetectContentType(head[:n])
+	}
+
+	attachmentID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	// 参照を確保してから本体を書き込む。回収済みのキーと衝突しないよう、キーには添付ファイルの ID を含める
+	attachment, err := a.attachmentRepo.CreateAttachment(ctx, &model.Attachment{
+		AttachmentID: attachmentID,
+		OwnerID:      caller.UserID,
+		FileName:     sanitizeFileName(req.FileName),
+		MimeType:     mimeType,
+		Size:         size,
+		SHA256:       hash,
+		StorageKey:   "blobs/" + hash + "/" + attachmentID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := a.blobExists(ctx, attachment)
+	if err != nil {
+		return nil, err
+	}
+	if stored {
+		return attachment, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := a.blobStore.Put(ctx, attachment.StorageKey, tmp, size, mimeType); err != nil {
+		// 本体がなければ参照できないのでメタデータも消しておく
+		if delErr := a.attachmentRepo.DeleteAttachment(ctx, attachmentID); delErr != nil {
+			log.Printf("failed to delete attachment %s: %v", attachmentID, delErr)
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// blobExists は重複排除された添付ファイルの本体が既に保存されているかを確認する
+// 先にアップロードした側が書き込みに失敗していた場合に備え、フラグだけでは判断しない
+func (a *attachmentUsecase) blobExists(ctx context.Context, attachment *model.Attachment) (bool, error) {
+	if attachment.Deduplicated == nil || !*attachment.Deduplicated {
+		return false, nil
+	}
+	body, err := a.blobStore.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if err == model.ErrBlobNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	body.Close()
+	return true, nil
+}
+
+// canRead はアップロードした本人か、添付されたメッセージのチャンネルを閲覧できるかを判定する
+func (a *attachmentUsecase) canRead(ctx context.Context, attachment *model.Attachment) (bool, error) {
+	if callerID(ctx) == attachment.OwnerID {
+		return true, nil
+	}
+	channelIDs, err := a.attachmentRepo.GetAttachmentChannels(ctx, attachment.AttachmentID)
+	if err != nil {
+		return false, err
+	}
+	for _, channelID := range channelIDs {
+		_, _, err := visibleChannel(ctx, a.channelRepo, channelID)
+		if err == nil {
+			return true, nil
+		}
+		if err != model.ErrChannelNotFound {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (a *attachmentUsecase) OpenAttachment(ctx context.Context, attachmentID uuid.UUID) (*model.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := a.attachmentRepo.GetAttachment(ctx, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ok, err := a.canRead(ctx, attachment)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		// 非公開チャンネルのファイルの存在を明かさない
+		return nil, nil, model.ErrAttachmentNotFound
+	}
+
+	body, err := a.blobStore.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		if err == model.ErrBlobNotFound {
+			return nil, nil, model.ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, body, nil
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/validate"
+)
+
+// DefaultSessionTTL はセッションの既定の有効期間
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+type authUsecase struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	sessionTTL  time.Duration
+}
+
+func NewAuthUsecase(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, sessionTTL time.Duration) usecase.AuthUsecase {
+	return &authUsecase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		sessionTTL:  sessionTTL,
+	}
+}
+
+func (a *authUsecase) Login(ctx context.Context, req *model.RequestLogin) (*model.ResponseLogin, error) {
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepo.VerifyPassword(ctx, req.UserName, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.UserID,
+		ExpiresAt: time.Now().Add(a.sessionTTL).UTC().Truncate(time.Second),
+	}
+	if err := a.sessionRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &model.ResponseLogin{
+		Token:     token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	}, nil
+}
+
+func (a *authUsecase) Logout(ctx context.Context, token string) error {
+	return a.sessionRepo.DeleteSession(ctx, auth.HashToken(token))
+}
+
+func (a *authUsecase) Authenticate(ctx context.Context, token string) (*model.User, error) {
+	if token == "" {
+		return nil, model.ErrUnauthorized
+	}
+
+	tokenHash := auth.HashToken(token)
+	session, err := a.sessionRepo.GetSession(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		// 期限切れのセッションはその場で破棄する
+		a.sessionRepo.DeleteSession(ctx, tokenHash)
+		return nil, model.ErrUnauthorized
+	}
+
+	user, err := a.userRepo.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		if err == model.ErrUserNotFound {
+			return nil, model.ErrUnauthorized
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+)
+
+const (
+	// DefaultBlobSweepInterval は参照されなくなった blob を回収する間隔
+	DefaultBlobSweepInterval = time.Hour
+	// blobGracePeriod は参照が 0 になってから回収するまでの猶予
+	blobGracePeriod = 10 * time.Minute
+	// blobSweepBatchSize は1トランザクションで回収する blob の件数
+	blobSweepBatchSize = 100
+)
+
+type blobUsecase struct {
+	blobRepo  repository.BlobRepository
+	blobStore repository.BlobStore
+}
+
+func NewBlobUsecase(blobRepo repository.BlobRepository, blobStore repository.BlobStore) usecase.BlobUsecase {
+	return &blobUsecase{
+		blobRepo:  blobRepo,
+		blobStore: blobStore,
+	}
+}
+
+func (b *blobUsecase) SweepBlobs(ctx context.Context) (int, error) {
+	olderThan := time.Now().Add(-blobGracePeriod)
+	swept := 0
+	for {
+		blobs, err := b.blobRepo.DeleteUnreferencedBlobs(ctx, olderThan, blobSweepBatchSize)
+		if err != nil {
+			return swept, err
+		}
+		for _, blob := range blobs {
+			if blob.StorageKey == nil {
+				continue
+			}
+			// 行は既に消えているので、本体の削除に失敗しても孤立したファイルが残るだけで済む
+			if err := b.blobStore.Delete(ctx, *blob.StorageKey); err != nil && err != model.ErrBlobNotFound {
+				log.Printf("failed to delete blob %s: %v", *blob.StorageKey, err)
+			}
+		}
+		swept += len(blobs)
+		if len(blobs) < blobSweepBatchSize {
+			return swept, nil
+		}
+	}
+}
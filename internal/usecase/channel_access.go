@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/gofrs/uuid"
+)
+
+// visibleChannel はリクエストしたユーザーから見えるチャンネルとその所属情報を返す
+// 非公開チャンネルはメンバー以外には存在しないものとして ErrChannelNotFound を返す
+// 所属していない場合、member は nil になる
+func visibleChannel(ctx context.Context, channelRepo repository.ChannelRepository, channelID uuid.UUID) (*model.Channel, *model.ChannelMember, error) {
+	channel, err := channelRepo.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var member *model.ChannelMember
+	if caller, ok := auth.UserFromContext(ctx); ok {
+		member, err = channelRepo.GetChannelMember(ctx, channelID, caller.UserID)
+		if err != nil && err != model.ErrChannelMemberNotFound {
+			return nil, nil, err
+		}
+	}
+
+	if channel.Visibility == model.ChannelVisibilityPrivate && member == nil {
+		return nil, nil, model.ErrChannelNotFound
+	}
+	return channel, member, nil
+}
+
+// callerID は認証済みユーザーのIDを返す。未認証なら uuid.Nil
+func callerID(ctx context.Context) uuid.UUID {
+	if caller, ok := auth.UserFromContext(ctx); ok {
+		return caller.UserID
+	}
+	return uuid.Nil
+}
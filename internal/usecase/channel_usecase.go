@@ -2,69 +2,237 @@ package usecase
 
 import (
 	"context"
-	"regexp"
 
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/validate"
 	"github.com/gofrs/uuid"
 )
 
-var (
-	channelNameRegex       = `^[a-zA-Z0-9_-]{4,32}$`
-	compiledChannelNameReg = regexp.MustCompile(channelNameRegex)
-)
-
 type channelUseCase struct {
 	channelRepo repository.ChannelRepository
+	txManager   repository.TxManager
 }
 
-func NewChannelUsecase(channelRepo repository.ChannelRepository) usecase.ChannelUsecase {
+func NewChannelUsecase(channelRepo repository.ChannelRepository, txManager repository.TxManager) usecase.ChannelUsecase {
 	return &channelUseCase{
 		channelRepo: channelRepo,
+		txManager:   txManager,
 	}
 }
 
-func (c *channelUseCase) validateChannelName(channelName string) error {
-	if channelName == "" {
-		return model.ErrInvalidChannelName
+// manageableChannel はリクエストしたユーザーがチャンネルのオーナーか管理者であることを確認する
+// 公開チャンネルも、メンバーでないユーザーは変更も削除もできない
+func (c *channelUseCase) manageableChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, *model.ChannelMember, error) {
+	if _, ok := auth.UserFromContext(ctx); !ok {
+		return nil, nil, model.ErrUnauthorized
+	}
+	channel, member, err := visibleChannel(ctx, c.channelRepo, channelID)
+	if err != nil {
+		return nil, nil, err
 	}
-	if !compiledChannelNameReg.MatchString(channelName) {
-		return model.ErrBadFormatChannelName
+	if member == nil || !member.Role.CanManage() {
+		return nil, nil, model.ErrForbidden
 	}
-	return nil
+	return channel, member, nil
 }
 
 func (c *channelUseCase) CreateChannel(ctx context.Context, req *model.RequestCreateChannel) (*model.Channel, error) {
-	if err := c.validateChannelName(req.ChannelName); err != nil {
+	if err := validate.Struct(req); err != nil {
 		return nil, err
 	}
-	if req.DisplayName == "" {
-		return nil, model.ErrInvalidDisplayName
+	if req.Visibility == "" {
+		req.Visibility = model.ChannelVisibilityPublic
+	}
+
+	// 作成者はオーナーになる。非公開チャンネルはオーナーなしでは誰も参加できない
+	req.OwnerID = callerID(ctx)
+	if req.Visibility == model.ChannelVisibilityPrivate && req.OwnerID.IsNil() {
+		return nil, model.ErrUnauthorized
 	}
 	return c.channelRepo.CreateChannel(ctx, req)
 }
 
 func (c *channelUseCase) GetChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error) {
-	return c.channelRepo.GetChannel(ctx, channelID)
+	channel, _, err := visibleChannel(ctx, c.channelRepo, channelID)
+	if err != nil {
+		if err == model.ErrChannelNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return channel, nil
 }
 
-func (c *channelUseCase) GetChannels(ctx context.Context) ([]*model.Channel, error) {
-	return c.channelRepo.GetChannels(ctx)
+func (c *channelUseCase) GetChannels(ctx context.Context, includeDeleted bool) ([]*model.Channel, error) {
+	if _, ok := auth.UserFromContext(ctx); !ok && includeDeleted {
+		return nil, model.ErrUnauthorized
+	}
+	return c.channelRepo.GetChannels(ctx, callerID(ctx), includeDeleted)
 }
 
 func (c *channelUseCase) PatchChannel(ctx context.Context, channelID uuid.UUID, req *model.RequestPatchChannel) (*model.Channel, error) {
-	if req.ChannelName != nil {
-		if err := c.validateChannelName(*req.ChannelName); err != nil {
-			return nil, err
-		}
+	if err := validate.Struct(req); err != nil {
+		return nil, err
 	}
-	if req.DisplayName != nil && *req.DisplayName == "" {
-		return nil, model.ErrInvalidDisplayName
+	if _, _, err := c.manageableChannel(ctx, channelID); err != nil {
+		return nil, err
 	}
 	return c.channelRepo.PatchChannel(ctx, channelID, req)
 }
 
 func (c *channelUseCase) DeleteChannel(ctx context.Context, channelID uuid.UUID) error {
+	if _, _, err := c.manageableChannel(ctx, channelID); err != nil {
+		return err
+	}
 	return c.channelRepo.DeleteChannel(ctx, channelID)
 }
+
+// RestoreChannel はゴミ箱のチャンネルを元に戻す
+// 削除と同じく、チャンネルのオーナーか管理者のみ戻せる
+func (c *channelUseCase) RestoreChannel(ctx context.Context, channelID uuid.UUID) (*model.Channel, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	// 権限を確かめてから戻したチャンネルを返すまでを、1つのトランザクションで行う
+	var restored *model.Channel
+	err := c.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		restored, err = c.restoreChannel(ctx, caller, channelID)
+		return err
+	})
+	return restored, err
+}
+
+func (c *channelUseCase) restoreChannel(ctx context.Context, caller *model.User, channelID uuid.UUID) (*model.Channel, error) {
+	channel, err := c.channelRepo.GetDeletedChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := c.channelRepo.GetChannelMember(ctx, channelID, caller.UserID)
+	if err != nil {
+		if err != model.ErrChannelMemberNotFound {
+			return nil, err
+		}
+		// 非公開チャンネルはメンバー以外には存在しないものとして扱う
+		if channel.Visibility == model.ChannelVisibilityPrivate {
+			return nil, model.ErrChannelNotFound
+		}
+		return nil, model.ErrForbidden
+	}
+	if !member.Role.CanManage() {
+		return nil, model.ErrForbidden
+	}
+
+	if err := c.channelRepo.RestoreChannel(ctx, channelID); err != nil {
+		return nil, err
+	}
+	restored, err := c.channelRepo.GetChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func (c *channelUseCase) AddChannelMember(ctx context.Context, channelID uuid.UUID, req *model.RequestAddChannelMember) (*model.ChannelMember, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	// オーナーはチャンネル作成時にのみ決まるため、role に owner は指定できない
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+	if req.Role == "" {
+		req.Role = model.ChannelRoleMember
+	}
+
+	// 権限の確認と追加の間にメンバーの権限が変わらないよう、1つのトランザクションで行う
+	var added *model.ChannelMember
+	err := c.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		added, err = c.addChannelMember(ctx, caller, channelID, req)
+		return err
+	})
+	return added, err
+}
+
+func (c *channelUseCase) addChannelMember(ctx context.Context, caller *model.User, channelID uuid.UUID, req *model.RequestAddChannelMember) (*model.ChannelMember, error) {
+	channel, member, err := visibleChannel(ctx, c.channelRepo, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case member != nil && member.Role == model.ChannelRoleOwner:
+		// オーナーは誰でも、どの権限でも追加できる
+	case member != nil && member.Role == model.ChannelRoleAdmin:
+		if req.Role == model.ChannelRoleAdmin {
+			return nil, model.ErrForbidden
+		}
+	default:
+		// 公開チャンネルには自分自身を一般メンバーとしてのみ追加できる
+		if channel.Visibility != model.ChannelVisibilityPublic || req.UserID != caller.UserID || req.Role != model.ChannelRoleMember {
+			return nil, model.ErrForbidden
+		}
+	}
+
+	return c.channelRepo.AddChannelMember(ctx, &model.ChannelMember{
+		ChannelID: channelID,
+		UserID:    req.UserID,
+		Role:      req.Role,
+	})
+}
+
+func (c *channelUseCase) GetChannelMembers(ctx context.Context, channelID uuid.UUID) ([]*model.ChannelMember, error) {
+	if _, _, err := visibleChannel(ctx, c.channelRepo, channelID); err != nil {
+		return nil, err
+	}
+	return c.channelRepo.GetChannelMembers(ctx, channelID)
+}
+
+func (c *channelUseCase) RemoveChannelMember(ctx context.Context, channelID uuid.UUID, req *model.RequestRemoveChannelMember) error {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return model.ErrUnauthorized
+	}
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+
+	// 権限の確認と削除の間にメンバーの権限が変わらないよう、1つのトランザクションで行う
+	return c.txManager.Do(ctx, func(ctx context.Context) error {
+		return c.removeChannelMember(ctx, caller, channelID, req)
+	})
+}
+
+func (c *channelUseCase) removeChannelMember(ctx context.Context, caller *model.User, channelID uuid.UUID, req *model.RequestRemoveChannelMember) error {
+	_, member, err := visibleChannel(ctx, c.channelRepo, channelID)
+	if err != nil {
+		return err
+	}
+
+	target, err := c.channelRepo.GetChannelMember(ctx, channelID, req.UserID)
+	if err != nil {
+		return err
+	}
+	// オーナーを外すとチャンネルを管理できる人がいなくなる
+	if target.Role == model.ChannelRoleOwner {
+		return model.ErrForbidden
+	}
+
+	if req.UserID != caller.UserID {
+		if member == nil || !member.Role.CanManage() {
+			return model.ErrForbidden
+		}
+		// 管理者を外せるのはオーナーのみ
+		if target.Role == model.ChannelRoleAdmin && member.Role != model.ChannelRoleOwner {
+			return model.ErrForbidden
+		}
+	}
+
+	return c.channelRepo.RemoveChannelMember(ctx, channelID, req.UserID)
+}
@@ -2,58 +2,773 @@ package usecase
 
 import (
 	"context"
+	"mime"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/event"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
 	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/diff"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/mention"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/sniff"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/validate"
 	"github.com/gofrs/uuid"
 )
 
+const (
+	// maxEmojiLength は u_message_reaction.emoji の長さ
+	maxEmojiLength = 64
+	// maxContentTypeLength は u_message.content_type の長さ
+	maxContentTypeLength = 127
+	// maxMessageTTL は有効期限として指定できる最長の期間
+	maxMessageTTL = 365 * 24 * time.Hour
+
+	// DefaultMessageReapInterval は期限切れのメッセージを削除する間隔
+	DefaultMessageReapInterval = time.Minute
+	// messageReapBatchSize は1トランザクションで削除するメッセージの件数
+	messageReapBatchSize = 500
+)
+
+// languageRegex は言語名として許可する形式 (例: go, c++, objective-c)
+var languageRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9+#._-]{0,31}$`)
+
 type messageUsecase struct {
-	messageRepo repository.MessageRepository
+	messageRepo    repository.MessageRepository
+	channelRepo    repository.ChannelRepository
+	reactionRepo   repository.ReactionRepository
+	attachmentRepo repository.AttachmentRepository
+	mentionRepo    repository.MentionRepository
+	txManager      repository.TxManager
+	publisher      event.Publisher
 }
 
-func NewMessageUsecase(messageRepo repository.MessageRepository) usecase.MessageUsecase {
+func NewMessageUsecase(
+	messageRepo repository.MessageRepository,
+	channelRepo repository.ChannelRepository,
+	reactionRepo repository.ReactionRepository,
+	attachmentRepo repository.AttachmentRepository,
+	mentionRepo repository.MentionRepository,
+	txManager repository.TxManager,
+	publisher event.Publisher,
+) usecase.MessageUsecase {
 	return &messageUsecase{
-		messageRepo: messageRepo,
+		messageRepo:    messageRepo,
+		channelRepo:    channelRepo,
+		reactionRepo:   reactionRepo,
+		attachmentRepo: attachmentRepo,
+		mentionRepo:    mentionRepo,
+		txManager:      txManager,
+		publisher:      publisher,
+	}
+}
+
+// enrichMessages はメッセージにリアクション数と添付ファイルを付与する
+// どちらもメッセージ数によらず1回のクエリで取得する
+func (m *messageUsecase) enrichMessages(ctx context.Context, messages []*model.Message) error {
+	ids := make([]uuid.UUID, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.MessageID)
+	}
+
+	summaries, err := m.reactionRepo.GetReactionSummaries(ctx, ids, callerID(ctx))
+	if err != nil {
+		return err
+	}
+	attachments, err := m.attachmentRepo.GetAttachmentsByMessageIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	viewerID := callerID(ctx)
+	for _, message := range messages {
+		message.Reactions = summaries[message.MessageID]
+		if message.Reactions == nil {
+			message.Reactions = []*model.ReactionSummary{}
+		}
+		message.Attachments = attachments[message.MessageID]
+		if message.Attachments == nil {
+			message.Attachments = []*model.Attachment{}
+		}
+		if message.BurnAfterRead && message.UserID != viewerID {
+			redact(message)
+		}
+	}
+	return nil
+}
+
+// redact は一度きりのメッセージの本文と添付ファイルを伏せる
+func redact(message *model.Message) {
+	message.Content = ""
+	message.Ciphertext = nil
+	message.Nonce = nil
+	message.Attachments = []*model.Attachment{}
+}
+
+// resolveExpiry は有効期限を検証し、TTLSeconds を ExpiresAt に変換する
+func resolveExpiry(req *model.RequestCreateMessage) error {
+	now := time.Now()
+	if req.TTLSeconds != nil {
+		if req.ExpiresAt != nil {
+			return model.ErrInvalidExpiry
+		}
+		if *req.TTLSeconds <= 0 || *req.TTLSeconds > int64(maxMessageTTL/time.Second) {
+			return model.ErrInvalidExpiry
+		}
+		expiresAt := now.Add(time.Duration(*req.TTLSeconds) * time.Second)
+		req.ExpiresAt = &expiresAt
+		return nil
+	}
+	if req.ExpiresAt != nil && (!req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(maxMessageTTL))) {
+		return model.ErrInvalidExpiry
+	}
+	return nil
+}
+
+// validateAttachments は添付ファイルが全て存在し、投稿者自身のものであることを確認する
+// 件数と重複は RequestCreateMessage の validate タグで確認済み
+func (m *messageUsecase) validateAttachments(ctx context.Context, userID uuid.UUID, attachmentIDs []uuid.UUID) error {
+	for _, attachmentID := range attachmentIDs {
+		attachment, err := m.attachmentRepo.GetAttachment(ctx, attachmentID)
+		if err != nil {
+			return err
+		}
+		if attachment.OwnerID != userID {
+			return model.ErrForbidden
+		}
+	}
+	return nil
+}
+
+// normalizeContentType は MIME タイプを検証し、パラメータを除いた小文字の形にする
+func normalizeContentType(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || len(mediaType) > maxContentTypeLength || !strings.Contains(mediaType, "/") {
+		return "", model.ErrInvalidContentType
+	}
+	return mediaType, nil
+}
+
+func normalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !languageRegex.MatchString(language) {
+		return "", model.ErrInvalidLanguage
+	}
+	return language, nil
+}
+
+// classifyContent は本文の種類と言語を検証し、省略されたものを本文から推定して補う
+func classifyContent(content, contentType, language string) (string, string, error) {
+	var err error
+	if contentType != "" {
+		if contentType, err = normalizeContentType(contentType); err != nil {
+			return "", "", err
+		}
+	}
+	if language != "" {
+		if language, err = normalizeLanguage(language); err != nil {
+			return "", "", err
+		}
+	}
+
+	switch {
+	case contentType == "" && language == "":
+		contentType, language = sniff.Detect(content)
+	case contentType == "":
+		// 言語だけ指定されていればコードとみなす
+		contentType = model.ContentTypeCode
+	case language == "" && contentType == model.ContentTypeCode:
+		language = sniff.Language(content)
+	}
+	return contentType, language, nil
+}
+
+// validateEnvelope は暗号化メッセージの形式だけを確認する。暗号文はテキストとして扱わず、中身も検証しない
+func validateEnvelope(content string, ciphertext, nonce []byte, keyID string) error {
+	if content != "" ||
+		len(ciphertext) == 0 || len(ciphertext) > model.MaxCiphertextSize ||
+		len(nonce) == 0 || len(nonce) > model.MaxNonceSize ||
+		keyID == "" || len(keyID) > model.MaxKeyIDLength {
+		return model.ErrInvalidEnvelope
+	}
+	for _, r := range keyID {
+		if r <= ' ' || r > '~' {
+			return model.ErrInvalidEnvelope
+		}
+	}
+	return nil
+}
+
+// classifyMessage は作成するメッセージの種類と言語を決める
+// 暗号化メッセージは本文から推定できないため、指定がなければ ContentTypeEncrypted とする
+func classifyMessage(req *model.RequestCreateMessage) error {
+	if !req.Encrypted {
+		if req.Ciphertext != nil || req.Nonce != nil || req.KeyID != "" {
+			return model.ErrInvalidEnvelope
+		}
+		contentType, language, err := classifyContent(req.Content, req.ContentType, req.Language)
+		if err != nil {
+			return err
+		}
+		req.ContentType, req.Language = contentType, language
+		return nil
+	}
+
+	if err := validateEnvelope(req.Content, req.Ciphertext, req.Nonce, req.KeyID); err != nil {
+		return err
 	}
+	var err error
+	if req.ContentType == "" {
+		req.ContentType = model.ContentTypeEncrypted
+	} else if req.ContentType, err = normalizeContentType(req.ContentType); err != nil {
+		return err
+	}
+	if req.Language != "" {
+		if req.Language, err = normalizeLanguage(req.Language); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mentionTargets はメッセージの本文からメンションの宛先を読み取る
+// 暗号文は読めず、コードの @ はデコレータなどでメンションではないため、どちらも宛先なしとする
+func mentionTargets(message *model.Message) *model.MentionTargets {
+	if message.Encrypted || message.ContentType == model.ContentTypeCode {
+		return &model.MentionTargets{UserNames: []string{}}
+	}
+	return mention.Parse(message.Content)
+}
+
+// publish はメッセージのライフサイクルイベントをチャンネルの購読者へ配信する
+// 一度きりのメッセージは購読者全員に届くため、本文を伏せて配信する
+func (m *messageUsecase) publish(eventType model.EventType, message *model.Message) {
+	if message.BurnAfterRead {
+		redacted := *message
+		redact(&redacted)
+		message = &redacted
+	}
+	m.publisher.Publish(&model.Event{
+		Type:      eventType,
+		ChannelID: message.ChannelID,
+		MessageID: message.MessageID,
+		Message:   message,
+		CreatedAt: time.Now(),
+	})
+}
+
+// authorize はリクエストしたユーザーがメッセージの投稿者であることを確認する
+func (m *messageUsecase) authorize(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	message, err := m.messageRepo.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.UserID != caller.UserID {
+		return nil, model.ErrForbidden
+	}
+	return message, nil
 }
 
 func (m *messageUsecase) CreateMessage(ctx context.Context, req *model.RequestCreateMessage) (*model.Message, error) {
-	return m.messageRepo.CreateMessage(ctx, req)
+	// 投稿者はリクエストボディではなく認証済みユーザーから決める
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	if !req.UserID.IsNil() && req.UserID != caller.UserID {
+		return nil, model.ErrForbidden
+	}
+	req.UserID = caller.UserID
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+
+	if _, _, err := visibleChannel(ctx, m.channelRepo, req.ChannelID); err != nil {
+		return nil, err
+	}
+
+	// 返信は同じチャンネルの親メッセージにのみ付けられる。スレッドは1階層まで
+	if req.ParentMessageID.Valid {
+		parent, err := m.messageRepo.GetMessage(ctx, req.ParentMessageID.UUID)
+		if err != nil {
+			if err == model.ErrMessageNotFound {
+				return nil, model.ErrInvalidParentMessage
+			}
+			return nil, err
+		}
+		if parent.ChannelID != req.ChannelID || parent.ParentMessageID.Valid {
+			return nil, model.ErrInvalidParentMessage
+		}
+	}
+
+	if err := m.validateAttachments(ctx, caller.UserID, req.AttachmentIDs); err != nil {
+		return nil, err
+	}
+
+	if err := classifyMessage(req); err != nil {
+		return nil, err
+	}
+	if err := resolveExpiry(req); err != nil {
+		return nil, err
+	}
+
+	// メンションはメッセージと一緒に保存する
+	var message *model.Message
+	err := m.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if message, err = m.messageRepo.CreateMessage(ctx, req); err != nil {
+			return err
+		}
+		return m.mentionRepo.SaveMentions(ctx, message.MessageID, mentionTargets(message))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, []*model.Message{message}); err != nil {
+		return nil, err
+	}
+	m.publish(model.EventMessageCreated, message)
+	return message, nil
 }
 
-func (m *messageUsecase) GetMessages(ctx context.Context, channelID uuid.UUID, limit int, offset int) ([]*model.Message, error) {
-	if limit < 1 || limit > 1000 {
+// GetMessage はメッセージを1件返す
+// 一度きりのメッセージは、ログインした投稿者以外のユーザーが開くと本文を返して削除する
+func (m *messageUsecase) GetMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	message, err := m.visibleMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, []*model.Message{message}); err != nil {
+		return nil, err
+	}
+
+	caller, ok := auth.UserFromContext(ctx)
+	if !message.BurnAfterRead || !ok || caller.UserID == message.UserID {
+		return message, nil
+	}
+
+	// 削除すると添付ファイルの対応も消えるため、先に取得しておく
+	attachments, err := m.attachmentRepo.GetAttachmentsByMessageIDs(ctx, []uuid.UUID{messageID})
+	if err != nil {
+		return nil, err
+	}
+	burned, err := m.messageRepo.BurnMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	message.Content = burned.Content
+	message.Attachments = attachments[messageID]
+	if message.Attachments == nil {
+		message.Attachments = []*model.Attachment{}
+	}
+	m.publish(model.EventMessageDeleted, message)
+	return message, nil
+}
+
+func (m *messageUsecase) GetMessages(ctx context.Context, channelID uuid.UUID, query *model.MessageQuery) (*model.MessagePage, error) {
+	if query.Limit < 1 || query.Limit > 1000 {
 		return nil, model.ErrInvalidRequestLimit
 	}
-	return m.messageRepo.GetMessages(ctx, channelID, limit, offset)
+	if query.Before != nil && query.After != nil {
+		return nil, model.ErrInvalidCursor
+	}
+	if query.ContentType != "" {
+		contentType, err := normalizeContentType(query.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		query.ContentType = contentType
+	}
+	_, member, err := visibleChannel(ctx, m.channelRepo, channelID)
+	if err != nil {
+		return nil, err
+	}
+	// ゴミ箱のメッセージは投稿者が削除したものなので、チャンネルの管理者にしか見せない
+	if query.IncludeDeleted {
+		if _, ok := auth.UserFromContext(ctx); !ok {
+			return nil, model.ErrUnauthorized
+		}
+		if member == nil || !member.Role.CanManage() {
+			return nil, model.ErrForbidden
+		}
+	}
+	page, err := m.messageRepo.GetMessages(ctx, channelID, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, page.Messages); err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
 func (m *messageUsecase) GetMessagesInDuration(ctx context.Context, channelID uuid.UUID, start, end time.Time) ([]*model.Message, error) {
 	if start.After(end) {
 		return nil, model.ErrInvalidTimeRange
 	}
-	return m.messageRepo.GetMessagesInDuration(ctx, channelID, start, end)
+	if _, _, err := visibleChannel(ctx, m.channelRepo, channelID); err != nil {
+		return nil, err
+	}
+	messages, err := m.messageRepo.GetMessagesInDuration(ctx, channelID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
 }
 
 func (m *messageUsecase) GetPinnedMessages(ctx context.Context, channelID uuid.UUID) ([]*model.Message, error) {
-	return m.messageRepo.GetPinnedMessages(ctx, channelID)
+	if _, _, err := visibleChannel(ctx, m.channelRepo, channelID); err != nil {
+		return nil, err
+	}
+	messages, err := m.messageRepo.GetPinnedMessages(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (m *messageUsecase) GetReplies(ctx context.Context, messageID uuid.UUID) ([]*model.Message, error) {
+	if _, err := m.visibleMessage(ctx, messageID); err != nil {
+		return nil, err
+	}
+	messages, err := m.messageRepo.GetReplies(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
 }
 
 func (m *messageUsecase) PatchMessage(ctx context.Context, messageID uuid.UUID, req *model.RequestPatchMessage) (*model.Message, error) {
-	return m.messageRepo.PatchMessage(ctx, messageID, req)
+	// 確かめた権限と暗号化の有無のまま更新するよう、1つのトランザクションで行う
+	var message *model.Message
+	err := m.txManager.Do(ctx, func(ctx context.Context) error {
+		current, err := m.authorize(ctx, messageID)
+		if err != nil {
+			return err
+		}
+		if err := validate.Struct(req); err != nil {
+			return err
+		}
+		if req.IsEmpty() {
+			return model.ErrNoFieldsToUpdate
+		}
+		if err := validatePatchEnvelope(current, req); err != nil {
+			return err
+		}
+		if err := classifyPatch(req); err != nil {
+			return err
+		}
+		if message, err = m.messageRepo.PatchMessage(ctx, messageID, req); err != nil {
+			return err
+		}
+		// 本文が変わればメンションの宛先も変わるため、更新後の本文で置き換える
+		return m.mentionRepo.SaveMentions(ctx, messageID, mentionTargets(message))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, []*model.Message{message}); err != nil {
+		return nil, err
+	}
+	m.publish(model.EventMessageUpdated, message)
+	return message, nil
+}
+
+// GetMentions は自分へのメンションを新しい順に返す
+func (m *messageUsecase) GetMentions(ctx context.Context, userID uuid.UUID, query *model.MentionQuery) (*model.MentionPage, error) {
+	if err := authorizeSelf(ctx, userID); err != nil {
+		return nil, err
+	}
+	if query.Limit < 1 || query.Limit > 100 {
+		return nil, model.ErrInvalidRequestLimit
+	}
+	page, err := m.mentionRepo.GetMentions(ctx, userID, query)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]*model.Message, 0, len(page.Mentions))
+	for _, mentioned := range page.Mentions {
+		messages = append(messages, mentioned.Message)
+	}
+	if err := m.enrichMessages(ctx, messages); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetRevisions は記録された版にメッセージの現在の内容を加えて返す
+func (m *messageUsecase) GetRevisions(ctx context.Context, messageID uuid.UUID) ([]*model.MessageRevision, error) {
+	message, err := m.visibleMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	// 一度きりのメッセージは過去の版からも本文が読めてしまうため、投稿者にしか見せない
+	if message.BurnAfterRead && message.UserID != callerID(ctx) {
+		return nil, model.ErrForbidden
+	}
+
+	revisions, err := m.messageRepo.GetRevisions(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	revisions = append(revisions, &model.MessageRevision{
+		MessageID:     message.MessageID,
+		Revision:      len(revisions) + 1,
+		Content:       message.Content,
+		ContentSHA256: message.ContentSHA256,
+		ContentType:   message.ContentType,
+		Language:      message.Language,
+		Encrypted:     message.Encrypted,
+		Ciphertext:    message.Ciphertext,
+		Nonce:         message.Nonce,
+		KeyID:         message.KeyID,
+		CreatedAt:     message.UpdatedAt,
+		Current:       true,
+	})
+
+	// 暗号文はテキストではないので差分を取らない
+	for i := 1; i < len(revisions); i++ {
+		prev, cur := revisions[i-1], revisions[i]
+		if prev.Encrypted || cur.Encrypted {
+			continue
+		}
+		d := diff.Unified(prev.Content, cur.Content, diff.DefaultContext)
+		cur.Diff = &d
+	}
+	return revisions, nil
+}
+
+// validatePatchEnvelope は暗号化メッセージなら暗号文だけを、そうでなければ本文だけを更新できることを確認する
+func validatePatchEnvelope(current *model.Message, req *model.RequestPatchMessage) error {
+	hasEnvelope := req.Ciphertext != nil || req.Nonce != nil || req.KeyID != nil
+	if !current.Encrypted {
+		if hasEnvelope {
+			return model.ErrInvalidEnvelope
+		}
+		return nil
+	}
+	if req.Content != nil {
+		return model.ErrInvalidEnvelope
+	}
+	if !hasEnvelope {
+		return nil
+	}
+	// 暗号文・nonce・鍵の ID は組で意味を持つため、まとめて差し替える
+	if req.KeyID == nil {
+		return model.ErrInvalidEnvelope
+	}
+	return validateEnvelope("", req.Ciphertext, req.Nonce, *req.KeyID)
+}
+
+// classifyPatch は更新後の本文の種類と言語を決める
+// 本文だけを変更した場合は、以前の種類が新しい本文に合わなくなるため推定し直す
+func classifyPatch(req *model.RequestPatchMessage) error {
+	if req.Content != nil && req.ContentType == nil && req.Language == nil {
+		contentType, language := sniff.Detect(*req.Content)
+		req.ContentType, req.Language = &contentType, &language
+		return nil
+	}
+	if req.ContentType != nil {
+		contentType, err := normalizeContentType(*req.ContentType)
+		if err != nil {
+			return err
+		}
+		req.ContentType = &contentType
+	}
+	// 空文字列は言語の指定を外す
+	if req.Language != nil && *req.Language != "" {
+		language, err := normalizeLanguage(*req.Language)
+		if err != nil {
+			return err
+		}
+		req.Language = &language
+	}
+	return nil
+}
+
+// visibleMessage はリクエストしたユーザーから見えるチャンネルのメッセージを返す
+func (m *messageUsecase) visibleMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	message, err := m.messageRepo.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := visibleChannel(ctx, m.channelRepo, message.ChannelID); err != nil {
+		if err == model.ErrChannelNotFound {
+			return nil, model.ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return message, nil
 }
 
 func (m *messageUsecase) PinnMessage(ctx context.Context, messageID uuid.UUID) error {
-	return m.messageRepo.PinnMessage(ctx, messageID)
+	message, err := m.visibleMessage(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if err := m.messageRepo.PinnMessage(ctx, messageID); err != nil {
+		return err
+	}
+	m.publish(model.EventMessagePinned, message)
+	return nil
 }
 
 func (m *messageUsecase) UnpinnMessage(ctx context.Context, messageID uuid.UUID) error {
-	return m.messageRepo.UnpinnMessage(ctx, messageID)
+	message, err := m.visibleMessage(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if err := m.messageRepo.UnpinnMessage(ctx, messageID); err != nil {
+		return err
+	}
+	m.publish(model.EventMessageUnpinned, message)
+	return nil
 }
 
 func (m *messageUsecase) DeleteMessage(ctx context.Context, messageID uuid.UUID) error {
-	return m.messageRepo.DeleteMessage(ctx, messageID)
+	// 削除後はチャンネルIDが引けないため、先に取得しておく
+	message, err := m.authorize(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if err := m.messageRepo.DeleteMessage(ctx, messageID); err != nil {
+		return err
+	}
+	m.publish(model.EventMessageDeleted, message)
+	return nil
+}
+
+func (m *messageUsecase) RestoreMessage(ctx context.Context, messageID uuid.UUID) (*model.Message, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	// 戻している間に親が削除されないよう、確認から取得までを1つのトランザクションで行う
+	var restored *model.Message
+	err := m.txManager.Do(ctx, func(ctx context.Context) error {
+		var err error
+		restored, err = m.restoreMessage(ctx, caller, messageID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := m.enrichMessages(ctx, []*model.Message{restored}); err != nil {
+		return nil, err
+	}
+	m.publish(model.EventMessageRestored, restored)
+	return restored, nil
+}
+
+func (m *messageUsecase) restoreMessage(ctx context.Context, caller *model.User, messageID uuid.UUID) (*model.Message, error) {
+	message, err := m.messageRepo.GetDeletedMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	_, member, err := visibleChannel(ctx, m.channelRepo, message.ChannelID)
+	if err != nil {
+		if err == model.ErrChannelNotFound {
+			return nil, model.ErrMessageNotFound
+		}
+		return nil, err
+	}
+	if message.UserID != caller.UserID && (member == nil || !member.Role.CanManage()) {
+		return nil, model.ErrForbidden
+	}
+	// 親がゴミ箱にあるままでは返信だけ戻しても辿れないため、先に親を戻してもらう
+	if message.ParentMessageID.Valid {
+		if _, err := m.messageRepo.GetMessage(ctx, message.ParentMessageID.UUID); err != nil {
+			if err == model.ErrMessageNotFound {
+				return nil, model.ErrParentMessageDeleted
+			}
+			return nil, err
+		}
+	}
+
+	if err := m.messageRepo.RestoreMessage(ctx, messageID); err != nil {
+		return nil, err
+	}
+	return m.messageRepo.GetMessage(ctx, messageID)
+}
+
+func (m *messageUsecase) ReapExpiredMessages(ctx context.Context) (int, error) {
+	reaped := 0
+	for {
+		messages, err := m.messageRepo.DeleteExpiredMessages(ctx, messageReapBatchSize)
+		if err != nil {
+			return reaped, err
+		}
+		for _, message := range messages {
+			m.publish(model.EventMessageDeleted, message)
+		}
+		reaped += len(messages)
+		if len(messages) < messageReapBatchSize {
+			return reaped, nil
+		}
+	}
+}
+
+func validateEmoji(emoji string) error {
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiLength {
+		return model.ErrInvalidEmoji
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return model.ErrInvalidEmoji
+		}
+	}
+	return nil
+}
+
+func (m *messageUsecase) AddReaction(ctx context.Context, messageID uuid.UUID, req *model.RequestAddReaction) error {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return model.ErrUnauthorized
+	}
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+	if err := validateEmoji(req.Emoji); err != nil {
+		return err
+	}
+	if _, err := m.visibleMessage(ctx, messageID); err != nil {
+		return err
+	}
+	return m.reactionRepo.AddReaction(ctx, &model.Reaction{
+		MessageID: messageID,
+		UserID:    caller.UserID,
+		Emoji:     req.Emoji,
+	})
+}
+
+func (m *messageUsecase) RemoveReaction(ctx context.Context, messageID uuid.UUID, emoji string) error {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return model.ErrUnauthorized
+	}
+	if err := validateEmoji(emoji); err != nil {
+		return err
+	}
+	if _, err := m.visibleMessage(ctx, messageID); err != nil {
+		return err
+	}
+	return m.reactionRepo.RemoveReaction(ctx, messageID, caller.UserID, emoji)
 }
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/validate"
+	"github.com/gofrs/uuid"
+)
+
+type readStateUsecase struct {
+	readStateRepo repository.ReadStateRepository
+	channelRepo   repository.ChannelRepository
+	messageRepo   repository.MessageRepository
+}
+
+func NewReadStateUsecase(
+	readStateRepo repository.ReadStateRepository,
+	channelRepo repository.ChannelRepository,
+	messageRepo repository.MessageRepository,
+) usecase.ReadStateUsecase {
+	return &readStateUsecase{
+		readStateRepo: readStateRepo,
+		channelRepo:   channelRepo,
+		messageRepo:   messageRepo,
+	}
+}
+
+// MarkChannelRead はチャンネルの既読の位置を req.MessageID まで進める
+func (u *readStateUsecase) MarkChannelRead(ctx context.Context, channelID uuid.UUID, req *model.RequestMarkChannelRead) (*model.ChannelReadState, error) {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return nil, model.ErrUnauthorized
+	}
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+	if _, _, err := visibleChannel(ctx, u.channelRepo, channelID); err != nil {
+		return nil, err
+	}
+
+	// 既読の位置はメッセージの作成日時で比べるため、クライアントから日時は受け取らずメッセージから引く
+	message, err := u.messageRepo.GetMessage(ctx, req.MessageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.ChannelID != channelID {
+		return nil, model.ErrMessageNotFound
+	}
+
+	return u.readStateRepo.MarkRead(ctx, &model.ChannelReadState{
+		UserID:            caller.UserID,
+		ChannelID:         channelID,
+		LastReadMessageID: message.MessageID,
+		LastReadAt:        message.CreatedAt,
+	})
+}
+
+// GetUnreadCounts は自分のチャンネルごとの未読数を返す
+func (u *readStateUsecase) GetUnreadCounts(ctx context.Context, userID uuid.UUID) ([]*model.ChannelUnread, error) {
+	if err := authorizeSelf(ctx, userID); err != nil {
+		return nil, err
+	}
+	unread, err := u.readStateRepo.GetUnreadCounts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if unread == nil {
+		unread = []*model.ChannelUnread{}
+	}
+	return unread, nil
+}
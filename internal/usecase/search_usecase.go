@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/highlight"
+)
+
+// searchOperators は全文検索の演算子として解釈される文字。検索語からは取り除く
+const searchOperators = `+-<>()~*"@'`
+
+type searchUsecase struct {
+	searchRepo repository.SearchRepository
+}
+
+func NewSearchUsecase(searchRepo repository.SearchRepository) usecase.SearchUsecase {
+	return &searchUsecase{
+		searchRepo: searchRepo,
+	}
+}
+
+// splitTerms は検索文字列を演算子を含まない検索語に分割する
+func splitTerms(q string) []string {
+	var terms []string
+	for _, field := range strings.Fields(q) {
+		term := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(searchOperators, r) {
+				return -1
+			}
+			return r
+		}, field)
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func (s *searchUsecase) SearchMessages(ctx context.Context, query *model.SearchQuery) (*model.SearchPage, error) {
+	query.Terms = splitTerms(query.Query)
+	if len(query.Terms) == 0 {
+		return nil, model.ErrInvalidSearchQuery
+	}
+	if query.Limit < 1 || query.Limit > 100 {
+		return nil, model.ErrInvalidRequestLimit
+	}
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return nil, model.ErrInvalidTimeRange
+	}
+	query.ViewerID = callerID(ctx)
+
+	page, err := s.searchRepo.SearchMessages(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range page.Results {
+		result.Snippet = highlight.Snippet(result.Message.Content, query.Terms, highlight.DefaultContext)
+	}
+	return page, nil
+}
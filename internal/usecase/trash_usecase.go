@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+)
+
+const (
+	// DefaultTrashRetention はゴミ箱に入ってから完全に削除するまでの期間
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	// DefaultTrashPurgeInterval は保持期間を過ぎたゴミ箱の中身を削除する間隔
+	DefaultTrashPurgeInterval = time.Hour
+	// trashPurgeBatchSize は1トランザクションで削除するチャンネル・メッセージの件数
+	trashPurgeBatchSize = 100
+)
+
+type trashUsecase struct {
+	messageRepo repository.MessageRepository
+	channelRepo repository.ChannelRepository
+	retention   time.Duration
+}
+
+func NewTrashUsecase(messageRepo repository.MessageRepository, channelRepo repository.ChannelRepository, retention time.Duration) usecase.TrashUsecase {
+	return &trashUsecase{
+		messageRepo: messageRepo,
+		channelRepo: channelRepo,
+		retention:   retention,
+	}
+}
+
+func (t *trashUsecase) PurgeTrash(ctx context.Context) (int, error) {
+	deletedBefore := time.Now().Add(-t.retention)
+	purged := 0
+	// チャンネルを先に削除すると、その中のゴミ箱のメッセージも一緒に消える
+	for _, purge := range []func(context.Context, time.Time, int) (int, error){
+		t.channelRepo.PurgeDeletedChannels,
+		t.messageRepo.PurgeDeletedMessages,
+	} {
+		for {
+			n, err := purge(ctx, deletedBefore, trashPurgeBatchSize)
+			if err != nil {
+				return purged, err
+			}
+			purged += n
+			if n < trashPurgeBatchSize {
+				break
+			}
+		}
+	}
+	return purged, nil
+}
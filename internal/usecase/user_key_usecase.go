@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/model"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/repository"
+	"github.com/base-intern-august-b/clipboard-server/internal/domain/usecase"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/auth"
+	"github.com/base-intern-august-b/clipboard-server/internal/pkg/validate"
+	"github.com/gofrs/uuid"
+)
+
+type userKeyUsecase struct {
+	userKeyRepo repository.UserKeyRepository
+	userRepo    repository.UserRepository
+}
+
+func NewUserKeyUsecase(userKeyRepo repository.UserKeyRepository, userRepo repository.UserRepository) usecase.UserKeyUsecase {
+	return &userKeyUsecase{
+		userKeyRepo: userKeyRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// authorizeSelf は呼び出したユーザーが userID 本人であることを確認する
+func authorizeSelf(ctx context.Context, userID uuid.UUID) error {
+	caller, ok := auth.UserFromContext(ctx)
+	if !ok {
+		return model.ErrUnauthorized
+	}
+	if caller.UserID != userID {
+		return model.ErrForbidden
+	}
+	return nil
+}
+
+func validateUserKey(algorithm string, publicKey []byte) error {
+	size, ok := model.UserKeyAlgorithms[algorithm]
+	if !ok || len(publicKey) == 0 || len(publicKey) > model.MaxPublicKeySize {
+		return model.ErrInvalidUserKey
+	}
+	if size != 0 && len(publicKey) != size {
+		return model.ErrInvalidUserKey
+	}
+	return nil
+}
+
+func (u *userKeyUsecase) CreateUserKey(ctx context.Context, req *model.RequestCreateUserKey) (*model.UserKey, error) {
+	if err := authorizeSelf(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	if err := validate.Struct(req); err != nil {
+		return nil, err
+	}
+	if err := validateUserKey(req.Algorithm, req.PublicKey); err != nil {
+		return nil, err
+	}
+
+	keyID, err := uuid.NewV4()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate UUID: %w", err)
+	}
+	fingerprint := sha256.Sum256(req.PublicKey)
+	return u.userKeyRepo.CreateUserKey(ctx, &model.UserKey{
+		KeyID:       keyID,
+		UserID:      req.UserID,
+		Algorithm:   req.Algorithm,
+		PublicKey:   req.PublicKey,
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+	})
+}
+
+func (u *userKeyUsecase) GetUserKeys(ctx context.Context, userID uuid.UUID) ([]*model.UserKey, error) {
+	if _, err := u.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	keys, err := u.userKeyRepo.GetUserKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []*model.UserKey{}
+	}
+	return keys, nil
+}
+
+func (u *userKeyUsecase) DeleteUserKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	if err := authorizeSelf(ctx, userID); err != nil {
+		return err
+	}
+	return u.userKeyRepo.DeleteUserKey(ctx, userID, keyID)
+}
@@ -6,16 +6,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/api"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/blobstore"
 	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence"
-	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/persistence/mysql"
+	"github.com/base-intern-august-b/clipboard-server/internal/infrastructure/stream"
 	"github.com/base-intern-august-b/clipboard-server/internal/pkg/migration"
 	"github.com/base-intern-august-b/clipboard-server/internal/usecase"
-
-	"github.com/jmoiron/sqlx"
 )
 
 func main() {
@@ -25,7 +25,7 @@ func main() {
 	log.Printf("Server starting on port %s", serverPort)
 
 	// データベース接続
-	db, err := sqlx.Connect("mysql", persistence.MySQL().FormatDSN())
+	db, err := persistence.Open()
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -39,17 +39,102 @@ func main() {
 	log.Println("Database migrations completed successfully")
 
 	// リポジトリの初期化
-	userRepo := mysql.NewUserRepository(db)
-	messageRepo := mysql.NewMessageRepository(db)
-	channelRepo := mysql.NewChannelRepository(db)
+	repos, err := persistence.NewRepositories(db)
+	if err != nil {
+		log.Fatalf("Failed to initialize repositories: %v", err)
+	}
+
+	// 添付ファイルのストレージ
+	blobStore, err := blobstore.New(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to initialize blob store: %v", err)
+	}
+
+	// イベント配信ハブの初期化
+	hub := stream.NewHub(stream.DefaultBufferSize, stream.DefaultHistorySize)
 
 	// ユースケースの初期化
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	messageUsecase := usecase.NewMessageUsecase(messageRepo)
-	channelUsecase := usecase.NewChannelUsecase(channelRepo)
+	sessionTTL, err := time.ParseDuration(getEnv("SESSION_TTL", usecase.DefaultSessionTTL.String()))
+	if err != nil {
+		log.Fatalf("Invalid SESSION_TTL: %v", err)
+	}
+	blobSweepInterval, err := time.ParseDuration(getEnv("BLOB_SWEEP_INTERVAL", usecase.DefaultBlobSweepInterval.String()))
+	if err != nil || blobSweepInterval <= 0 {
+		log.Fatalf("Invalid BLOB_SWEEP_INTERVAL: %v", err)
+	}
+	messageReapInterval, err := time.ParseDuration(getEnv("MESSAGE_REAP_INTERVAL", usecase.DefaultMessageReapInterval.String()))
+	if err != nil || messageReapInterval <= 0 {
+		log.Fatalf("Invalid MESSAGE_REAP_INTERVAL: %v", err)
+	}
+	trashRetention, err := time.ParseDuration(getEnv("TRASH_RETENTION", usecase.DefaultTrashRetention.String()))
+	if err != nil || trashRetention < 0 {
+		log.Fatalf("Invalid TRASH_RETENTION: %v", err)
+	}
+	trashPurgeInterval, err := time.ParseDuration(getEnv("TRASH_PURGE_INTERVAL", usecase.DefaultTrashPurgeInterval.String()))
+	if err != nil || trashPurgeInterval <= 0 {
+		log.Fatalf("Invalid TRASH_PURGE_INTERVAL: %v", err)
+	}
+	userUsecase := usecase.NewUserUsecase(repos.User)
+	authUsecase := usecase.NewAuthUsecase(repos.User, repos.Session, sessionTTL)
+	messageUsecase := usecase.NewMessageUsecase(repos.Message, repos.Channel, repos.Reaction, repos.Attachment, repos.Mention, repos.Tx, hub)
+	channelUsecase := usecase.NewChannelUsecase(repos.Channel, repos.Tx)
+	searchUsecase := usecase.NewSearchUsecase(repos.Search)
+	attachmentUsecase := usecase.NewAttachmentUsecase(repos.Attachment, repos.Channel, blobStore)
+	blobUsecase := usecase.NewBlobUsecase(repos.Blob, blobStore)
+	userKeyUsecase := usecase.NewUserKeyUsecase(repos.UserKey, repos.User)
+	readStateUsecase := usecase.NewReadStateUsecase(repos.ReadState, repos.Channel, repos.Message)
+	trashUsecase := usecase.NewTrashUsecase(repos.Message, repos.Channel, trashRetention)
+
+	// バックグラウンドジョブ
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	var jobs sync.WaitGroup
+	startJob := func(interval time.Duration, task func(ctx context.Context)) {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			runPeriodically(background, interval, task)
+		}()
+	}
+	startJob(blobSweepInterval, func(ctx context.Context) {
+		swept, err := blobUsecase.SweepBlobs(ctx)
+		if err != nil {
+			log.Printf("Failed to sweep blobs: %v", err)
+			return
+		}
+		if swept > 0 {
+			log.Printf("Swept %d unreferenced blobs", swept)
+		}
+	})
+	startJob(messageReapInterval, func(ctx context.Context) {
+		reaped, err := messageUsecase.ReapExpiredMessages(ctx)
+		if err != nil {
+			log.Printf("Failed to reap expired messages: %v", err)
+			return
+		}
+		if reaped > 0 {
+			log.Printf("Reaped %d expired messages", reaped)
+		}
+	})
+	startJob(trashPurgeInterval, func(ctx context.Context) {
+		purged, err := trashUsecase.PurgeTrash(ctx)
+		if err != nil {
+			log.Printf("Failed to purge trash: %v", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("Purged %d channels and messages from trash", purged)
+		}
+	})
+
+	startJob(stream.HistoryTTL, func(ctx context.Context) {
+		if evicted := hub.EvictIdleHistory(); evicted > 0 {
+			log.Printf("Evicted event history of %d idle channels", evicted)
+		}
+	})
 
 	// APIルーターの設定
-	router := api.NewRouter(channelUsecase, messageUsecase, userUsecase)
+	router := api.NewRouter(channelUsecase, messageUsecase, userUsecase, authUsecase, searchUsecase, attachmentUsecase, userKeyUsecase, readStateUsecase, hub)
 	handler := router.Setup()
 
 	// HTTPサーバーの設定
@@ -57,6 +142,9 @@ func main() {
 		Addr:    ":" + serverPort,
 		Handler: handler,
 	}
+	// Shutdown は SSE のリクエストのコンテキストをキャンセルせず、
+	// ハイジャックした WebSocket の接続も待たないため、ハブを閉じて配信中のハンドラを終わらせる
+	server.RegisterOnShutdown(hub.Close)
 
 	// グレースフルシャットダウンの設定
 	go func() {
@@ -70,11 +158,30 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	// 実行中のジョブが区切りのよいところで終わるのを待つ
+	stopBackground()
+	jobs.Wait()
+
 	// グレースフルシャットダウン
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	// log.Fatalf は defer を実行しないため、タイムアウトしてもログだけ残してデータベースを閉じる
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+}
+
+// runPeriodically は ctx が終了するまで interval ごとに task を実行する
+func runPeriodically(ctx context.Context, interval time.Duration, task func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			task(ctx)
+		}
 	}
 }
 